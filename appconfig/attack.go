@@ -3,6 +3,7 @@ package appconfig
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,8 +25,11 @@ type AppConfigurationAction struct {
 
 var _ action_kit_sdk.Action[AppConfigurationActionState] = (*AppConfigurationAction)(nil)
 var _ action_kit_sdk.ActionWithStop[AppConfigurationActionState] = (*AppConfigurationAction)(nil)
+var _ common.ActionWithRequiredPermissions[AppConfigurationActionState] = (*AppConfigurationAction)(nil)
 
 type AppConfigurationActionState struct {
+	common.ExecutionContextState
+
 	Account          string                `json:"account"`
 	Region           string                `json:"region"`
 	DiscoveredByRole *string               `json:"discoveredByRole"`
@@ -33,6 +37,7 @@ type AppConfigurationActionState struct {
 	Config           *FaultInjectionConfig `json:"config"`
 	ExperimentKey    *string               `json:"experimentKey"`
 	ExecutionId      *int                  `json:"executionId"`
+	DryRun           bool                  `json:"dryRun"`
 }
 
 type AttackType int
@@ -100,9 +105,26 @@ func (a *AppConfigurationAction) Prepare(ctx context.Context, state *AppConfigur
 	state.ExperimentKey = request.ExecutionContext.ExperimentKey
 	state.ExecutionId = request.ExecutionContext.ExecutionId
 	state.Config = config
+	state.DryRun = common.IsDryRun(request)
 	return nil, nil
 }
 
+// RequiredPermissions checks the key-value data actions on the App Configuration store. Targets that
+// only expose the store's endpoint cannot be checked, as the permissions API needs the resource ID.
+func (a *AppConfigurationAction) RequiredPermissions(state *AppConfigurationActionState) []common.PermissionRequirement {
+	if state.Config == nil || state.Config.AppConfigurationId == nil {
+		return nil
+	}
+	return []common.PermissionRequirement{{
+		Scope: *state.Config.AppConfigurationId,
+		DataOperations: []string{
+			"Microsoft.AppConfiguration/configurationStores/keyValues/read",
+			"Microsoft.AppConfiguration/configurationStores/keyValues/write",
+			"Microsoft.AppConfiguration/configurationStores/keyValues/delete",
+		},
+	}}
+}
+
 func GetAppConfigEndpoint(appConfigurationId string) (string, error) {
 	splitId := strings.Split(appConfigurationId, "/")
 
@@ -146,6 +168,10 @@ func getAppConfigName(endpoint string) (string, error) {
 }
 
 func (a *AppConfigurationAction) Start(ctx context.Context, state *AppConfigurationActionState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(dryRunSettings(state.Config)...)}, nil
+	}
+
 	cred, err := common.ConnectionAzure()
 
 	if err != nil {
@@ -158,7 +184,7 @@ func (a *AppConfigurationAction) Start(ctx context.Context, state *AppConfigurat
 		return nil, extension_kit.ToError("Failed to get App Configuration endpoint.", err)
 	}
 
-	client, err := azappconfig.NewClient(appConfigEndpoint, cred, nil)
+	client, err := azappconfig.NewClient(appConfigEndpoint, cred, &azappconfig.ClientOptions{ClientOptions: common.AzureClientOptions()})
 
 	if err != nil {
 		log.Error().Msgf("Failed to create Azure App Configuration client: %v", err)
@@ -179,6 +205,12 @@ func (a *AppConfigurationAction) Start(ctx context.Context, state *AppConfigurat
 }
 
 func (a *AppConfigurationAction) Stop(ctx context.Context, state *AppConfigurationActionState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("delete all settings matching 'Steadybit:FaultInjection:%s:*'", *state.Config.AppConfigurationSuffix)),
+		}, nil
+	}
+
 	cred, err := common.ConnectionAzure()
 	if err != nil {
 		log.Error().Msgf("Failed to create Azure credential: %v", err)
@@ -190,7 +222,7 @@ func (a *AppConfigurationAction) Stop(ctx context.Context, state *AppConfigurati
 		return nil, extension_kit.ToError("Failed to get App Configuration endpoint.", err)
 	}
 
-	client, err := azappconfig.NewClient(appConfigEndpoint, cred, nil)
+	client, err := azappconfig.NewClient(appConfigEndpoint, cred, &azappconfig.ClientOptions{ClientOptions: common.AzureClientOptions()})
 
 	if err != nil {
 		log.Error().Msgf("Failed to create Azure App Configuration client: %v", err)
@@ -224,3 +256,23 @@ func (a *AppConfigurationAction) Stop(ctx context.Context, state *AppConfigurati
 
 	return nil, nil
 }
+
+// dryRunSettings lists the key/value pairs Start writes to App Configuration, sorted by key.
+func dryRunSettings(config *FaultInjectionConfig) []string {
+	suffix := *config.AppConfigurationSuffix
+	settings := config.ToAppConfigKeyValuePairs(suffix)
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mutations := []string{
+		fmt.Sprintf("set 'Steadybit:FaultInjection:%s:Enabled' to 'Yes'", suffix),
+		fmt.Sprintf("set 'Steadybit:FaultInjection:%s:Revision' to a new random revision", suffix),
+	}
+	for _, key := range keys {
+		mutations = append(mutations, fmt.Sprintf("set '%s' to '%s'", key, *settings[key]))
+	}
+	return mutations
+}
@@ -232,3 +232,22 @@ func TestAzureFunctionAction_Prepare(t *testing.T) {
 		})
 	}
 }
+
+func TestDryRunSettings(t *testing.T) {
+	config := &FaultInjectionConfig{
+		Injection:              "StatusCode",
+		Rate:                   50,
+		Enabled:                true,
+		StatusCode:             new(503),
+		AppConfigurationSuffix: new("my-app"),
+	}
+
+	assert.Equal(t, []string{
+		"set 'Steadybit:FaultInjection:my-app:Enabled' to 'Yes'",
+		"set 'Steadybit:FaultInjection:my-app:Revision' to a new random revision",
+		"set 'Steadybit:FaultInjection:my-app:Enabled' to 'true'",
+		"set 'Steadybit:FaultInjection:my-app:Injection' to 'StatusCode'",
+		"set 'Steadybit:FaultInjection:my-app:Rate' to '50'",
+		"set 'Steadybit:FaultInjection:my-app:StatusCode' to '503'",
+	}, dryRunSettings(config))
+}
@@ -104,6 +104,10 @@ func (a *appContainerDiscovery) Describe() discovery_kit_api.DiscoveryDescriptio
 }
 
 func (a *appContainerDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDContainerApp, a.discoverTargets)
+}
+
+func (a *appContainerDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
@@ -149,7 +153,7 @@ func getAllContainerApps(ctx context.Context, client common.ArmResourceGraphApi)
 		return nil, fmt.Errorf("failed to get azure credentials")
 	}
 
-	appClient, err := armappcontainers.NewContainerAppsClient(subscriptionId, cred, nil)
+	appClient, err := armappcontainers.NewContainerAppsClient(subscriptionId, cred, common.ArmClientOptions())
 	if err != nil {
 		log.Error().Msgf("failed to create container apps client: %v", err)
 		return nil, err
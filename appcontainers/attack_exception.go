@@ -7,6 +7,7 @@ import (
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	"github.com/steadybit/extension-azure/appconfig"
+	"github.com/steadybit/extension-azure/common"
 	"github.com/steadybit/extension-kit/extbuild"
 )
 
@@ -58,6 +59,7 @@ func getInjectExceptionDescription() action_kit_api.ActionDescription {
 				Required:     new(true),
 				Order:        new(2),
 			},
+			common.DryRunParameter(),
 		},
 		Stop: new(action_kit_api.MutatingEndpointReference{}),
 	}
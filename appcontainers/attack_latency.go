@@ -8,6 +8,7 @@ import (
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	"github.com/steadybit/extension-azure/appconfig"
+	"github.com/steadybit/extension-azure/common"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
 )
@@ -69,6 +70,7 @@ func getInjectLatencyDescription() action_kit_api.ActionDescription {
 				Required:     new(true),
 				Order:        new(3),
 			},
+			common.DryRunParameter(),
 		},
 		Stop: new(action_kit_api.MutatingEndpointReference{}),
 	}
@@ -96,6 +96,10 @@ func (a *azureFunctionDiscovery) Describe() discovery_kit_api.DiscoveryDescripti
 }
 
 func (a *azureFunctionDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDAzureFunction, a.discoverTargets)
+}
+
+func (a *azureFunctionDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
@@ -112,7 +116,7 @@ func getAllAzureFunctions(ctx context.Context, client common.ArmResourceGraphApi
 		return nil, fmt.Errorf("failed to get azure credentials")
 	}
 
-	appClient, err := armappservice.NewWebAppsClient(subscriptionId, cred, nil)
+	appClient, err := armappservice.NewWebAppsClient(subscriptionId, cred, common.ArmClientOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to created web app client: %w", err)
 	}
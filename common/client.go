@@ -22,7 +22,7 @@ func GetClientByCredentials() (*armresourcegraph.Client, error) {
 	}
 
 	// Create and authorize a ResourceGraph client
-	client, err := armresourcegraph.NewClient(cred, nil)
+	client, err := armresourcegraph.NewClient(cred, ArmClientOptions())
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to create Azure resource graph client.")
 		return nil, err
@@ -37,7 +37,7 @@ func GetVirtualMachinesClient(subscriptionId string) (*armcompute.VirtualMachine
 		log.Error().Err(err).Msgf("Failed to create Azure connection.")
 		return nil, err
 	}
-	computeClientFactory, err := armcompute.NewClientFactory(subscriptionId, conn, nil)
+	computeClientFactory, err := armcompute.NewClientFactory(subscriptionId, conn, ArmClientOptions())
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to create Azure compute client.")
 		return nil, err
@@ -52,7 +52,7 @@ func GetVirtualMachineScaleSetVMsClient(subscriptionId string) (*armcompute.Virt
 		log.Error().Err(err).Msgf("Failed to create Azure connection.")
 		return nil, err
 	}
-	computeClientFactory, err := armcompute.NewClientFactory(subscriptionId, conn, nil)
+	computeClientFactory, err := armcompute.NewClientFactory(subscriptionId, conn, ArmClientOptions())
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to create Azure compute client.")
 		return nil, err
@@ -61,6 +61,20 @@ func GetVirtualMachineScaleSetVMsClient(subscriptionId string) (*armcompute.Virt
 	return virtualMachinesClient, nil
 }
 
+func GetVirtualMachineScaleSetsClient(subscriptionId string) (*armcompute.VirtualMachineScaleSetsClient, error) {
+	conn, err := ConnectionAzure()
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create Azure connection.")
+		return nil, err
+	}
+	computeClientFactory, err := armcompute.NewClientFactory(subscriptionId, conn, ArmClientOptions())
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create Azure compute client.")
+		return nil, err
+	}
+	return computeClientFactory.NewVirtualMachineScaleSetsClient(), nil
+}
+
 // GetServiceBusQueuesClient returns a per-subscription Service Bus QueuesClient. Used by the queue
 // discovery to enumerate queues via the direct ARM API (Resource Graph indexes Service Bus child
 // resources with a multi-minute lag — direct ARM is real-time).
@@ -70,7 +84,7 @@ func GetServiceBusQueuesClient(subscriptionId string) (*armservicebus.QueuesClie
 		log.Error().Err(err).Msgf("Failed to create Azure connection.")
 		return nil, err
 	}
-	factory, err := armservicebus.NewClientFactory(subscriptionId, conn, nil)
+	factory, err := armservicebus.NewClientFactory(subscriptionId, conn, ArmClientOptions())
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to create Azure Service Bus client factory.")
 		return nil, err
@@ -86,7 +100,7 @@ func GetServiceBusTopicsClient(subscriptionId string) (*armservicebus.TopicsClie
 		log.Error().Err(err).Msgf("Failed to create Azure connection.")
 		return nil, err
 	}
-	factory, err := armservicebus.NewClientFactory(subscriptionId, conn, nil)
+	factory, err := armservicebus.NewClientFactory(subscriptionId, conn, ArmClientOptions())
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to create Azure Service Bus client factory.")
 		return nil, err
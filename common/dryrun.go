@@ -0,0 +1,47 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/config"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const DryRunParameterName = "dryRun"
+
+// DryRunParameter is the advanced "Dry run" parameter shared by all attacks. In dry-run mode
+// Prepare validates as usual while Start and Stop only report the mutations they would make.
+func DryRunParameter() action_kit_api.ActionParameter {
+	return action_kit_api.ActionParameter{
+		Name:         DryRunParameterName,
+		Label:        "Dry run",
+		Description:  new("Only report the changes the attack would make to Azure, without applying them."),
+		Type:         action_kit_api.ActionParameterTypeBoolean,
+		DefaultValue: new("false"),
+		Advanced:     new(true),
+		Order:        new(100),
+	}
+}
+
+// IsDryRun reports whether the attack must not write to Azure, either because the
+// extension runs in global dry-run mode or because the action requested it.
+func IsDryRun(request action_kit_api.PrepareActionRequestBody) bool {
+	return config.Config.DryRun || extutil.ToBool(request.Config[DryRunParameterName])
+}
+
+// DryRunMessages returns one info message per mutation the attack would make.
+func DryRunMessages(mutations ...string) *action_kit_api.Messages {
+	messages := make([]action_kit_api.Message, 0, len(mutations))
+	for _, mutation := range mutations {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("[dry run] Would %s", mutation),
+		})
+	}
+	return new(messages)
+}
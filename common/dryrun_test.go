@@ -0,0 +1,40 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDryRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    bool
+		parameter any
+		want      bool
+	}{
+		{name: "disabled", global: false, parameter: nil, want: false},
+		{name: "parameter", global: false, parameter: true, want: true},
+		{name: "parameter as string", global: false, parameter: "true", want: true},
+		{name: "global", global: true, parameter: false, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.Config.DryRun
+			config.Config.DryRun = tt.global
+			t.Cleanup(func() { config.Config.DryRun = previous })
+
+			request := action_kit_api.PrepareActionRequestBody{Config: map[string]any{}}
+			if tt.parameter != nil {
+				request.Config[DryRunParameterName] = tt.parameter
+			}
+			assert.Equal(t, tt.want, IsDryRun(request))
+		})
+	}
+}
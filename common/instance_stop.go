@@ -0,0 +1,126 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// StoppableInstance is a virtual machine or scale set instance that a stop attack powers off or deallocates and
+// starts again.
+type StoppableInstance interface {
+	// Name names the instance in messages, e.g. "virtual machine 'vm-1'".
+	Name() string
+	PowerState(ctx context.Context) (string, error)
+	// BeginStop powers off or deallocates the instance, or resumes that operation if a resume token is given.
+	BeginStop(ctx context.Context, mode string, resumeToken string) (Operation, error)
+	BeginStart(ctx context.Context) (Operation, error)
+}
+
+// InstanceStopState is embedded into the states of the stop attacks.
+type InstanceStopState struct {
+	Mode   string
+	DryRun bool
+	// AlreadyStopped is set if the instance was not running when the attack started. It is then
+	// left alone, so that Stop never starts an instance that was meant to be off.
+	AlreadyStopped bool
+	// StopResumeToken resumes the power-off or deallocation, which has to finish before the start.
+	StopResumeToken string
+}
+
+// StopInstance powers off or deallocates the instance, unless it is already stopped.
+func StopInstance(ctx context.Context, instance StoppableInstance, state *InstanceStopState) (*action_kit_api.StartResult, error) {
+	powerState, err := instance.PowerState(ctx)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to read the power state of the %s", instance.Name()), err)
+	}
+	if IsStoppedPowerState(powerState) {
+		state.AlreadyStopped = true
+		return &action_kit_api.StartResult{
+			Messages: new([]action_kit_api.Message{{
+				Level:   extutil.Ptr(action_kit_api.Warn),
+				Message: fmt.Sprintf("The %s is already %s. It is skipped and will not be started on stop.", instance.Name(), powerState),
+			}}),
+		}, nil
+	}
+
+	operation, err := instance.BeginStop(ctx, state.Mode, "")
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to %s the %s", state.Mode, instance.Name()), err)
+	}
+	if operation != nil {
+		if state.StopResumeToken, err = operation.ResumeToken(); err != nil {
+			log.Warn().Err(err).Msgf("Failed to get the resume token of the %s of the %s.", state.Mode, instance.Name())
+		}
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Started to %s the %s.", state.Mode, instance.Name()),
+		}}),
+	}, nil
+}
+
+// RestartInstance starts the instance stopped by StopInstance and waits until it is running. The revert fails if
+// the instance is not running within the StopTimeout.
+func RestartInstance(ctx context.Context, instance StoppableInstance, state *InstanceStopState) (*action_kit_api.StopResult, error) {
+	if state.AlreadyStopped {
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("The %s was already stopped before the attack and is not started.", instance.Name()),
+			}}),
+		}, nil
+	}
+
+	ctx, cancel := WithStopTimeout(ctx)
+	defer cancel()
+
+	if state.StopResumeToken != "" {
+		operation, err := instance.BeginStop(ctx, state.Mode, state.StopResumeToken)
+		if err == nil && operation != nil {
+			err = WaitForPrevious(ctx, operation)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("The %s of the %s did not complete, starting it anyway.", state.Mode, instance.Name())
+		}
+	}
+
+	operation, err := instance.BeginStart(ctx)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to start the %s", instance.Name()), err)
+	}
+	if operation != nil {
+		err = operation.Wait(ctx)
+	}
+	if err == nil {
+		err = WaitForPowerState(ctx, instance.Name(), "running", StopTimeout, instance.PowerState)
+	}
+	if IsStopTimeout(ctx) {
+		return &action_kit_api.StopResult{
+			Error: &action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("The %s was started but is not running after %s. Azure keeps starting it.", instance.Name(), StopTimeout),
+				Status: new(action_kit_api.Failed),
+			},
+		}, nil
+	}
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to start the %s", instance.Name()), err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("The %s is running again.", instance.Name()),
+		}}),
+	}, nil
+}
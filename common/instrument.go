@@ -0,0 +1,197 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-azure/extmetrics"
+	"github.com/steadybit/extension-azure/exttracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AzureClientOptions returns the azcore options every Azure SDK client of this
+// extension is created with, so that all API calls are counted and traced.
+func AzureClientOptions() azcore.ClientOptions {
+	return azcore.ClientOptions{
+		PerRetryPolicies: []policy.Policy{extmetrics.NewApiPolicy()},
+		TracingProvider:  exttracing.AzureTracingProvider(),
+	}
+}
+
+// ArmClientOptions wraps AzureClientOptions for the ARM client factories.
+func ArmClientOptions() *arm.ClientOptions {
+	return &arm.ClientOptions{ClientOptions: AzureClientOptions()}
+}
+
+// ObserveDiscovery runs one discovery cycle in its own span and records its duration and target count.
+func ObserveDiscovery(ctx context.Context, targetType string, discover func(ctx context.Context) ([]discovery_kit_api.Target, error)) ([]discovery_kit_api.Target, error) {
+	ctx, span := exttracing.Tracer().Start(ctx, "discovery "+targetType, trace.WithAttributes(exttracing.AttributeTargetType.String(targetType)))
+	start := time.Now()
+	targets, err := discover(ctx)
+	extmetrics.ObserveDiscovery(targetType, time.Since(start), len(targets), err)
+	span.SetAttributes(exttracing.AttributeTargetCount.Int(len(targets)))
+	exttracing.EndSpan(span, err)
+	return targets, err
+}
+
+// ExecutionContextState is embedded into action states to carry the experiment
+// and execution of a Prepare call to the subsequent Start, Status and Stop spans.
+type ExecutionContextState struct {
+	ExecutionContext *action_kit_api.ExecutionContext `json:"executionContext,omitempty"`
+}
+
+func (s *ExecutionContextState) setExecutionContext(executionContext *action_kit_api.ExecutionContext) {
+	s.ExecutionContext = executionContext
+}
+
+func (s *ExecutionContextState) getExecutionContext() *action_kit_api.ExecutionContext {
+	return s.ExecutionContext
+}
+
+type executionContextHolder interface {
+	setExecutionContext(executionContext *action_kit_api.ExecutionContext)
+	getExecutionContext() *action_kit_api.ExecutionContext
+}
+
+// InstrumentAction wraps an action so that each lifecycle call runs in its own
+// span and its outcome is recorded. The wrapper implements ActionWithStop and
+// ActionWithStatus only if the wrapped action does, as the SDK derives the
+// action's endpoints from them.
+func InstrumentAction[T any](action action_kit_sdk.Action[T]) action_kit_sdk.Action[T] {
+	base := &instrumentedAction[T]{action: action, actionId: action.Describe().Id}
+	_, hasStop := action.(action_kit_sdk.ActionWithStop[T])
+	_, hasStatus := action.(action_kit_sdk.ActionWithStatus[T])
+	switch {
+	case hasStop && hasStatus:
+		return &instrumentedActionWithStatusAndStop[T]{instrumentedActionWithStop[T]{base}}
+	case hasStop:
+		return &instrumentedActionWithStop[T]{base}
+	case hasStatus:
+		return &instrumentedActionWithStatus[T]{base}
+	default:
+		return base
+	}
+}
+
+type instrumentedAction[T any] struct {
+	action   action_kit_sdk.Action[T]
+	actionId string
+}
+
+func (a *instrumentedAction[T]) NewEmptyState() T {
+	return a.action.NewEmptyState()
+}
+
+func (a *instrumentedAction[T]) Describe() action_kit_api.ActionDescription {
+	return a.action.Describe()
+}
+
+func (a *instrumentedAction[T]) Prepare(ctx context.Context, state *T, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	if holder, ok := any(state).(executionContextHolder); ok {
+		holder.setExecutionContext(request.ExecutionContext)
+	}
+	ctx, span := a.startSpan(ctx, extmetrics.OperationPrepare, state)
+	result, err := a.action.Prepare(ctx, state, request)
+	var resultErr *action_kit_api.ActionKitError
+	if result != nil {
+		resultErr = result.Error
+	}
+	a.observe(span, extmetrics.OperationPrepare, err, resultErr)
+	return result, err
+}
+
+func (a *instrumentedAction[T]) Start(ctx context.Context, state *T) (*action_kit_api.StartResult, error) {
+	ctx, span := a.startSpan(ctx, extmetrics.OperationStart, state)
+	result, err := a.action.Start(ctx, state)
+	var resultErr *action_kit_api.ActionKitError
+	if result != nil {
+		resultErr = result.Error
+	}
+	a.observe(span, extmetrics.OperationStart, err, resultErr)
+	return result, err
+}
+
+func (a *instrumentedAction[T]) status(ctx context.Context, state *T) (*action_kit_api.StatusResult, error) {
+	ctx, span := a.startSpan(ctx, extmetrics.OperationStatus, state)
+	result, err := a.action.(action_kit_sdk.ActionWithStatus[T]).Status(ctx, state)
+	var resultErr *action_kit_api.ActionKitError
+	if result != nil {
+		resultErr = result.Error
+	}
+	a.observe(span, extmetrics.OperationStatus, err, resultErr)
+	return result, err
+}
+
+func (a *instrumentedAction[T]) stop(ctx context.Context, state *T) (*action_kit_api.StopResult, error) {
+	ctx, span := a.startSpan(ctx, extmetrics.OperationStop, state)
+	result, err := a.action.(action_kit_sdk.ActionWithStop[T]).Stop(ctx, state)
+	var resultErr *action_kit_api.ActionKitError
+	if result != nil {
+		resultErr = result.Error
+	}
+	a.observe(span, extmetrics.OperationStop, err, resultErr)
+	if err != nil || resultErr != nil {
+		extmetrics.ObserveRestoreFailure(a.actionId)
+	}
+	return result, err
+}
+
+func (a *instrumentedAction[T]) startSpan(ctx context.Context, operation string, state *T) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{exttracing.AttributeActionId.String(a.actionId)}
+	if holder, ok := any(state).(executionContextHolder); ok {
+		if executionContext := holder.getExecutionContext(); executionContext != nil {
+			if executionContext.ExperimentKey != nil {
+				attributes = append(attributes, exttracing.AttributeExperimentKey.String(*executionContext.ExperimentKey))
+			}
+			if executionContext.ExecutionId != nil {
+				attributes = append(attributes, exttracing.AttributeExecutionId.Int(*executionContext.ExecutionId))
+			}
+		}
+	}
+	return exttracing.Tracer().Start(ctx, operation+" "+a.actionId, trace.WithAttributes(attributes...))
+}
+
+// observe records the outcome of an operation, which failed if it returned an error or a result with an error.
+func (a *instrumentedAction[T]) observe(span trace.Span, operation string, err error, resultErr *action_kit_api.ActionKitError) {
+	if err == nil && resultErr != nil {
+		err = errors.New(resultErr.Title)
+	}
+	extmetrics.ObserveActionOperation(a.actionId, operation, err)
+	exttracing.EndSpan(span, err)
+}
+
+type instrumentedActionWithStop[T any] struct {
+	*instrumentedAction[T]
+}
+
+func (a *instrumentedActionWithStop[T]) Stop(ctx context.Context, state *T) (*action_kit_api.StopResult, error) {
+	return a.stop(ctx, state)
+}
+
+type instrumentedActionWithStatus[T any] struct {
+	*instrumentedAction[T]
+}
+
+func (a *instrumentedActionWithStatus[T]) Status(ctx context.Context, state *T) (*action_kit_api.StatusResult, error) {
+	return a.status(ctx, state)
+}
+
+type instrumentedActionWithStatusAndStop[T any] struct {
+	instrumentedActionWithStop[T]
+}
+
+func (a *instrumentedActionWithStatusAndStop[T]) Status(ctx context.Context, state *T) (*action_kit_api.StatusResult, error) {
+	return a.status(ctx, state)
+}
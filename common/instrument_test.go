@@ -0,0 +1,134 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type testState struct {
+	ExecutionContextState
+}
+
+type testAction struct{}
+
+func (a *testAction) NewEmptyState() testState { return testState{} }
+
+func (a *testAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{Id: "com.steadybit.test"}
+}
+
+func (a *testAction) Prepare(_ context.Context, _ *testState, _ action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	return nil, nil
+}
+
+func (a *testAction) Start(_ context.Context, _ *testState) (*action_kit_api.StartResult, error) {
+	return nil, nil
+}
+
+type testActionWithStop struct {
+	testAction
+	stopErr error
+}
+
+func (a *testActionWithStop) Stop(_ context.Context, _ *testState) (*action_kit_api.StopResult, error) {
+	return nil, a.stopErr
+}
+
+func TestInstrumentAction_PreservesOptionalInterfaces(t *testing.T) {
+	plain := InstrumentAction[testState](&testAction{})
+	_, hasStop := plain.(action_kit_sdk.ActionWithStop[testState])
+	_, hasStatus := plain.(action_kit_sdk.ActionWithStatus[testState])
+	assert.False(t, hasStop)
+	assert.False(t, hasStatus)
+
+	withStop := InstrumentAction[testState](&testActionWithStop{})
+	_, hasStop = withStop.(action_kit_sdk.ActionWithStop[testState])
+	_, hasStatus = withStop.(action_kit_sdk.ActionWithStatus[testState])
+	assert.True(t, hasStop)
+	assert.False(t, hasStatus)
+	assert.Equal(t, "com.steadybit.test", withStop.Describe().Id)
+}
+
+func TestInstrumentAction_DelegatesStop(t *testing.T) {
+	action := InstrumentAction[testState](&testActionWithStop{stopErr: errors.New("restore failed")})
+	_, err := action.(action_kit_sdk.ActionWithStop[testState]).Stop(t.Context(), &testState{})
+	require.EqualError(t, err, "restore failed")
+}
+
+func TestObserveDiscovery_ReturnsResult(t *testing.T) {
+	targets, err := ObserveDiscovery(t.Context(), "com.steadybit.test", func(ctx context.Context) ([]discovery_kit_api.Target, error) {
+		return []discovery_kit_api.Target{{Id: "a"}}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, targets, 1)
+}
+
+func TestInstrumentAction_TracesExecutionContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	action := InstrumentAction[testState](&testActionWithStop{stopErr: errors.New("restore failed")})
+	state := action.NewEmptyState()
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		ExecutionContext: &action_kit_api.ExecutionContext{ExperimentKey: new("ADM-1"), ExecutionId: new(42)},
+	})
+	require.NoError(t, err)
+	_, _ = action.(action_kit_sdk.ActionWithStop[testState]).Stop(t.Context(), &state)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "prepare com.steadybit.test", spans[0].Name())
+	assert.Equal(t, "stop com.steadybit.test", spans[1].Name())
+	for _, span := range spans {
+		attributes := map[string]any{}
+		for _, kv := range span.Attributes() {
+			attributes[string(kv.Key)] = kv.Value.AsInterface()
+		}
+		assert.Equal(t, "ADM-1", attributes["steadybit.experiment.key"])
+		assert.Equal(t, int64(42), attributes["steadybit.execution.id"])
+	}
+	assert.Equal(t, "restore failed", spans[1].Status().Description)
+}
+
+type testActionWithFailedStart struct {
+	testAction
+}
+
+func (a *testActionWithFailedStart) Start(_ context.Context, _ *testState) (*action_kit_api.StartResult, error) {
+	return &action_kit_api.StartResult{Error: &action_kit_api.ActionKitError{Title: "Start failed."}}, nil
+}
+
+func TestInstrumentAction_RecordsResultErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	action := InstrumentAction[testState](&testActionWithFailedStart{})
+	state := action.NewEmptyState()
+	result, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "Start failed.", spans[0].Status().Description)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+	"github.com/rs/zerolog/log"
+)
+
+const lockIdSegment = "/providers/microsoft.authorization/locks/"
+
+type LocksApi interface {
+	NewListByScopePager(scope string, options *armlocks.ManagementLocksClientListByScopeOptions) *runtime.Pager[armlocks.ManagementLocksClientListByScopeResponse]
+}
+
+var locksClientProvider = func(subscriptionId string) (LocksApi, error) {
+	cred, err := ConnectionAzure()
+	if err != nil {
+		return nil, err
+	}
+	return armlocks.NewManagementLocksClient(subscriptionId, cred, ArmClientOptions())
+}
+
+// CheckLocks looks for management locks on each required scope, its parent resources, its resource
+// group and its subscription, and returns an error naming every lock that blocks one of the required
+// operations: CanNotDelete locks block deletes, ReadOnly locks block every operation that is not a read.
+// Scopes whose locks cannot be read are skipped with a warning.
+func CheckLocks(ctx context.Context, requirements []PermissionRequirement) error {
+	locksByScope := make(map[string][]*armlocks.ManagementLockObject)
+	var blocked []string
+	for _, requirement := range requirements {
+		if requirement.Scope == "" {
+			continue
+		}
+		id, err := arm.ParseResourceID(requirement.Scope)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to parse %s, skipping the lock check.", requirement.Scope)
+			continue
+		}
+		client, err := locksClientProvider(id.SubscriptionID)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to initialize the locks client for subscription %s, skipping the lock check.", id.SubscriptionID)
+			continue
+		}
+		for current := id; current != nil && current.Parent != nil; current = current.Parent {
+			scope := current.String()
+			locks, ok := locksByScope[strings.ToLower(scope)]
+			if !ok {
+				locks, err = listLocksAt(ctx, client, scope)
+				if err != nil {
+					log.Warn().Err(err).Msgf("Failed to read the locks of %s, checking its parent scopes only.", scope)
+					continue
+				}
+				locksByScope[strings.ToLower(scope)] = locks
+			}
+			for _, lock := range locks {
+				for _, operation := range requirement.Operations {
+					if !blocksOperation(*lock.Properties.Level, operation) {
+						continue
+					}
+					message := fmt.Sprintf("%s lock '%s' on %s blocks %s", *lock.Properties.Level, GetStringValue(lock.Name), describeScope(current), operation)
+					if !slices.Contains(blocked, message) {
+						blocked = append(blocked, message)
+					}
+				}
+			}
+		}
+	}
+	if len(blocked) > 0 {
+		return fmt.Errorf("%s. Remove the lock or choose another target", strings.Join(blocked, "; "))
+	}
+	return nil
+}
+
+// listLocksAt returns the locks defined exactly at scope. The API also returns the locks of all
+// resources below the scope, which are filtered out by their ID.
+func listLocksAt(ctx context.Context, client LocksApi, scope string) ([]*armlocks.ManagementLockObject, error) {
+	var locks []*armlocks.ManagementLockObject
+	pager := client.NewListByScopePager(scope, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, lock := range page.Value {
+			if lock == nil || lock.ID == nil || lock.Properties == nil || lock.Properties.Level == nil {
+				continue
+			}
+			lockId := strings.ToLower(*lock.ID)
+			if i := strings.LastIndex(lockId, lockIdSegment); i >= 0 && lockId[:i] == strings.ToLower(scope) {
+				locks = append(locks, lock)
+			}
+		}
+	}
+	return locks, nil
+}
+
+// blocksOperation reports whether a lock of the given level prevents the operation. Reads and
+// linked-access checks ("join" actions) do not modify the locked resource and are never blocked.
+func blocksOperation(level armlocks.LockLevel, operation string) bool {
+	operation = strings.ToLower(operation)
+	if strings.HasSuffix(operation, "/read") || strings.HasSuffix(operation, "/join/action") {
+		return false
+	}
+	switch level {
+	case armlocks.LockLevelReadOnly:
+		return true
+	case armlocks.LockLevelCanNotDelete:
+		return strings.HasSuffix(operation, "/delete")
+	default:
+		return false
+	}
+}
+
+func describeScope(id *arm.ResourceID) string {
+	switch {
+	case strings.EqualFold(id.ResourceType.String(), arm.SubscriptionResourceType.String()):
+		return fmt.Sprintf("subscription '%s'", id.SubscriptionID)
+	case strings.EqualFold(id.ResourceType.String(), arm.ResourceGroupResourceType.String()):
+		return fmt.Sprintf("resource group '%s'", id.ResourceGroupName)
+	default:
+		return fmt.Sprintf("%s '%s'", id.ResourceType.String(), id.Name)
+	}
+}
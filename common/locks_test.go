@@ -0,0 +1,111 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// locksApiFake answers ListByScope like ARM does: with every lock at or below the scope.
+type locksApiFake struct {
+	locks   []*armlocks.ManagementLockObject
+	scopes  []string
+	failing string
+}
+
+func (f *locksApiFake) NewListByScopePager(scope string, _ *armlocks.ManagementLocksClientListByScopeOptions) *runtime.Pager[armlocks.ManagementLocksClientListByScopeResponse] {
+	f.scopes = append(f.scopes, scope)
+	var value []*armlocks.ManagementLockObject
+	for _, lock := range f.locks {
+		if strings.HasPrefix(strings.ToLower(*lock.ID), strings.ToLower(scope)+"/") {
+			value = append(value, lock)
+		}
+	}
+	return runtime.NewPager(runtime.PagingHandler[armlocks.ManagementLocksClientListByScopeResponse]{
+		More: func(armlocks.ManagementLocksClientListByScopeResponse) bool { return false },
+		Fetcher: func(context.Context, *armlocks.ManagementLocksClientListByScopeResponse) (armlocks.ManagementLocksClientListByScopeResponse, error) {
+			if strings.EqualFold(scope, f.failing) {
+				return armlocks.ManagementLocksClientListByScopeResponse{}, errors.New("forbidden")
+			}
+			return armlocks.ManagementLocksClientListByScopeResponse{ManagementLockListResult: armlocks.ManagementLockListResult{Value: value}}, nil
+		},
+	})
+}
+
+func useLocks(t *testing.T, locks ...*armlocks.ManagementLockObject) *locksApiFake {
+	fake := &locksApiFake{locks: locks}
+	previous := locksClientProvider
+	locksClientProvider = func(string) (LocksApi, error) { return fake, nil }
+	t.Cleanup(func() { locksClientProvider = previous })
+	return fake
+}
+
+func lockAt(scope string, name string, level armlocks.LockLevel) *armlocks.ManagementLockObject {
+	return &armlocks.ManagementLockObject{
+		ID:         new(scope + "/providers/Microsoft.Authorization/locks/" + name),
+		Name:       new(name),
+		Properties: &armlocks.ManagementLockProperties{Level: new(level)},
+	}
+}
+
+const (
+	lockedResourceGroup = "/subscriptions/sub-1/resourceGroups/rg-1"
+	lockedVm            = lockedResourceGroup + "/providers/Microsoft.Compute/virtualMachines/vm-1"
+	lockedVnet          = lockedResourceGroup + "/providers/Microsoft.Network/virtualNetworks/vnet-1"
+)
+
+func TestCheckLocks_CanNotDeleteBlocksDelete(t *testing.T) {
+	fake := useLocks(t, lockAt(lockedResourceGroup, "keep", armlocks.LockLevelCanNotDelete))
+
+	err := CheckLocks(t.Context(), []PermissionRequirement{{Scope: lockedVm, Operations: []string{"Microsoft.Compute/virtualMachines/delete"}}})
+
+	require.EqualError(t, err, "CanNotDelete lock 'keep' on resource group 'rg-1' blocks Microsoft.Compute/virtualMachines/delete. Remove the lock or choose another target")
+	assert.Equal(t, []string{lockedVm, lockedResourceGroup, "/subscriptions/sub-1"}, fake.scopes)
+}
+
+func TestCheckLocks_CanNotDeleteAllowsRestart(t *testing.T) {
+	useLocks(t, lockAt(lockedVm, "keep", armlocks.LockLevelCanNotDelete))
+
+	err := CheckLocks(t.Context(), []PermissionRequirement{{Scope: lockedVm, Operations: []string{"Microsoft.Compute/virtualMachines/restart/action"}}})
+
+	require.NoError(t, err)
+}
+
+func TestCheckLocks_ReadOnlyOnParentBlocksWrites(t *testing.T) {
+	useLocks(t, lockAt(lockedVnet, "frozen", armlocks.LockLevelReadOnly))
+
+	err := CheckLocks(t.Context(), []PermissionRequirement{{
+		Scope:      lockedVnet + "/subnets/snet-1",
+		Operations: []string{"Microsoft.Network/virtualNetworks/subnets/read", "Microsoft.Network/virtualNetworks/subnets/write"},
+	}})
+
+	require.EqualError(t, err, "ReadOnly lock 'frozen' on Microsoft.Network/virtualNetworks 'vnet-1' blocks Microsoft.Network/virtualNetworks/subnets/write. Remove the lock or choose another target")
+}
+
+func TestCheckLocks_IgnoresLocksOfSiblings(t *testing.T) {
+	useLocks(t, lockAt(lockedResourceGroup+"/providers/Microsoft.Compute/virtualMachines/vm-2", "frozen", armlocks.LockLevelReadOnly))
+
+	err := CheckLocks(t.Context(), []PermissionRequirement{{Scope: lockedVm, Operations: []string{"Microsoft.Compute/virtualMachines/delete"}}})
+
+	require.NoError(t, err)
+}
+
+func TestCheckLocks_ChecksParentScopesIfAScopeCannotBeRead(t *testing.T) {
+	fake := useLocks(t, lockAt(lockedResourceGroup, "keep", armlocks.LockLevelCanNotDelete))
+	fake.failing = lockedVm
+
+	err := CheckLocks(t.Context(), []PermissionRequirement{{Scope: lockedVm, Operations: []string{"Microsoft.Compute/virtualMachines/delete"}}})
+
+	require.EqualError(t, err, "CanNotDelete lock 'keep' on resource group 'rg-1' blocks Microsoft.Compute/virtualMachines/delete. Remove the lock or choose another target")
+	assert.Equal(t, []string{lockedVm, lockedResourceGroup, "/subscriptions/sub-1"}, fake.scopes)
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	CompletionTimeoutParameterName = "completionTimeout"
+	powerStatePrefix               = "PowerState/"
+)
+
+// Operation is a long-running Azure operation, independent of the response type of its poller.
+type Operation interface {
+	// Poll advances the operation once and reports whether it finished. A failed operation returns its error.
+	Poll(ctx context.Context) (bool, error)
+	// ResumeToken returns the token to resume the operation with in a later call. It fails once the operation is done.
+	ResumeToken() (string, error)
+	// Done reports whether the operation reached a terminal state.
+	Done() bool
+	// Wait blocks until the operation finished and returns its error, if it failed.
+	Wait(ctx context.Context) error
+}
+
+type pollerOperation[T any] struct {
+	poller *runtime.Poller[T]
+}
+
+// NewOperation wraps the result of a Begin* call. It is meant to be called as NewOperation(client.BeginX(...)).
+func NewOperation[T any](poller *runtime.Poller[T], err error) (Operation, error) {
+	if err != nil || poller == nil {
+		return nil, err
+	}
+	return &pollerOperation[T]{poller: poller}, nil
+}
+
+func (o *pollerOperation[T]) Poll(ctx context.Context) (bool, error) {
+	if !o.poller.Done() {
+		if _, err := o.poller.Poll(ctx); err != nil {
+			return false, err
+		}
+		if !o.poller.Done() {
+			return false, nil
+		}
+	}
+	_, err := o.poller.Result(ctx)
+	return true, err
+}
+
+func (o *pollerOperation[T]) ResumeToken() (string, error) {
+	return o.poller.ResumeToken()
+}
+
+func (o *pollerOperation[T]) Done() bool {
+	return o.poller.Done()
+}
+
+func (o *pollerOperation[T]) Wait(ctx context.Context) error {
+	_, err := o.poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: powerStatePollInterval})
+	return err
+}
+
+// OperationTracking is embedded into the states of actions that can wait for their long-running
+// operation to complete. Start records the resume token; Status resumes the operation from it. Only
+// actions calling PrepareTracking wait.
+type OperationTracking struct {
+	CompletionTimeout time.Duration `json:"completionTimeout"`
+	Deadline          time.Time     `json:"deadline"`
+	ResumeToken       string        `json:"resumeToken"`
+	PowerState        string        `json:"powerState"`
+}
+
+// CompletionTimeoutParameter is the parameter bounding how long Status waits for a long-running operation.
+func CompletionTimeoutParameter() action_kit_api.ActionParameter {
+	return action_kit_api.ActionParameter{
+		Name:         CompletionTimeoutParameterName,
+		Label:        "Completion timeout",
+		Description:  new("Fail the step if the operation has not completed within this time."),
+		Type:         action_kit_api.ActionParameterTypeDuration,
+		DefaultValue: new("15m"),
+		Advanced:     new(true),
+		Order:        new(51),
+	}
+}
+
+// PrepareTracking makes the action wait for its operation, bounded by the completion timeout of the request.
+func (t *OperationTracking) PrepareTracking(request action_kit_api.PrepareActionRequestBody) {
+	t.CompletionTimeout = time.Duration(extutil.ToInt64(request.Config[CompletionTimeoutParameterName])) * time.Millisecond
+	if t.CompletionTimeout <= 0 {
+		t.CompletionTimeout = 15 * time.Minute
+	}
+}
+
+// Track records the operation started by Start, if the action waits for its completion. An operation that
+// already finished with the first response has no resume token; it is not tracked and its error is returned.
+func (t *OperationTracking) Track(ctx context.Context, operation Operation) error {
+	if t.CompletionTimeout <= 0 || operation == nil {
+		return nil
+	}
+	if operation.Done() {
+		_, err := operation.Poll(ctx)
+		return err
+	}
+	token, err := operation.ResumeToken()
+	if err != nil {
+		return err
+	}
+	t.ResumeToken = token
+	t.Deadline = time.Now().Add(t.CompletionTimeout)
+	return nil
+}
+
+// IsTracking reports whether Status has to follow an operation.
+func (t *OperationTracking) IsTracking() bool {
+	return t.ResumeToken != ""
+}
+
+// PollTracked polls the resumed operation once and turns the outcome into a status result: completed
+// when the operation succeeded, failed when it failed or did not finish before the deadline. The
+// description names the operation in messages, e.g. "restart of virtual machine 'vm-1'".
+func (t *OperationTracking) PollTracked(ctx context.Context, operation Operation, description string, messages []action_kit_api.Message) *action_kit_api.StatusResult {
+	done, err := operation.Poll(ctx)
+	if err != nil {
+		return &action_kit_api.StatusResult{
+			Completed: true,
+			Messages:  new(messages),
+			Error: &action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("The %s failed.", description),
+				Detail: new(err.Error()),
+				Status: new(action_kit_api.Failed),
+			},
+		}
+	}
+	if done {
+		t.ResumeToken = ""
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("The %s completed.", description),
+		})
+		return &action_kit_api.StatusResult{Completed: true, Messages: new(messages)}
+	}
+	if time.Now().After(t.Deadline) {
+		return &action_kit_api.StatusResult{
+			Completed: true,
+			Messages:  new(messages),
+			Error: &action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("The %s did not complete within %s.", description, t.CompletionTimeout),
+				Status: new(action_kit_api.Failed),
+			},
+		}
+	}
+	if token, err := operation.ResumeToken(); err == nil {
+		t.ResumeToken = token
+	}
+	messages = append(messages, action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("The %s is still in progress.", description),
+	})
+	return &action_kit_api.StatusResult{Completed: false, Messages: new(messages)}
+}
+
+// ObservePowerState records the power state and returns a message if it changed since the last call.
+func (t *OperationTracking) ObservePowerState(subject string, powerState string) []action_kit_api.Message {
+	if powerState == "" || powerState == t.PowerState {
+		return nil
+	}
+	previous := t.PowerState
+	t.PowerState = powerState
+	if previous == "" {
+		return []action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("The %s is %s.", subject, powerState),
+		}}
+	}
+	return []action_kit_api.Message{{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("The %s changed from %s to %s.", subject, previous, powerState),
+	}}
+}
+
+// Outcome reports whether the operation with the given description, e.g. "start of virtual machine 'vm-1'", failed.
+func Outcome(err error, description string) action_kit_api.Message {
+	if err != nil {
+		return action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Warn),
+			Message: fmt.Sprintf("The %s failed: %s", description, err.Error()),
+		}
+	}
+	return action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("The %s succeeded.", description),
+	}
+}
+
+// StopTimeout bounds how long the Stop of an attack waits for Azure to revert it. Azure may take much
+// longer to start resources again, so Stop reports a revert still in progress instead of waiting for it.
+var StopTimeout = 5 * time.Minute
+
+// WithStopTimeout returns a context for Stop which expires after the StopTimeout.
+func WithStopTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, StopTimeout)
+}
+
+// WaitForPrevious waits at most half of the remaining time of ctx for operations that have to finish before
+// the revert can begin, e.g. power-offs before the starts, and leaves the rest for the revert.
+func WaitForPrevious(ctx context.Context, operations ...Operation) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Until(deadline)/2)
+		defer cancel()
+	}
+	errs := make([]error, 0)
+	for _, operation := range operations {
+		if err := operation.Wait(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StillInProgress reports an operation of Stop that did not complete within the StopTimeout.
+func StillInProgress(description string) action_kit_api.Message {
+	return action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Warn),
+		Message: fmt.Sprintf("The %s is still in progress after %s.", description, StopTimeout),
+	}
+}
+
+// IsStopTimeout reports whether the context of Stop expired, i.e. the revert is still in progress.
+func IsStopTimeout(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// powerStatePollInterval is the time between two reads of the state in WaitUntil.
+var powerStatePollInterval = 10 * time.Second
+
+// WaitUntil reads the state of a resource until done reports true. It returns the error of the context if that is
+// not the case within the timeout.
+func WaitUntil(ctx context.Context, timeout time.Duration, done func(ctx context.Context) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		ok, err := done(ctx)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(powerStatePollInterval):
+		}
+	}
+}
+
+// WaitForPowerState reads the power state until it equals want and fails if it does not within the timeout.
+func WaitForPowerState(ctx context.Context, subject string, want string, timeout time.Duration, powerState func(ctx context.Context) (string, error)) error {
+	current := ""
+	err := WaitUntil(ctx, timeout, func(ctx context.Context) (bool, error) {
+		state, err := powerState(ctx)
+		if state != "" {
+			current = state
+		}
+		return current == want, err
+	})
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return fmt.Errorf("the %s did not become %s within %s, last power state was '%s'", subject, want, timeout, current)
+	}
+	return err
+}
+
+// IsStoppedPowerState reports whether a power state means the instance is stopped, deallocated or on its way there.
+func IsStoppedPowerState(powerState string) bool {
+	switch powerState {
+	case "stopping", "stopped", "deallocating", "deallocated":
+		return true
+	default:
+		return false
+	}
+}
+
+// PowerStateOf returns the power state of an instance view, e.g. "running" or "deallocated".
+func PowerStateOf(statuses []*armcompute.InstanceViewStatus) string {
+	for _, status := range statuses {
+		if status != nil && status.Code != nil && strings.HasPrefix(*status.Code, powerStatePrefix) {
+			return strings.TrimPrefix(*status.Code, powerStatePrefix)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const asyncOperationUrl = "https://management.azure.com/subscriptions/sub-1/providers/Microsoft.Compute/locations/westeurope/operations/op-1"
+
+// asyncOperationTransport answers every poll of the Azure-AsyncOperation URL with the given status.
+type asyncOperationTransport struct {
+	status string
+}
+
+func (f *asyncOperationTransport) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"status":"` + f.status + `"}`)),
+		Request:    req,
+	}, nil
+}
+
+func newTestOperation(t *testing.T, status string) Operation {
+	request, err := http.NewRequest(http.MethodPost, "https://management.azure.com/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Compute/virtualMachines/vm-1/restart", nil)
+	require.NoError(t, err)
+	accepted := &http.Response{
+		StatusCode: http.StatusAccepted,
+		Header:     http.Header{"Azure-Asyncoperation": []string{asyncOperationUrl}},
+		Body:       http.NoBody,
+		Request:    request,
+	}
+	pipeline := runtime.NewPipeline("test", "v1.0.0", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport: &asyncOperationTransport{status: status},
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+	operation, err := NewOperation(runtime.NewPoller[armcompute.VirtualMachinesClientRestartResponse](accepted, pipeline, nil))
+	require.NoError(t, err)
+	return operation
+}
+
+// newCompletedTestOperation returns an operation that finished with the first response, like a restart of a
+// virtual machine that is already being restarted.
+func newCompletedTestOperation(t *testing.T) Operation {
+	request, err := http.NewRequest(http.MethodPost, "https://management.azure.com/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Compute/virtualMachines/vm-1/restart", nil)
+	require.NoError(t, err)
+	ok := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{}`)),
+		Request:    request,
+	}
+	pipeline := runtime.NewPipeline("test", "v1.0.0", runtime.PipelineOptions{}, &policy.ClientOptions{Retry: policy.RetryOptions{MaxRetries: -1}})
+	operation, err := NewOperation(runtime.NewPoller[armcompute.VirtualMachinesClientRestartResponse](ok, pipeline, nil))
+	require.NoError(t, err)
+	return operation
+}
+
+func TestPollTracked(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        string
+		deadline      time.Time
+		wantCompleted bool
+		wantError     string
+		wantMessage   string
+	}{
+		{name: "succeeded", status: "Succeeded", deadline: time.Now().Add(time.Minute), wantCompleted: true, wantMessage: "The restart of virtual machine 'vm-1' completed."},
+		{name: "in progress", status: "InProgress", deadline: time.Now().Add(time.Minute), wantMessage: "The restart of virtual machine 'vm-1' is still in progress."},
+		{name: "failed", status: "Failed", deadline: time.Now().Add(time.Minute), wantCompleted: true, wantError: "The restart of virtual machine 'vm-1' failed."},
+		{name: "timed out", status: "InProgress", deadline: time.Now().Add(-time.Second), wantCompleted: true, wantError: "The restart of virtual machine 'vm-1' did not complete within 1m0s."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracking := OperationTracking{CompletionTimeout: time.Minute}
+			operation := newTestOperation(t, tt.status)
+			require.NoError(t, tracking.Track(t.Context(), operation))
+			assert.True(t, tracking.IsTracking())
+			tracking.Deadline = tt.deadline
+
+			result := tracking.PollTracked(t.Context(), operation, "restart of virtual machine 'vm-1'", nil)
+
+			assert.Equal(t, tt.wantCompleted, result.Completed)
+			if tt.wantError != "" {
+				require.NotNil(t, result.Error)
+				assert.Equal(t, tt.wantError, result.Error.Title)
+				assert.Equal(t, action_kit_api.Failed, *result.Error.Status)
+			} else {
+				assert.Nil(t, result.Error)
+				assert.Equal(t, tt.wantMessage, (*result.Messages)[0].Message)
+			}
+		})
+	}
+}
+
+func TestTrack_IgnoresOperationWhenNotWaiting(t *testing.T) {
+	tracking := OperationTracking{}
+
+	require.NoError(t, tracking.Track(t.Context(), newTestOperation(t, "InProgress")))
+
+	assert.False(t, tracking.IsTracking())
+}
+
+func TestTrack_AcceptsOperationCompletedWithTheFirstResponse(t *testing.T) {
+	tracking := OperationTracking{CompletionTimeout: time.Minute}
+	operation := newCompletedTestOperation(t)
+	require.True(t, operation.Done())
+
+	require.NoError(t, tracking.Track(t.Context(), operation))
+
+	assert.False(t, tracking.IsTracking())
+}
+
+func TestPrepareTracking(t *testing.T) {
+	tracking := OperationTracking{}
+
+	tracking.PrepareTracking(action_kit_api.PrepareActionRequestBody{Config: map[string]any{
+		CompletionTimeoutParameterName: 120000,
+	}})
+
+	assert.Equal(t, 2*time.Minute, tracking.CompletionTimeout)
+
+	tracking.PrepareTracking(action_kit_api.PrepareActionRequestBody{Config: map[string]any{}})
+	assert.Equal(t, 15*time.Minute, tracking.CompletionTimeout)
+}
+
+func TestObservePowerState(t *testing.T) {
+	tracking := OperationTracking{}
+	statuses := []*armcompute.InstanceViewStatus{
+		{Code: new("ProvisioningState/succeeded")},
+		{Code: new("PowerState/running")},
+	}
+
+	messages := tracking.ObservePowerState("virtual machine 'vm-1'", PowerStateOf(statuses))
+	require.Len(t, messages, 1)
+	assert.Equal(t, "The virtual machine 'vm-1' is running.", messages[0].Message)
+
+	assert.Empty(t, tracking.ObservePowerState("virtual machine 'vm-1'", "running"))
+
+	messages = tracking.ObservePowerState("virtual machine 'vm-1'", "stopping")
+	require.Len(t, messages, 1)
+	assert.Equal(t, "The virtual machine 'vm-1' changed from running to stopping.", messages[0].Message)
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, "The start of virtual machine 'vm-1' succeeded.", Outcome(nil, "start of virtual machine 'vm-1'").Message)
+	failed := Outcome(errors.New("conflict"), "start of virtual machine 'vm-1'")
+	assert.Equal(t, action_kit_api.Warn, *failed.Level)
+	assert.Equal(t, "The start of virtual machine 'vm-1' failed: conflict", failed.Message)
+}
+
+func TestWaitForPowerState(t *testing.T) {
+	previous := powerStatePollInterval
+	powerStatePollInterval = time.Millisecond
+	t.Cleanup(func() { powerStatePollInterval = previous })
+
+	states := []string{"deallocated", "starting", "running"}
+	reads := 0
+	err := WaitForPowerState(t.Context(), "virtual machine 'vm-1'", "running", time.Second, func(context.Context) (string, error) {
+		state := states[min(reads, len(states)-1)]
+		reads++
+		return state, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, reads)
+
+	err = WaitForPowerState(t.Context(), "virtual machine 'vm-1'", "running", 20*time.Millisecond, func(context.Context) (string, error) {
+		return "starting", nil
+	})
+	assert.EqualError(t, err, "the virtual machine 'vm-1' did not become running within 20ms, last power state was 'starting'")
+}
+
+func TestWaitForPrevious_LeavesHalfOfTheStopTimeoutForTheRevert(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	err := WaitForPrevious(ctx, newTestOperation(t, "InProgress"))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, IsStopTimeout(ctx))
+	<-ctx.Done()
+	assert.True(t, IsStopTimeout(ctx))
+}
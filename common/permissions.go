@@ -0,0 +1,257 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+)
+
+// PermissionRequirement lists the Azure operations an action performs on one scope (an ARM resource
+// or resource group ID). Operations are checked against the control plane actions of the caller's
+// role assignments, DataOperations against their data actions.
+type PermissionRequirement struct {
+	Scope          string
+	Operations     []string
+	DataOperations []string
+}
+
+// ActionWithRequiredPermissions is implemented by actions that declare the Azure operations they
+// perform. WithPreflight checks them after a successful Prepare, so that a missing role
+// assignment or a management lock fails the experiment before Start changed anything.
+type ActionWithRequiredPermissions[T any] interface {
+	RequiredPermissions(state *T) []PermissionRequirement
+}
+
+type PermissionsApi interface {
+	NewListForResourcePager(resourceGroupName string, resourceProviderNamespace string, parentResourcePath string, resourceType string, resourceName string, options *armauthorization.PermissionsClientListForResourceOptions) *runtime.Pager[armauthorization.PermissionsClientListForResourceResponse]
+	NewListForResourceGroupPager(resourceGroupName string, options *armauthorization.PermissionsClientListForResourceGroupOptions) *runtime.Pager[armauthorization.PermissionsClientListForResourceGroupResponse]
+}
+
+var permissionsClientProvider = func(subscriptionId string) (PermissionsApi, error) {
+	cred, err := ConnectionAzure()
+	if err != nil {
+		return nil, err
+	}
+	return armauthorization.NewPermissionsClient(subscriptionId, cred, ArmClientOptions())
+}
+
+// WithPreflight wraps an action declaring its required Azure operations so that Prepare fails if they are not
+// permitted or blocked by a management lock. Other actions are returned as they are. Like InstrumentAction, the
+// wrapper implements ActionWithStop and ActionWithStatus only if the wrapped action does.
+func WithPreflight[T any](action action_kit_sdk.Action[T]) action_kit_sdk.Action[T] {
+	withPermissions, ok := action.(ActionWithRequiredPermissions[T])
+	if !ok {
+		return action
+	}
+	base := &preflightAction[T]{Action: action, permissions: withPermissions}
+	stop, hasStop := action.(action_kit_sdk.ActionWithStop[T])
+	status, hasStatus := action.(action_kit_sdk.ActionWithStatus[T])
+	switch {
+	case hasStop && hasStatus:
+		return &preflightActionWithStatusAndStop[T]{base, stop, status}
+	case hasStop:
+		return &preflightActionWithStop[T]{base, stop}
+	case hasStatus:
+		return &preflightActionWithStatus[T]{base, status}
+	default:
+		return base
+	}
+}
+
+type preflightAction[T any] struct {
+	action_kit_sdk.Action[T]
+	permissions ActionWithRequiredPermissions[T]
+}
+
+// Prepare runs the preflight only if the action prepared successfully, as there is nothing to attack otherwise.
+func (a *preflightAction[T]) Prepare(ctx context.Context, state *T, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	result, err := a.Action.Prepare(ctx, state, request)
+	if err != nil || (result != nil && result.Error != nil) {
+		return result, err
+	}
+	if err := preflight(ctx, a.permissions.RequiredPermissions(state)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type preflightActionWithStop[T any] struct {
+	*preflightAction[T]
+	stop action_kit_sdk.ActionWithStop[T]
+}
+
+func (a *preflightActionWithStop[T]) Stop(ctx context.Context, state *T) (*action_kit_api.StopResult, error) {
+	return a.stop.Stop(ctx, state)
+}
+
+type preflightActionWithStatus[T any] struct {
+	*preflightAction[T]
+	status action_kit_sdk.ActionWithStatus[T]
+}
+
+func (a *preflightActionWithStatus[T]) Status(ctx context.Context, state *T) (*action_kit_api.StatusResult, error) {
+	return a.status.Status(ctx, state)
+}
+
+type preflightActionWithStatusAndStop[T any] struct {
+	*preflightAction[T]
+	stop   action_kit_sdk.ActionWithStop[T]
+	status action_kit_sdk.ActionWithStatus[T]
+}
+
+func (a *preflightActionWithStatusAndStop[T]) Stop(ctx context.Context, state *T) (*action_kit_api.StopResult, error) {
+	return a.stop.Stop(ctx, state)
+}
+
+func (a *preflightActionWithStatusAndStop[T]) Status(ctx context.Context, state *T) (*action_kit_api.StatusResult, error) {
+	return a.status.Status(ctx, state)
+}
+
+// preflight verifies that the service principal may perform the required operations and that no
+// management lock blocks them.
+func preflight(ctx context.Context, requirements []PermissionRequirement) error {
+	if err := CheckPermissions(ctx, requirements); err != nil {
+		return extension_kit.ToError("Missing Azure permissions", err)
+	}
+	if err := CheckLocks(ctx, requirements); err != nil {
+		return extension_kit.ToError("Target is locked", err)
+	}
+	return nil
+}
+
+// CheckPermissions asks the Microsoft.Authorization/permissions API which operations the service
+// principal may perform on each scope and returns an error listing every required operation that is
+// not granted. Scopes whose permissions cannot be read are skipped with a warning rather than failing
+// the attack, as the preflight is only a safeguard.
+func CheckPermissions(ctx context.Context, requirements []PermissionRequirement) error {
+	var missing []string
+	for _, requirement := range requirements {
+		if requirement.Scope == "" {
+			continue
+		}
+		permissions, err := listPermissions(ctx, requirement.Scope)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to read the permissions for %s, skipping the permission preflight.", requirement.Scope)
+			continue
+		}
+		for _, operation := range MissingOperations(permissions, requirement.Operations, requirement.DataOperations) {
+			missing = append(missing, fmt.Sprintf("%s on %s", operation, requirement.Scope))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the service principal is missing the following Azure permissions: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func listPermissions(ctx context.Context, scope string) ([]*armauthorization.Permission, error) {
+	id, err := arm.ParseResourceID(scope)
+	if err != nil {
+		return nil, err
+	}
+	client, err := permissionsClientProvider(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []*armauthorization.Permission
+	if strings.EqualFold(id.ResourceType.String(), arm.ResourceGroupResourceType.String()) {
+		pager := client.NewListForResourceGroupPager(id.Name, nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			permissions = append(permissions, page.Value...)
+		}
+		return permissions, nil
+	}
+
+	namespace, parentResourcePath, resourceType := resourcePathOf(id)
+	pager := client.NewListForResourcePager(id.ResourceGroupName, namespace, parentResourcePath, resourceType, id.Name, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, page.Value...)
+	}
+	return permissions, nil
+}
+
+// resourcePathOf splits a (possibly nested) resource ID into the arguments of the permissions API,
+// e.g. .../providers/Microsoft.ServiceBus/namespaces/ns/queues/q into
+// ("Microsoft.ServiceBus", "namespaces/ns", "queues").
+func resourcePathOf(id *arm.ResourceID) (namespace, parentResourcePath, resourceType string) {
+	types := id.ResourceType.Types
+	names := make([]string, len(types))
+	for i, current := len(types)-1, id; i >= 0 && current != nil; i, current = i-1, current.Parent {
+		names[i] = current.Name
+	}
+	parents := make([]string, 0, len(types)-1)
+	for i := 0; i < len(types)-1; i++ {
+		parents = append(parents, types[i]+"/"+names[i])
+	}
+	return id.ResourceType.Namespace, strings.Join(parents, "/"), types[len(types)-1]
+}
+
+// MissingOperations returns the operations and data operations not granted by any of the
+// permissions. An operation is granted by a permission if it matches one of its (wildcard) actions
+// and none of its not-actions.
+func MissingOperations(permissions []*armauthorization.Permission, operations []string, dataOperations []string) []string {
+	var missing []string
+	for _, operation := range operations {
+		if !isGranted(permissions, operation, func(p *armauthorization.Permission) ([]*string, []*string) { return p.Actions, p.NotActions }) {
+			missing = append(missing, operation)
+		}
+	}
+	for _, operation := range dataOperations {
+		if !isGranted(permissions, operation, func(p *armauthorization.Permission) ([]*string, []*string) { return p.DataActions, p.NotDataActions }) {
+			missing = append(missing, operation)
+		}
+	}
+	return missing
+}
+
+func isGranted(permissions []*armauthorization.Permission, operation string, actionsOf func(p *armauthorization.Permission) ([]*string, []*string)) bool {
+	for _, permission := range permissions {
+		if permission == nil {
+			continue
+		}
+		actions, notActions := actionsOf(permission)
+		if matchesAny(actions, operation) && !matchesAny(notActions, operation) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []*string, operation string) bool {
+	for _, pattern := range patterns {
+		if pattern != nil && matchesAction(*pattern, operation) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAction reports whether an RBAC action pattern like "Microsoft.Network/*/read" covers the
+// operation. Azure compares actions case-insensitively and "*" matches any sequence of characters.
+func matchesAction(pattern string, operation string) bool {
+	expression := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(expression, operation)
+	return err == nil && matched
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type permissionsApiFake struct {
+	permissions []*armauthorization.Permission
+	calls       [][]string
+}
+
+func (f *permissionsApiFake) NewListForResourcePager(resourceGroupName string, resourceProviderNamespace string, parentResourcePath string, resourceType string, resourceName string, _ *armauthorization.PermissionsClientListForResourceOptions) *runtime.Pager[armauthorization.PermissionsClientListForResourceResponse] {
+	f.calls = append(f.calls, []string{resourceGroupName, resourceProviderNamespace, parentResourcePath, resourceType, resourceName})
+	return runtime.NewPager(runtime.PagingHandler[armauthorization.PermissionsClientListForResourceResponse]{
+		More: func(armauthorization.PermissionsClientListForResourceResponse) bool { return false },
+		Fetcher: func(context.Context, *armauthorization.PermissionsClientListForResourceResponse) (armauthorization.PermissionsClientListForResourceResponse, error) {
+			return armauthorization.PermissionsClientListForResourceResponse{PermissionGetResult: armauthorization.PermissionGetResult{Value: f.permissions}}, nil
+		},
+	})
+}
+
+func (f *permissionsApiFake) NewListForResourceGroupPager(resourceGroupName string, _ *armauthorization.PermissionsClientListForResourceGroupOptions) *runtime.Pager[armauthorization.PermissionsClientListForResourceGroupResponse] {
+	f.calls = append(f.calls, []string{resourceGroupName})
+	return runtime.NewPager(runtime.PagingHandler[armauthorization.PermissionsClientListForResourceGroupResponse]{
+		More: func(armauthorization.PermissionsClientListForResourceGroupResponse) bool { return false },
+		Fetcher: func(context.Context, *armauthorization.PermissionsClientListForResourceGroupResponse) (armauthorization.PermissionsClientListForResourceGroupResponse, error) {
+			return armauthorization.PermissionsClientListForResourceGroupResponse{PermissionGetResult: armauthorization.PermissionGetResult{Value: f.permissions}}, nil
+		},
+	})
+}
+
+func usePermissions(t *testing.T, permissions ...*armauthorization.Permission) *permissionsApiFake {
+	fake := &permissionsApiFake{permissions: permissions}
+	previous := permissionsClientProvider
+	permissionsClientProvider = func(string) (PermissionsApi, error) { return fake, nil }
+	t.Cleanup(func() { permissionsClientProvider = previous })
+	return fake
+}
+
+func actions(values ...string) []*string {
+	pointers := make([]*string, 0, len(values))
+	for _, value := range values {
+		pointers = append(pointers, new(value))
+	}
+	return pointers
+}
+
+func TestMissingOperations(t *testing.T) {
+	const ruleWrite = "Microsoft.Network/networkSecurityGroups/securityRules/write"
+	tests := []struct {
+		name        string
+		permissions []*armauthorization.Permission
+		want        []string
+	}{
+		{name: "owner", permissions: []*armauthorization.Permission{{Actions: actions("*")}}, want: nil},
+		{name: "exact, different case", permissions: []*armauthorization.Permission{{Actions: actions("microsoft.network/networksecuritygroups/securityrules/write")}}, want: nil},
+		{name: "provider wildcard", permissions: []*armauthorization.Permission{{Actions: actions("Microsoft.Network/*")}}, want: nil},
+		{name: "reader", permissions: []*armauthorization.Permission{{Actions: actions("*/read")}}, want: []string{ruleWrite}},
+		{name: "not action", permissions: []*armauthorization.Permission{{Actions: actions("*"), NotActions: actions("Microsoft.Network/*/write")}}, want: []string{ruleWrite}},
+		{name: "granted by second assignment", permissions: []*armauthorization.Permission{
+			{Actions: actions("*"), NotActions: actions("Microsoft.Network/*")},
+			{Actions: actions("Microsoft.Network/networkSecurityGroups/*")},
+		}, want: nil},
+		{name: "none", permissions: nil, want: []string{ruleWrite}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MissingOperations(tt.permissions, []string{ruleWrite}, nil))
+		})
+	}
+}
+
+func TestMissingOperations_DataActions(t *testing.T) {
+	const keyValueWrite = "Microsoft.AppConfiguration/configurationStores/keyValues/write"
+	permissions := []*armauthorization.Permission{{Actions: actions("*"), DataActions: actions("Microsoft.AppConfiguration/configurationStores/*/read")}}
+	assert.Equal(t, []string{keyValueWrite}, MissingOperations(permissions, nil, []string{keyValueWrite}))
+}
+
+func TestResourcePathOf(t *testing.T) {
+	id, err := arm.ParseResourceID("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ServiceBus/namespaces/ns-1/queues/queue-1")
+	require.NoError(t, err)
+	namespace, parentResourcePath, resourceType := resourcePathOf(id)
+	assert.Equal(t, "Microsoft.ServiceBus", namespace)
+	assert.Equal(t, "namespaces/ns-1", parentResourcePath)
+	assert.Equal(t, "queues", resourceType)
+}
+
+func TestCheckPermissions_ListsMissingOperations(t *testing.T) {
+	fake := usePermissions(t, &armauthorization.Permission{Actions: actions("Microsoft.Network/networkSecurityGroups/read")})
+	scope := "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Network/networkSecurityGroups/nsg-1"
+
+	err := CheckPermissions(t.Context(), []PermissionRequirement{{
+		Scope: scope,
+		Operations: []string{
+			"Microsoft.Network/networkSecurityGroups/read",
+			"Microsoft.Network/networkSecurityGroups/securityRules/write",
+		},
+	}})
+
+	require.EqualError(t, err, "the service principal is missing the following Azure permissions: Microsoft.Network/networkSecurityGroups/securityRules/write on "+scope)
+	assert.Equal(t, [][]string{{"rg-1", "Microsoft.Network", "", "networkSecurityGroups", "nsg-1"}}, fake.calls)
+}
+
+func TestCheckPermissions_ResourceGroupScope(t *testing.T) {
+	fake := usePermissions(t, &armauthorization.Permission{Actions: actions("*")})
+
+	err := CheckPermissions(t.Context(), []PermissionRequirement{{Scope: "/subscriptions/sub-1/resourceGroups/rg-1", Operations: []string{"Microsoft.Resources/deployments/write"}}})
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"rg-1"}}, fake.calls)
+}
+
+type testActionWithPermissions struct {
+	testAction
+}
+
+func (a *testActionWithPermissions) RequiredPermissions(_ *testState) []PermissionRequirement {
+	return []PermissionRequirement{{
+		Scope:      "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Compute/virtualMachines/vm-1",
+		Operations: []string{"Microsoft.Compute/virtualMachines/restart/action"},
+	}}
+}
+
+func TestWithPreflight_FailsPrepareOnMissingPermissions(t *testing.T) {
+	usePermissions(t, &armauthorization.Permission{Actions: actions("*/read")})
+	action := WithPreflight[testState](&testActionWithPermissions{})
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Microsoft.Compute/virtualMachines/restart/action")
+}
+
+type testActionWithPermissionsAndFailedPrepare struct {
+	testActionWithPermissions
+}
+
+func (a *testActionWithPermissionsAndFailedPrepare) Prepare(_ context.Context, _ *testState, _ action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	return &action_kit_api.PrepareResult{Error: &action_kit_api.ActionKitError{Title: "No target found."}}, nil
+}
+
+func TestWithPreflight_SkipsPreflightWhenPrepareFailed(t *testing.T) {
+	fake := usePermissions(t, &armauthorization.Permission{Actions: actions("*/read")})
+	action := WithPreflight[testState](&testActionWithPermissionsAndFailedPrepare{})
+	state := action.NewEmptyState()
+
+	result, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "No target found.", result.Error.Title)
+	assert.Empty(t, fake.calls)
+}
+
+func TestWithPreflight_PreservesOptionalInterfaces(t *testing.T) {
+	plain := WithPreflight[testState](&testAction{})
+	assert.Equal(t, &testAction{}, plain)
+
+	withPermissions := WithPreflight[testState](&testActionWithPermissions{})
+	_, hasStop := withPermissions.(action_kit_sdk.ActionWithStop[testState])
+	assert.False(t, hasStop)
+}
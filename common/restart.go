@@ -0,0 +1,68 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+)
+
+// Restart brings back one resource taken down by an outage attack.
+type Restart struct {
+	// Description names the restart in messages, e.g. "start of virtual machine 'vm-1'".
+	Description string
+	// Begin starts the resource. It returns no operation if there is nothing to start, e.g. after a reboot.
+	Begin func(ctx context.Context) (Operation, error)
+	// Wait waits until the resource is back. If it is not set, only the operation of Begin is waited for.
+	Wait func(ctx context.Context, operation Operation) error
+}
+
+// BringBack waits for the previous operations together, e.g. the pending power-offs, then begins all restarts before
+// waiting for any of them, so the resources come back as a whole and Stop takes as long as the slowest resource. The
+// subject names the resources in logs, e.g. "resources of zone 1 of westeurope".
+//
+// It returns a message per restart and the descriptions of the failed ones. Restarts that are still in progress when
+// ctx expires, i.e. after the StopTimeout, are reported as such and not as failed.
+func BringBack(ctx context.Context, subject string, previous []Operation, restarts []Restart) ([]action_kit_api.Message, []string) {
+	if err := WaitForPrevious(ctx, previous...); err != nil {
+		log.Warn().Err(err).Msgf("Not all previous operations on the %s completed, bringing them back anyway.", subject)
+	}
+
+	operations := make([]Operation, len(restarts))
+	errs := make([]error, len(restarts))
+	for i, restart := range restarts {
+		operations[i], errs[i] = restart.Begin(ctx)
+	}
+
+	messages := make([]action_kit_api.Message, 0, len(restarts))
+	failed := make([]string, 0)
+	for i, restart := range restarts {
+		err := errs[i]
+		if err == nil {
+			err = waitForRestart(ctx, restart, operations[i])
+			if err != nil && IsStopTimeout(ctx) {
+				messages = append(messages, StillInProgress(restart.Description))
+				continue
+			}
+		}
+		messages = append(messages, Outcome(err, restart.Description))
+		if err != nil {
+			failed = append(failed, restart.Description)
+		}
+	}
+	return messages, failed
+}
+
+func waitForRestart(ctx context.Context, restart Restart, operation Operation) error {
+	if restart.Wait != nil {
+		return restart.Wait(ctx, operation)
+	}
+	if operation == nil {
+		return nil
+	}
+	return operation.Wait(ctx)
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBringBack_BeginsAllRestartsBeforeWaitingForAny(t *testing.T) {
+	// Given
+	begun := make([]string, 0)
+	restart := func(name string, err error) Restart {
+		return Restart{
+			Description: "start of virtual machine '" + name + "'",
+			Begin: func(context.Context) (Operation, error) {
+				begun = append(begun, name)
+				return nil, err
+			},
+			Wait: func(context.Context, Operation) error {
+				assert.Len(t, begun, 3, "all restarts are begun before the first one is waited for")
+				return nil
+			},
+		}
+	}
+
+	// When
+	messages, failed := BringBack(t.Context(), "virtual machines", nil, []Restart{restart("vm-1", nil), restart("vm-2", errors.New("conflict")), restart("vm-3", nil)})
+
+	// Then
+	assert.Equal(t, []string{"vm-1", "vm-2", "vm-3"}, begun)
+	require.Len(t, messages, 3)
+	assert.Equal(t, "The start of virtual machine 'vm-1' succeeded.", messages[0].Message)
+	assert.Equal(t, "The start of virtual machine 'vm-2' failed: conflict", messages[1].Message)
+	assert.Equal(t, []string{"start of virtual machine 'vm-2'"}, failed)
+}
+
+func TestBringBack_ReportsRestartsInProgressAtTheStopTimeout(t *testing.T) {
+	// Given
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	restarts := []Restart{{
+		Description: "start of virtual machine 'vm-1'",
+		Begin: func(context.Context) (Operation, error) {
+			return newTestOperation(t, "InProgress"), nil
+		},
+	}}
+
+	// When
+	messages, failed := BringBack(ctx, "virtual machines", nil, restarts)
+
+	// Then
+	assert.Empty(t, failed)
+	require.Len(t, messages, 1)
+	assert.Equal(t, action_kit_api.Warn, *messages[0].Level)
+	assert.Contains(t, messages[0].Message, "The start of virtual machine 'vm-1' is still in progress after")
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"fmt"
+	"math"
+
+	extension_kit "github.com/steadybit/extension-kit"
+)
+
+// The modes of the scale attacks: reduce the current count by a percentage, or scale to an absolute count.
+const (
+	ScaleModeAbsolute = "absolute"
+	ScaleModePercent  = "percent"
+)
+
+// TargetCount returns the count to scale to. A percentage reduction is rounded down, so reducing by 50% leaves 1 of 3.
+// name names the value in errors, e.g. "capacity".
+func TargetCount[T int32 | int64](current T, mode string, value T, name string) (T, error) {
+	if value < 0 {
+		return 0, extension_kit.ToError(fmt.Sprintf("The %s must not be negative.", name), nil)
+	}
+	switch mode {
+	case ScaleModeAbsolute:
+		return value, nil
+	case ScaleModePercent, "":
+		if value > 100 {
+			return 0, extension_kit.ToError("The percentage must be between 0 and 100.", nil)
+		}
+		return T(math.Floor(float64(current) * float64(100-value) / 100)), nil
+	default:
+		return 0, extension_kit.ToError(fmt.Sprintf("Unknown mode '%s'.", mode), nil)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetCount(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       int64
+		mode          string
+		value         int64
+		expected      int64
+		expectedError string
+	}{
+		{name: "absolute", current: 4, mode: ScaleModeAbsolute, value: 1, expected: 1},
+		{name: "scale out", current: 2, mode: ScaleModeAbsolute, value: 6, expected: 6},
+		{name: "percentage rounds down", current: 3, mode: ScaleModePercent, value: 50, expected: 1},
+		{name: "percentage to zero", current: 5, mode: ScaleModePercent, value: 100, expected: 0},
+		{name: "percentage above 100", current: 5, mode: ScaleModePercent, value: 120, expectedError: "The percentage must be between 0 and 100."},
+		{name: "negative value", current: 5, mode: ScaleModeAbsolute, value: -1, expectedError: "The capacity must not be negative."},
+		{name: "unknown mode", current: 5, mode: "double", value: 1, expectedError: "Unknown mode 'double'."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := TargetCount(tt.current, tt.mode, tt.value, "capacity")
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+}
+
+func TestTargetCount_Int32(t *testing.T) {
+	count, err := TargetCount(int32(3), ScaleModePercent, int32(50), "node count")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), count)
+}
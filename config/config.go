@@ -44,6 +44,7 @@ type Specification struct {
 	DiscoveryEnableLoadBalancer       bool `json:"discoveryEnableLoadBalancer" split_words:"true" required:"false" default:"false"`
 	DiscoveryEnableApplicationGateway bool `json:"discoveryEnableApplicationGateway" split_words:"true" required:"false" default:"false"`
 	DiscoveryEnableApiManagement      bool `json:"discoveryEnableApiManagement" split_words:"true" required:"false" default:"false"`
+	DiscoveryEnableAvailabilitySet    bool `json:"discoveryEnableAvailabilitySet" split_words:"true" required:"false" default:"false"`
 
 	DiscoveryAttributesExcludesAksCluster         []string `json:"discoveryAttributesExcludesAksCluster" required:"false" split_words:"true"`
 	DiscoveryAttributesExcludesAksNodePool        []string `json:"discoveryAttributesExcludesAksNodePool" required:"false" split_words:"true"`
@@ -57,6 +58,14 @@ type Specification struct {
 	DiscoveryAttributesExcludesLoadBalancer       []string `json:"discoveryAttributesExcludesLoadBalancer" required:"false" split_words:"true"`
 	DiscoveryAttributesExcludesApplicationGateway []string `json:"discoveryAttributesExcludesApplicationGateway" required:"false" split_words:"true"`
 	DiscoveryAttributesExcludesApiManagement      []string `json:"discoveryAttributesExcludesApiManagement" required:"false" split_words:"true"`
+	DiscoveryAttributesExcludesAvailabilitySet    []string `json:"discoveryAttributesExcludesAvailabilitySet" required:"false" split_words:"true"`
+
+	// DryRun forces every attack into dry-run mode: Start and Stop only report the mutations they would make.
+	DryRun bool `json:"dryRun" split_words:"true" required:"false" default:"false"`
+
+	// TracingOtlpEndpoint is the OTLP/HTTP endpoint URL (e.g. http://otel-collector:4318) spans are exported to.
+	// Tracing is disabled when empty.
+	TracingOtlpEndpoint string `json:"tracingOtlpEndpoint" required:"false" split_words:"true"`
 }
 
 var (
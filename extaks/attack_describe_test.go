@@ -7,6 +7,7 @@ package extaks
 import (
 	"testing"
 
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,3 +31,31 @@ func TestClusterDescribeEnrichmentRules(t *testing.T) {
 		assert.NotEmpty(t, r.Id)
 	}
 }
+
+func TestNodePoolDescribeEnrichmentRules(t *testing.T) {
+	rules := (&nodePoolDiscovery{}).DescribeEnrichmentRules()
+	require.Len(t, rules, 2)
+	r := rules[0]
+	assert.Equal(t, TargetIDNodePool, r.Src.Type)
+	assert.Equal(t, "com.steadybit.extension_kubernetes.kubernetes-node", r.Dest.Type)
+	assert.Equal(t, "${dest.k8s.label.agentpool}", r.Src.Selector["azure.aks.nodepool.name"])
+	assert.Equal(t, "${src.azure.aks.nodepool.name}", r.Dest.Selector["k8s.label.agentpool"])
+	assert.Equal(t, "${src.k8s.cluster-name}", r.Dest.Selector["k8s.cluster-name"])
+	assert.Contains(t, r.Attributes, discovery_kit_api.Attribute{Matcher: discovery_kit_api.Equals, Name: "azure.aks.nodepool.scale-set-priority"})
+	assert.Contains(t, r.Attributes, discovery_kit_api.Attribute{Matcher: discovery_kit_api.Equals, Name: "azure.aks.nodepool.mode"})
+
+	pod := rules[1]
+	assert.Equal(t, TargetIDNodePool, pod.Src.Type)
+	assert.Equal(t, "com.steadybit.extension_kubernetes.kubernetes-pod", pod.Dest.Type)
+	assert.Equal(t, "${dest.host.hostname}", pod.Src.Selector["azure.aks.nodepool.node.name"])
+	assert.Equal(t, "${src.azure.aks.nodepool.node.name}", pod.Dest.Selector["host.hostname"])
+	assert.Equal(t, "${src.k8s.cluster-name}", pod.Dest.Selector["k8s.cluster-name"])
+	assert.Equal(t, r.Attributes, pod.Attributes)
+}
+
+func TestNodePoolSpotEvictionDescribe(t *testing.T) {
+	desc := NewNodePoolSpotEvictionAction().Describe()
+	assert.Equal(t, NodePoolSpotEvictionActionId, desc.Id)
+	assert.Equal(t, TargetIDNodePool, desc.TargetSelection.TargetType)
+	assert.NotEmpty(t, desc.Parameters)
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type ClusterRestrictApiServerState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	ResourceGroupName string
+	ClusterName       string
+	// OriginalRanges are the authorized IP ranges restored by Stop. No ranges means the API server is open to all.
+	OriginalRanges []string
+	TargetRanges   []string
+	DryRun         bool
+	// RestrictResumeToken resumes the update, which has to finish before the original ranges are restored.
+	RestrictResumeToken string
+}
+
+type clusterRestrictApiServerAttack struct {
+	clientProvider func(subscriptionId string) (ManagedClustersApi, error)
+}
+
+var _ action_kit_sdk.Action[ClusterRestrictApiServerState] = (*clusterRestrictApiServerAttack)(nil)
+var _ action_kit_sdk.ActionWithStop[ClusterRestrictApiServerState] = (*clusterRestrictApiServerAttack)(nil)
+var _ common.ActionWithRequiredPermissions[ClusterRestrictApiServerState] = (*clusterRestrictApiServerAttack)(nil)
+
+func NewClusterRestrictApiServerAction() action_kit_sdk.ActionWithStop[ClusterRestrictApiServerState] {
+	return &clusterRestrictApiServerAttack{
+		clientProvider: func(subscriptionId string) (ManagedClustersApi, error) {
+			return newManagedClustersClient(subscriptionId)
+		},
+	}
+}
+
+func (a *clusterRestrictApiServerAttack) NewEmptyState() ClusterRestrictApiServerState {
+	return ClusterRestrictApiServerState{}
+}
+
+func (a *clusterRestrictApiServerAttack) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    ClusterRestrictApiServerActionId,
+		Label: "Restrict AKS API Server Access",
+		Description: "Replaces the authorized IP ranges of the API server of a public AKS cluster with the given ranges for a given duration and restores the original ranges afterwards. " +
+			"Validates how controllers, CI pipelines and other API server clients react when the API server becomes unreachable.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDCluster,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "by cluster name",
+					Description: new("Find public AKS cluster by name"),
+					Query:       "azure.aks.cluster.name=\"\" and azure.aks.cluster.private-cluster=\"false\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("AKS"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the API server access stays restricted."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("300s"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:        "authorizedIpRanges",
+				Label:       "Authorized IP ranges",
+				Description: new("Comma separated IP addresses or CIDR ranges that may still reach the API server while the attack runs, e.g. '203.0.113.10/32'."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(2),
+				Required:    new(true),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *clusterRestrictApiServerAttack) Prepare(ctx context.Context, state *ClusterRestrictApiServerState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.SubscriptionId = mustHave(request.Target.Attributes, "azure.subscription.id")
+	state.ResourceGroupName = mustHave(request.Target.Attributes, "azure.resource-group.name")
+	state.ClusterName = mustHave(request.Target.Attributes, "azure.aks.cluster.name")
+	if state.SubscriptionId == "" || state.ResourceGroupName == "" || state.ClusterName == "" {
+		return nil, extension_kit.ToError("Target is missing one of: azure.subscription.id, azure.resource-group.name, azure.aks.cluster.name", nil)
+	}
+	state.DryRun = common.IsDryRun(request)
+
+	ranges, err := parseAuthorizedIpRanges(extutil.ToString(request.Config["authorizedIpRanges"]))
+	if err != nil {
+		return nil, err
+	}
+	state.TargetRanges = ranges
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS managed clusters client for subscription %s", state.SubscriptionId), err)
+	}
+	cluster, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get AKS cluster %s", state.ClusterName), err)
+	}
+	state.OriginalRanges = make([]string, 0)
+	if cluster.Properties != nil && cluster.Properties.APIServerAccessProfile != nil {
+		profile := cluster.Properties.APIServerAccessProfile
+		if profile.EnablePrivateCluster != nil && *profile.EnablePrivateCluster {
+			return nil, extension_kit.ToError(fmt.Sprintf("AKS cluster %s is a private cluster. Authorized IP ranges have no effect on private clusters.", state.ClusterName), nil)
+		}
+		for _, r := range profile.AuthorizedIPRanges {
+			if r != nil {
+				state.OriginalRanges = append(state.OriginalRanges, *r)
+			}
+		}
+	}
+
+	original := "none, the API server is open to all networks"
+	if len(state.OriginalRanges) > 0 {
+		original = strings.Join(state.OriginalRanges, ", ")
+	}
+	return &action_kit_api.PrepareResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("The authorized IP ranges of AKS cluster %s will be replaced with %s (currently %s).", state.ClusterName, strings.Join(state.TargetRanges, ", "), original),
+		}}),
+	}, nil
+}
+
+// parseAuthorizedIpRanges splits the comma separated ranges and fails for values that are neither an IP address nor a
+// CIDR range. An empty list is rejected, as it would open the API server to all networks instead of restricting it.
+func parseAuthorizedIpRanges(value string) ([]string, error) {
+	ranges := make([]string, 0)
+	for _, r := range strings.Split(value, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(r); err != nil && net.ParseIP(r) == nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Invalid authorized IP range '%s'. Please use an IP address or a CIDR range.", r), nil)
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, extension_kit.ToError("At least one authorized IP range is required. Without ranges the API server is open to all networks.", nil)
+	}
+	return ranges, nil
+}
+
+func (a *clusterRestrictApiServerAttack) RequiredPermissions(state *ClusterRestrictApiServerState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s", state.SubscriptionId, state.ResourceGroupName, state.ClusterName),
+		Operations: []string{
+			"Microsoft.ContainerService/managedClusters/read",
+			"Microsoft.ContainerService/managedClusters/write",
+		},
+	}}
+}
+
+func (a *clusterRestrictApiServerAttack) Start(ctx context.Context, state *ClusterRestrictApiServerState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("replace the authorized IP ranges of AKS cluster %s with %s", state.ClusterName, strings.Join(state.TargetRanges, ", "))),
+		}, nil
+	}
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS managed clusters client for subscription %s", state.SubscriptionId), err)
+	}
+	operation, err := beginAuthorizedIpRangesUpdate(ctx, client, state, state.TargetRanges)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to restrict the API server access of AKS cluster %s", state.ClusterName), err)
+	}
+	if operation != nil {
+		if state.RestrictResumeToken, err = operation.ResumeToken(); err != nil {
+			log.Warn().Err(err).Msgf("Failed to get the resume token of the update of AKS cluster %s.", state.ClusterName)
+		}
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Started to restrict the API server access of AKS cluster %s to %s.", state.ClusterName, strings.Join(state.TargetRanges, ", ")),
+		}}),
+	}, nil
+}
+
+func (a *clusterRestrictApiServerAttack) Stop(ctx context.Context, state *ClusterRestrictApiServerState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("restore the authorized IP ranges of AKS cluster %s", state.ClusterName)),
+		}, nil
+	}
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS managed clusters client for subscription %s", state.SubscriptionId), err)
+	}
+
+	ctx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+
+	if state.RestrictResumeToken != "" {
+		operation, err := common.NewOperation(client.BeginCreateOrUpdate(ctx, state.ResourceGroupName, state.ClusterName, armcontainerservice.ManagedCluster{}, &armcontainerservice.ManagedClustersClientBeginCreateOrUpdateOptions{ResumeToken: state.RestrictResumeToken}))
+		if err == nil && operation != nil {
+			err = common.WaitForPrevious(ctx, operation)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("The restriction of the API server access of AKS cluster %s did not complete, restoring the original ranges anyway.", state.ClusterName)
+		}
+	}
+
+	operation, err := beginAuthorizedIpRangesUpdate(ctx, client, state, state.OriginalRanges)
+	if err == nil && operation != nil {
+		err = operation.Wait(ctx)
+	}
+	if err != nil && common.IsStopTimeout(ctx) {
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{common.StillInProgress(fmt.Sprintf("restore of the authorized IP ranges of AKS cluster %s", state.ClusterName))}),
+		}, nil
+	}
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to restore the authorized IP ranges of AKS cluster %s", state.ClusterName), err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("AKS cluster %s has its original authorized IP ranges again.", state.ClusterName),
+		}}),
+	}, nil
+}
+
+// beginAuthorizedIpRangesUpdate writes the authorized IP ranges of the cluster. Managed clusters can only be replaced
+// as a whole, so the current cluster is read first to keep all other settings. The agent pool profiles are left out,
+// as AKS then keeps the node pools as they are instead of applying a possibly outdated count or version to them.
+func beginAuthorizedIpRangesUpdate(ctx context.Context, client ManagedClustersApi, state *ClusterRestrictApiServerState, ranges []string) (common.Operation, error) {
+	cluster, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.Properties == nil {
+		cluster.Properties = &armcontainerservice.ManagedClusterProperties{}
+	}
+	if cluster.Properties.APIServerAccessProfile == nil {
+		cluster.Properties.APIServerAccessProfile = &armcontainerservice.ManagedClusterAPIServerAccessProfile{}
+	}
+	// An empty, non-nil list is sent to remove all ranges, as an omitted list keeps the current ones.
+	authorized := make([]*string, 0, len(ranges))
+	for _, r := range ranges {
+		authorized = append(authorized, new(r))
+	}
+	cluster.Properties.APIServerAccessProfile.AuthorizedIPRanges = authorized
+	cluster.Properties.AgentPoolProfiles = nil
+	return common.NewOperation(client.BeginCreateOrUpdate(ctx, state.ResourceGroupName, state.ClusterName, cluster.ManagedCluster, nil))
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func clusterWithAccessProfile(private bool, ranges ...string) armcontainerservice.ManagedClustersClientGetResponse {
+	authorized := make([]*string, 0, len(ranges))
+	for _, r := range ranges {
+		authorized = append(authorized, new(r))
+	}
+	return armcontainerservice.ManagedClustersClientGetResponse{ManagedCluster: armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			APIServerAccessProfile: &armcontainerservice.ManagedClusterAPIServerAccessProfile{
+				EnablePrivateCluster: new(private),
+				AuthorizedIPRanges:   authorized,
+			},
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{{Name: new("pool-1"), Count: new(int32(3))}},
+		},
+	}}
+}
+
+func TestParseAuthorizedIpRanges(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expected      []string
+		expectedError string
+	}{
+		{name: "cidr and ip", value: "203.0.113.0/24, 198.51.100.7", expected: []string{"203.0.113.0/24", "198.51.100.7"}},
+		{name: "invalid range", value: "203.0.113.0/24,internet", expectedError: "Invalid authorized IP range 'internet'."},
+		{name: "empty", value: " , ", expectedError: "At least one authorized IP range is required."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges, err := parseAuthorizedIpRanges(tt.value)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ranges)
+		})
+	}
+}
+
+func TestClusterRestrictApiServer_RestrictsAndRestoresRanges(t *testing.T) {
+	clusters := &managedClustersApiMock{}
+	clusters.On("Get", mock.Anything, "rg-1", "cluster-1").Return(clusterWithAccessProfile(false, "192.0.2.0/24"), nil)
+	clusters.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", []string{"203.0.113.10/32"}).Return(nil, nil).Once()
+	clusters.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", []string{"192.0.2.0/24"}).Return(nil, nil).Once()
+	attack := &clusterRestrictApiServerAttack{clientProvider: func(string) (ManagedClustersApi, error) { return clusters, nil }}
+	state := attack.NewEmptyState()
+	request := clusterStopRequest()
+	request.Config["authorizedIpRanges"] = "203.0.113.10/32"
+
+	_, err := attack.Prepare(context.Background(), &state, request)
+	require.NoError(t, err)
+	_, err = attack.Start(context.Background(), &state)
+	require.NoError(t, err)
+	_, err = attack.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"192.0.2.0/24"}, state.OriginalRanges)
+	require.Len(t, clusters.updates, 2)
+	for _, update := range clusters.updates {
+		assert.Nil(t, update.Properties.AgentPoolProfiles)
+	}
+	clusters.AssertExpectations(t)
+}
+
+func TestClusterRestrictApiServer_ReportsRestoreInProgressAtTheStopTimeout(t *testing.T) {
+	// Given
+	previous := common.StopTimeout
+	common.StopTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { common.StopTimeout = previous })
+	clusters := &managedClustersApiMock{}
+	clusters.On("Get", mock.Anything, "rg-1", "cluster-1").Return(clusterWithAccessProfile(false, "203.0.113.10/32"), nil)
+	clusters.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", []string{"192.0.2.0/24"}).
+		Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+		Return(nil, context.DeadlineExceeded)
+	attack := &clusterRestrictApiServerAttack{clientProvider: func(string) (ManagedClustersApi, error) { return clusters, nil }}
+	state := &ClusterRestrictApiServerState{SubscriptionId: "sub-1", ResourceGroupName: "rg-1", ClusterName: "cluster-1", OriginalRanges: []string{"192.0.2.0/24"}}
+
+	// When
+	result, err := attack.Stop(context.Background(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "The restore of the authorized IP ranges of AKS cluster cluster-1 is still in progress after 50ms.", (*result.Messages)[0].Message)
+}
+
+func TestClusterRestrictApiServer_RejectsPrivateCluster(t *testing.T) {
+	clusters := &managedClustersApiMock{}
+	clusters.On("Get", mock.Anything, "rg-1", "cluster-1").Return(clusterWithAccessProfile(true), nil)
+	attack := &clusterRestrictApiServerAttack{clientProvider: func(string) (ManagedClustersApi, error) { return clusters, nil }}
+	state := attack.NewEmptyState()
+	request := clusterStopRequest()
+	request.Config["authorizedIpRanges"] = "203.0.113.10/32"
+
+	_, err := attack.Prepare(context.Background(), &state, request)
+
+	assert.ErrorContains(t, err, "AKS cluster cluster-1 is a private cluster.")
+}
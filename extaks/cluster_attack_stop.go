@@ -0,0 +1,242 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type ClusterStopState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	ResourceGroupName string
+	ClusterName       string
+	DryRun            bool
+	// StopResumeToken resumes the stop, which has to finish before the cluster can be started again.
+	StopResumeToken string
+}
+
+type clusterStopAttack struct {
+	clientProvider func(subscriptionId string) (ManagedClustersApi, error)
+}
+
+var _ action_kit_sdk.Action[ClusterStopState] = (*clusterStopAttack)(nil)
+var _ action_kit_sdk.ActionWithStop[ClusterStopState] = (*clusterStopAttack)(nil)
+var _ common.ActionWithRequiredPermissions[ClusterStopState] = (*clusterStopAttack)(nil)
+
+func NewClusterStopAction() action_kit_sdk.ActionWithStop[ClusterStopState] {
+	return &clusterStopAttack{
+		clientProvider: func(subscriptionId string) (ManagedClustersApi, error) {
+			return newManagedClustersClient(subscriptionId)
+		},
+	}
+}
+
+func (a *clusterStopAttack) NewEmptyState() ClusterStopState {
+	return ClusterStopState{}
+}
+
+func (a *clusterStopAttack) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    ClusterStopActionId,
+		Label: "Stop AKS Cluster",
+		Description: "Stops an AKS cluster, including its control plane and all node pools, for a given duration and starts it again afterwards. " +
+			"Validates how dependent services react when a whole cluster goes away.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDCluster,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "by cluster name",
+					Description: new("Find AKS cluster by name"),
+					Query:       "azure.aks.cluster.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("AKS"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the cluster stays stopped."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("300s"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *clusterStopAttack) Prepare(ctx context.Context, state *ClusterStopState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.SubscriptionId = mustHave(request.Target.Attributes, "azure.subscription.id")
+	state.ResourceGroupName = mustHave(request.Target.Attributes, "azure.resource-group.name")
+	state.ClusterName = mustHave(request.Target.Attributes, "azure.aks.cluster.name")
+	if state.SubscriptionId == "" || state.ResourceGroupName == "" || state.ClusterName == "" {
+		return nil, extension_kit.ToError("Target is missing one of: azure.subscription.id, azure.resource-group.name, azure.aks.cluster.name", nil)
+	}
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS managed clusters client for subscription %s", state.SubscriptionId), err)
+	}
+	cluster, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get AKS cluster %s", state.ClusterName), err)
+	}
+	if powerState, _ := clusterStateOf(cluster.ManagedCluster); strings.EqualFold(powerState, string(armcontainerservice.CodeStopped)) {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS cluster %s is already stopped.", state.ClusterName), nil)
+	}
+
+	return &action_kit_api.PrepareResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("AKS cluster %s in resource group %s will be stopped.", state.ClusterName, state.ResourceGroupName),
+		}}),
+	}, nil
+}
+
+func (a *clusterStopAttack) RequiredPermissions(state *ClusterStopState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s", state.SubscriptionId, state.ResourceGroupName, state.ClusterName),
+		Operations: []string{
+			"Microsoft.ContainerService/managedClusters/read",
+			"Microsoft.ContainerService/managedClusters/stop/action",
+			"Microsoft.ContainerService/managedClusters/start/action",
+		},
+	}}
+}
+
+func (a *clusterStopAttack) Start(ctx context.Context, state *ClusterStopState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("stop AKS cluster %s in resource group %s", state.ClusterName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS managed clusters client for subscription %s", state.SubscriptionId), err)
+	}
+	operation, err := common.NewOperation(client.BeginStop(ctx, state.ResourceGroupName, state.ClusterName, nil))
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to stop AKS cluster %s", state.ClusterName), err)
+	}
+	if operation != nil {
+		if state.StopResumeToken, err = operation.ResumeToken(); err != nil {
+			log.Warn().Err(err).Msgf("Failed to get the resume token of the stop of AKS cluster %s.", state.ClusterName)
+		}
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Started to stop AKS cluster %s.", state.ClusterName),
+		}}),
+	}, nil
+}
+
+func (a *clusterStopAttack) Stop(ctx context.Context, state *ClusterStopState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("start AKS cluster %s in resource group %s again", state.ClusterName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS managed clusters client for subscription %s", state.SubscriptionId), err)
+	}
+
+	// Starting an AKS cluster often takes longer than the StopTimeout. A cluster that is not running by then is reported
+	// as still starting, neither as recovered nor as failed.
+	ctx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+
+	if state.StopResumeToken != "" {
+		operation, err := common.NewOperation(client.BeginStop(ctx, state.ResourceGroupName, state.ClusterName, &armcontainerservice.ManagedClustersClientBeginStopOptions{ResumeToken: state.StopResumeToken}))
+		if err == nil && operation != nil {
+			err = common.WaitForPrevious(ctx, operation)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("The stop of AKS cluster %s did not complete, starting it anyway.", state.ClusterName)
+		}
+	}
+
+	operation, err := common.NewOperation(client.BeginStart(ctx, state.ResourceGroupName, state.ClusterName, nil))
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to start AKS cluster %s", state.ClusterName), err)
+	}
+	if operation != nil {
+		err = operation.Wait(ctx)
+	}
+	powerState, provisioningState := "", ""
+	if err == nil {
+		// A cluster is only back once it runs and no update is in progress anymore.
+		err = common.WaitUntil(ctx, common.StopTimeout, func(ctx context.Context) (bool, error) {
+			cluster, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, nil)
+			if err != nil {
+				return false, err
+			}
+			powerState, provisioningState = clusterStateOf(cluster.ManagedCluster)
+			return strings.EqualFold(powerState, string(armcontainerservice.CodeRunning)) && strings.EqualFold(provisioningState, "Succeeded"), nil
+		})
+	}
+	if err != nil && common.IsStopTimeout(ctx) {
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{
+				common.StillInProgress(fmt.Sprintf("start of AKS cluster %s", state.ClusterName)),
+				{
+					Level:   extutil.Ptr(action_kit_api.Info),
+					Message: fmt.Sprintf("The last power state of AKS cluster %s was '%s', the last provisioning state '%s'.", state.ClusterName, powerState, provisioningState),
+				},
+			}),
+		}, nil
+	}
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS cluster %s is not running again", state.ClusterName), err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("AKS cluster %s is running again.", state.ClusterName),
+		}}),
+	}, nil
+}
+
+// clusterStateOf returns the power state and the provisioning state of a managed cluster, e.g. "Running" and "Succeeded".
+func clusterStateOf(cluster armcontainerservice.ManagedCluster) (string, string) {
+	if cluster.Properties == nil {
+		return "", ""
+	}
+	powerState, provisioningState := "", ""
+	if cluster.Properties.PowerState != nil && cluster.Properties.PowerState.Code != nil {
+		powerState = string(*cluster.Properties.PowerState.Code)
+	}
+	if cluster.Properties.ProvisioningState != nil {
+		provisioningState = *cluster.Properties.ProvisioningState
+	}
+	return powerState, provisioningState
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type managedClustersApiMock struct {
+	mock.Mock
+	updates []armcontainerservice.ManagedCluster
+}
+
+func (m *managedClustersApiMock) Get(ctx context.Context, resourceGroupName string, resourceName string, _ *armcontainerservice.ManagedClustersClientGetOptions) (armcontainerservice.ManagedClustersClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, resourceName)
+	return args.Get(0).(armcontainerservice.ManagedClustersClientGetResponse), args.Error(1)
+}
+
+func (m *managedClustersApiMock) BeginStop(ctx context.Context, resourceGroupName string, resourceName string, _ *armcontainerservice.ManagedClustersClientBeginStopOptions) (*runtime.Poller[armcontainerservice.ManagedClustersClientStopResponse], error) {
+	args := m.Called(ctx, resourceGroupName, resourceName)
+	return nil, args.Error(1)
+}
+
+func (m *managedClustersApiMock) BeginStart(ctx context.Context, resourceGroupName string, resourceName string, _ *armcontainerservice.ManagedClustersClientBeginStartOptions) (*runtime.Poller[armcontainerservice.ManagedClustersClientStartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, resourceName)
+	return nil, args.Error(1)
+}
+
+func (m *managedClustersApiMock) BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, resourceName string, parameters armcontainerservice.ManagedCluster, _ *armcontainerservice.ManagedClustersClientBeginCreateOrUpdateOptions) (*runtime.Poller[armcontainerservice.ManagedClustersClientCreateOrUpdateResponse], error) {
+	ranges := make([]string, 0)
+	for _, r := range parameters.Properties.APIServerAccessProfile.AuthorizedIPRanges {
+		ranges = append(ranges, *r)
+	}
+	m.updates = append(m.updates, parameters)
+	args := m.Called(ctx, resourceGroupName, resourceName, ranges)
+	return nil, args.Error(1)
+}
+
+func managedCluster(powerState armcontainerservice.Code, provisioningState string) armcontainerservice.ManagedClustersClientGetResponse {
+	return armcontainerservice.ManagedClustersClientGetResponse{ManagedCluster: armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			PowerState:        &armcontainerservice.PowerState{Code: new(powerState)},
+			ProvisioningState: new(provisioningState),
+		},
+	}}
+}
+
+func clusterStopRequest() action_kit_api.PrepareActionRequestBody {
+	return action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"duration": 60000},
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure.subscription.id":     {"sub-1"},
+			"azure.resource-group.name": {"rg-1"},
+			"azure.aks.cluster.name":    {"cluster-1"},
+		}},
+	}
+}
+
+func TestClusterStop_StopsAndStartsCluster(t *testing.T) {
+	clusters := &managedClustersApiMock{}
+	clusters.On("Get", mock.Anything, "rg-1", "cluster-1").Return(managedCluster(armcontainerservice.CodeRunning, "Succeeded"), nil)
+	clusters.On("BeginStop", mock.Anything, "rg-1", "cluster-1").Return(nil, nil)
+	clusters.On("BeginStart", mock.Anything, "rg-1", "cluster-1").Return(nil, nil)
+	attack := &clusterStopAttack{clientProvider: func(string) (ManagedClustersApi, error) { return clusters, nil }}
+	state := attack.NewEmptyState()
+
+	_, err := attack.Prepare(context.Background(), &state, clusterStopRequest())
+	require.NoError(t, err)
+	_, err = attack.Start(context.Background(), &state)
+	require.NoError(t, err)
+	result, err := attack.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	assert.Equal(t, "AKS cluster cluster-1 is running again.", (*result.Messages)[0].Message)
+	clusters.AssertExpectations(t)
+}
+
+func TestClusterStop_ReportsStartInProgressIfClusterIsStillUpdatingAfterStopTimeout(t *testing.T) {
+	// Given
+	previous := common.StopTimeout
+	common.StopTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { common.StopTimeout = previous })
+	clusters := &managedClustersApiMock{}
+	clusters.On("Get", mock.Anything, "rg-1", "cluster-1").Return(managedCluster(armcontainerservice.CodeRunning, "Updating"), nil)
+	clusters.On("BeginStart", mock.Anything, "rg-1", "cluster-1").Return(nil, nil)
+	attack := &clusterStopAttack{clientProvider: func(string) (ManagedClustersApi, error) { return clusters, nil }}
+	state := &ClusterStopState{SubscriptionId: "sub-1", ResourceGroupName: "rg-1", ClusterName: "cluster-1"}
+
+	// When
+	result, err := attack.Stop(context.Background(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Nil(t, result.Error)
+	require.Len(t, *result.Messages, 2)
+	assert.Equal(t, action_kit_api.Warn, *(*result.Messages)[0].Level)
+	assert.Equal(t, "The start of AKS cluster cluster-1 is still in progress after 50ms.", (*result.Messages)[0].Message)
+	assert.Equal(t, "The last power state of AKS cluster cluster-1 was 'Running', the last provisioning state 'Updating'.", (*result.Messages)[1].Message)
+}
+
+func TestClusterStop_RejectsStoppedCluster(t *testing.T) {
+	clusters := &managedClustersApiMock{}
+	clusters.On("Get", mock.Anything, "rg-1", "cluster-1").Return(managedCluster(armcontainerservice.CodeStopped, "Succeeded"), nil)
+	attack := &clusterStopAttack{clientProvider: func(string) (ManagedClustersApi, error) { return clusters, nil }}
+	state := attack.NewEmptyState()
+
+	_, err := attack.Prepare(context.Background(), &state, clusterStopRequest())
+
+	assert.ErrorContains(t, err, "AKS cluster cluster-1 is already stopped.")
+}
+
+func TestClusterStopDescribe(t *testing.T) {
+	desc := NewClusterStopAction().Describe()
+	assert.Equal(t, ClusterStopActionId, desc.Id)
+	assert.Equal(t, TargetIDCluster, desc.TargetSelection.TargetType)
+	assert.Equal(t, action_kit_api.TimeControlExternal, desc.TimeControl)
+}
@@ -34,6 +34,7 @@ var (
 // reliability config (kubernetes version, API-server public-access posture, network plugin/policy, AAD/RBAC,
 // SKU tier, etc.). The K8s extension already carries k8s.cluster-name on its targets; we join on that.
 var aksEnrichmentTargetTypes = []string{
+	"com.steadybit.extension_kubernetes.kubernetes-cluster",
 	"com.steadybit.extension_kubernetes.kubernetes-deployment",
 	"com.steadybit.extension_kubernetes.kubernetes-pod",
 	"com.steadybit.extension_kubernetes.kubernetes-statefulset",
@@ -124,6 +125,10 @@ func (d *clusterDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDes
 }
 
 func (d *clusterDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDCluster, d.discoverTargets)
+}
+
+func (d *clusterDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
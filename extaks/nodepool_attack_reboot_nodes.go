@@ -0,0 +1,412 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	nodeRebootModeRestart  = "restart"
+	nodeRebootModePowerOff = "power-off"
+)
+
+type NodePoolRebootNodesState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	ResourceGroupName string
+	ClusterName       string
+	NodePoolName      string
+	Mode              string
+	Percentage        int
+	Zone              string
+	// InstanceIds are the ARM IDs of the scale set instances backing the selected machines.
+	InstanceIds []string
+	DryRun      bool
+	// ResumeTokens resume the restart or power-off per instance ID, which has to finish before the node is started.
+	ResumeTokens map[string]string
+	// Skipped holds the instance IDs Start did not reboot, with the reason: the instance was already stopped or its
+	// reboot failed. Stop leaves them alone.
+	Skipped map[string]string
+}
+
+// NodeScaleSetVMsApi captures the subset of armcompute.VirtualMachineScaleSetVMsClient used to reboot nodes.
+type NodeScaleSetVMsApi interface {
+	ScaleSetVMReaderApi
+	GetInstanceView(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewOptions) (armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse, error)
+	BeginRestart(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginRestartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientRestartResponse], error)
+	BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientPowerOffResponse], error)
+	BeginStart(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientStartResponse], error)
+}
+
+type nodePoolRebootNodesAttack struct {
+	machinesProvider    func(subscriptionId string) (MachinesApi, error)
+	scaleSetVMsProvider func(subscriptionId string) (NodeScaleSetVMsApi, error)
+	rng                 func(n int) []int
+}
+
+var _ action_kit_sdk.Action[NodePoolRebootNodesState] = (*nodePoolRebootNodesAttack)(nil)
+var _ action_kit_sdk.ActionWithStop[NodePoolRebootNodesState] = (*nodePoolRebootNodesAttack)(nil)
+var _ common.ActionWithRequiredPermissions[NodePoolRebootNodesState] = (*nodePoolRebootNodesAttack)(nil)
+
+func NewNodePoolRebootNodesAction() action_kit_sdk.ActionWithStop[NodePoolRebootNodesState] {
+	return &nodePoolRebootNodesAttack{
+		machinesProvider: func(subscriptionId string) (MachinesApi, error) {
+			return newMachinesClient(subscriptionId)
+		},
+		scaleSetVMsProvider: func(subscriptionId string) (NodeScaleSetVMsApi, error) {
+			return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
+		},
+		rng: rand.Perm,
+	}
+}
+
+func (a *nodePoolRebootNodesAttack) NewEmptyState() NodePoolRebootNodesState {
+	return NodePoolRebootNodesState{}
+}
+
+func (a *nodePoolRebootNodesAttack) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    NodePoolRebootNodesActionId,
+		Label: "Reboot Nodes",
+		Description: "Restarts or powers off a percentage of the nodes of an AKS node pool, optionally only nodes of one availability zone. " +
+			"Unlike terminating nodes, the nodes keep their identity and come back. Powered off nodes are started again when the attack ends.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(nodePoolIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDNodePool,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "by cluster and node pool name",
+					Description: new("Find AKS node pool by cluster name and node pool name"),
+					Query:       "azure.aks.cluster.name=\"\" and azure.aks.nodepool.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("AKS"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long powered off nodes stay off. Restarted nodes come back on their own."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("120s"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  new("Whether to restart the nodes or to power them off until the attack ends."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(nodeRebootModeRestart),
+				Order:        new(2),
+				Required:     new(true),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "Restart", Value: nodeRebootModeRestart},
+					action_kit_api.ExplicitParameterOption{Label: "Power off", Value: nodeRebootModePowerOff},
+				}),
+			},
+			{
+				Name:         "percentage",
+				Label:        "Percentage of nodes",
+				Description:  new("Percentage (1-100) of the node pool's nodes to reboot. Defaults to 33%."),
+				Type:         action_kit_api.ActionParameterTypePercentage,
+				DefaultValue: new("33"),
+				Order:        new(3),
+				Required:     new(true),
+				MinValue:     new(1),
+				MaxValue:     new(100),
+			},
+			{
+				Name:        "zone",
+				Label:       "Zone",
+				Description: new("Only pick nodes of this availability zone, e.g. '1'. Leave empty to pick from all nodes."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(4),
+				Required:    new(false),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *nodePoolRebootNodesAttack) Prepare(ctx context.Context, state *NodePoolRebootNodesState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.SubscriptionId = mustHave(request.Target.Attributes, "azure.subscription.id")
+	state.ResourceGroupName = mustHave(request.Target.Attributes, "azure.resource-group.name")
+	state.ClusterName = mustHave(request.Target.Attributes, "azure.aks.cluster.name")
+	state.NodePoolName = mustHave(request.Target.Attributes, "azure.aks.nodepool.name")
+	if state.SubscriptionId == "" || state.ResourceGroupName == "" || state.ClusterName == "" || state.NodePoolName == "" {
+		return nil, extension_kit.ToError("Target is missing one of: azure.subscription.id, azure.resource-group.name, azure.aks.cluster.name, azure.aks.nodepool.name", nil)
+	}
+
+	state.Mode = extutil.ToString(request.Config["mode"])
+	if state.Mode == "" {
+		state.Mode = nodeRebootModeRestart
+	}
+	if state.Mode != nodeRebootModeRestart && state.Mode != nodeRebootModePowerOff {
+		return nil, extension_kit.ToError(fmt.Sprintf("Unknown mode '%s'.", state.Mode), nil)
+	}
+	pct := extutil.ToInt(request.Config["percentage"])
+	if pct < 1 || pct > 100 {
+		return nil, extension_kit.ToError("The percentage must be between 1 and 100.", nil)
+	}
+	state.Percentage = pct
+	state.Zone = strings.TrimSpace(extutil.ToString(request.Config["zone"]))
+
+	machinesClient, err := a.machinesProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS machines client for subscription %s", state.SubscriptionId), err)
+	}
+	machines, err := listNodePoolMachines(ctx, machinesClient, state.ResourceGroupName, state.ClusterName, state.NodePoolName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to list machines for AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+	for _, m := range machines {
+		if m.ResourceID != "" && !m.isScaleSetInstance() {
+			return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s is not backed by a virtual machine scale set. Only the nodes of scale set based node pools can be rebooted.", state.ClusterName, state.NodePoolName), nil)
+		}
+	}
+	candidates, err := a.rebootCandidates(ctx, state, machines)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to determine the zones of the machines of AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+	if len(candidates) == 0 {
+		if state.Zone != "" {
+			return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s has no scale set instances in zone '%s'", state.ClusterName, state.NodePoolName, state.Zone), nil)
+		}
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s has no scale set instances to reboot", state.ClusterName, state.NodePoolName), nil)
+	}
+
+	sampleSize := sampleSizeOf(len(candidates), pct)
+	perm := a.rng(len(candidates))
+	names := make([]string, 0, sampleSize)
+	state.InstanceIds = make([]string, 0, sampleSize)
+	for i := 0; i < sampleSize; i++ {
+		names = append(names, candidates[perm[i]].Name)
+		state.InstanceIds = append(state.InstanceIds, candidates[perm[i]].ResourceID)
+	}
+	sort.Strings(names)
+	sort.Strings(state.InstanceIds)
+	state.DryRun = common.IsDryRun(request)
+
+	return &action_kit_api.PrepareResult{
+		Messages: new([]action_kit_api.Message{{
+			Level: extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Selected %d of %d machine(s) (%d%%) in AKS node pool %s/%s to %s: %v",
+				sampleSize, len(candidates), pct, state.ClusterName, state.NodePoolName, state.Mode, names),
+		}}),
+	}, nil
+}
+
+// rebootCandidates returns the machines backed by a scale set instance, restricted to the zone if one is given.
+func (a *nodePoolRebootNodesAttack) rebootCandidates(ctx context.Context, state *NodePoolRebootNodesState, machines []nodePoolMachine) ([]nodePoolMachine, error) {
+	if state.Zone != "" {
+		client, err := a.scaleSetVMsProvider(state.SubscriptionId)
+		if err != nil {
+			return nil, err
+		}
+		if err := resolveMachineZones(ctx, client, machines); err != nil {
+			return nil, err
+		}
+	}
+	candidates := make([]nodePoolMachine, 0, len(machines))
+	for _, m := range machines {
+		if m.isScaleSetInstance() && (state.Zone == "" || m.Zone == state.Zone) {
+			candidates = append(candidates, m)
+		}
+	}
+	return candidates, nil
+}
+
+func (a *nodePoolRebootNodesAttack) RequiredPermissions(state *NodePoolRebootNodesState) []common.PermissionRequirement {
+	operations := []string{
+		"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/read",
+		"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/instanceView/read",
+	}
+	if state.Mode == nodeRebootModePowerOff {
+		operations = append(operations,
+			"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/powerOff/action",
+			"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/start/action")
+	} else {
+		operations = append(operations, "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/restart/action")
+	}
+	requirements := make([]common.PermissionRequirement, 0, len(state.InstanceIds))
+	for _, id := range state.InstanceIds {
+		requirements = append(requirements, common.PermissionRequirement{Scope: id, Operations: operations})
+	}
+	return requirements
+}
+
+func (a *nodePoolRebootNodesAttack) Start(ctx context.Context, state *NodePoolRebootNodesState) (*action_kit_api.StartResult, error) {
+	if len(state.InstanceIds) == 0 {
+		return nil, extension_kit.ToError("No machines selected to reboot.", nil)
+	}
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.InstanceIds))
+		for _, id := range state.InstanceIds {
+			mutations = append(mutations, fmt.Sprintf("%s %s in AKS node pool %s/%s", state.Mode, id, state.ClusterName, state.NodePoolName))
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+	client, err := a.scaleSetVMsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize scale set VMs client for subscription %s", state.SubscriptionId), err)
+	}
+
+	// A failed reboot of one instance does not stop the others. It is recorded per instance, like the instances
+	// which are already stopped, so that Stop does not start an instance Start did not stop.
+	state.ResumeTokens = make(map[string]string, len(state.InstanceIds))
+	state.Skipped = make(map[string]string)
+	messages := make([]action_kit_api.Message, 0, len(state.InstanceIds))
+	for _, id := range state.InstanceIds {
+		skipped, err := a.reboot(ctx, client, state, id)
+		if skipped != "" {
+			state.Skipped[id] = skipped
+			messages = append(messages, action_kit_api.Message{
+				Level:   extutil.Ptr(action_kit_api.Warn),
+				Message: fmt.Sprintf("%s is already %s. It is skipped and will not be started on stop.", id, skipped),
+			})
+			continue
+		}
+		messages = append(messages, common.Outcome(err, fmt.Sprintf("%s of %s", state.Mode, id)))
+		if err != nil {
+			state.Skipped[id] = err.Error()
+		}
+	}
+
+	if len(state.Skipped) == len(state.InstanceIds) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to %s any machine in AKS node pool %s/%s", state.Mode, state.ClusterName, state.NodePoolName), nil)
+	}
+	return &action_kit_api.StartResult{Messages: &messages}, nil
+}
+
+// reboot restarts or powers off the instance. An instance which is already stopped is not powered off, its power
+// state is returned instead.
+func (a *nodePoolRebootNodesAttack) reboot(ctx context.Context, client NodeScaleSetVMsApi, state *NodePoolRebootNodesState, id string) (string, error) {
+	instance, err := parseInstanceId(id)
+	if err != nil {
+		return "", err
+	}
+	if state.Mode == nodeRebootModePowerOff {
+		instanceView, err := client.GetInstanceView(ctx, instance.ResourceGroupName, instance.Parent.Name, instance.Name, nil)
+		if err != nil {
+			return "", err
+		}
+		if powerState := common.PowerStateOf(instanceView.Statuses); common.IsStoppedPowerState(powerState) {
+			return powerState, nil
+		}
+	}
+	operation, err := beginReboot(ctx, client, state.Mode, instance, "")
+	if err != nil || operation == nil {
+		return "", err
+	}
+	if state.ResumeTokens[id], err = operation.ResumeToken(); err != nil {
+		log.Warn().Err(err).Msgf("Failed to get the resume token of the %s of %s.", state.Mode, id)
+	}
+	return "", nil
+}
+
+func (a *nodePoolRebootNodesAttack) Stop(ctx context.Context, state *NodePoolRebootNodesState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("wait until the %d machine(s) of AKS node pool %s/%s are running again", len(state.InstanceIds), state.ClusterName, state.NodePoolName)),
+		}, nil
+	}
+	client, err := a.scaleSetVMsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize scale set VMs client for subscription %s", state.SubscriptionId), err)
+	}
+
+	ctx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+
+	restart := state.Mode
+	if state.Mode == nodeRebootModePowerOff {
+		restart = "start"
+	}
+	previous := make([]common.Operation, 0)
+	restarts := make([]common.Restart, 0, len(state.InstanceIds))
+	for _, id := range state.InstanceIds {
+		if _, skipped := state.Skipped[id]; skipped {
+			continue
+		}
+		instance, err := parseInstanceId(id)
+		if err == nil && state.ResumeTokens[id] != "" {
+			operation, err := beginReboot(ctx, client, state.Mode, instance, state.ResumeTokens[id])
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to resume the %s of %s, continuing anyway.", state.Mode, id)
+			} else if operation != nil {
+				previous = append(previous, operation)
+			}
+		}
+		restarts = append(restarts, common.Restart{
+			Description: fmt.Sprintf("%s of %s", restart, id),
+			Begin: func(ctx context.Context) (common.Operation, error) {
+				if err != nil || state.Mode != nodeRebootModePowerOff {
+					return nil, err
+				}
+				return common.NewOperation(client.BeginStart(ctx, instance.ResourceGroupName, instance.Parent.Name, instance.Name, nil))
+			},
+			Wait: func(ctx context.Context, start common.Operation) error {
+				return waitUntilRunning(ctx, client, instance, start)
+			},
+		})
+	}
+	messages, failed := common.BringBack(ctx, fmt.Sprintf("machines of AKS node pool %s/%s", state.ClusterName, state.NodePoolName), previous, restarts)
+
+	if len(failed) > 0 {
+		return &action_kit_api.StopResult{
+			Messages: &messages,
+			Error: &action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Failed to bring %d machine(s) of AKS node pool %s/%s back.", len(failed), state.ClusterName, state.NodePoolName),
+				Detail: new(strings.Join(failed, ", ")),
+				Status: new(action_kit_api.Errored),
+			},
+		}, nil
+	}
+	return &action_kit_api.StopResult{Messages: &messages}, nil
+}
+
+// waitUntilRunning waits for the start of the instance, if it was powered off, and until it is running again.
+func waitUntilRunning(ctx context.Context, client NodeScaleSetVMsApi, instance *arm.ResourceID, start common.Operation) error {
+	if start != nil {
+		if err := start.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return common.WaitForPowerState(ctx, fmt.Sprintf("instance '%s' of scale set '%s'", instance.Name, instance.Parent.Name), "running", common.StopTimeout, func(ctx context.Context) (string, error) {
+		instanceView, err := client.GetInstanceView(ctx, instance.ResourceGroupName, instance.Parent.Name, instance.Name, nil)
+		return common.PowerStateOf(instanceView.Statuses), err
+	})
+}
+
+// beginReboot restarts or powers off the instance, or resumes that operation if a resume token is given.
+func beginReboot(ctx context.Context, client NodeScaleSetVMsApi, mode string, instance *arm.ResourceID, resumeToken string) (common.Operation, error) {
+	if mode == nodeRebootModePowerOff {
+		return common.NewOperation(client.BeginPowerOff(ctx, instance.ResourceGroupName, instance.Parent.Name, instance.Name, &armcompute.VirtualMachineScaleSetVMsClientBeginPowerOffOptions{ResumeToken: resumeToken}))
+	}
+	return common.NewOperation(client.BeginRestart(ctx, instance.ResourceGroupName, instance.Parent.Name, instance.Name, &armcompute.VirtualMachineScaleSetVMsClientBeginRestartOptions{ResumeToken: resumeToken}))
+}
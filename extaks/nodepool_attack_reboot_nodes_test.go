@@ -0,0 +1,188 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type nodeScaleSetVMsApiMock struct {
+	mock.Mock
+	zones   map[string]string
+	stopped []string
+}
+
+func (m *nodeScaleSetVMsApiMock) Get(_ context.Context, _ string, _ string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientGetOptions) (armcompute.VirtualMachineScaleSetVMsClientGetResponse, error) {
+	return armcompute.VirtualMachineScaleSetVMsClientGetResponse{VirtualMachineScaleSetVM: armcompute.VirtualMachineScaleSetVM{Zones: []*string{new(m.zones[instanceID])}}}, nil
+}
+
+func (m *nodeScaleSetVMsApiMock) GetInstanceView(_ context.Context, _ string, _ string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewOptions) (armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse, error) {
+	powerState := "PowerState/running"
+	if slices.Contains(m.stopped, instanceID) {
+		powerState = "PowerState/stopped"
+	}
+	return armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse{VirtualMachineScaleSetVMInstanceView: armcompute.VirtualMachineScaleSetVMInstanceView{
+		Statuses: []*armcompute.InstanceViewStatus{{Code: new(powerState)}},
+	}}, nil
+}
+
+func (m *nodeScaleSetVMsApiMock) BeginRestart(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientBeginRestartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientRestartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return nil, args.Error(1)
+}
+
+func (m *nodeScaleSetVMsApiMock) BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientPowerOffResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return nil, args.Error(1)
+}
+
+func (m *nodeScaleSetVMsApiMock) BeginStart(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientStartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return nil, args.Error(1)
+}
+
+func rebootPrepareRequest(config map[string]any) action_kit_api.PrepareActionRequestBody {
+	return action_kit_api.PrepareActionRequestBody{
+		Config: config,
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure.subscription.id":     {"sub-1"},
+			"azure.resource-group.name": {"rg-1"},
+			"azure.aks.cluster.name":    {"cluster-1"},
+			"azure.aks.nodepool.name":   {"spot"},
+		}},
+	}
+}
+
+func newRebootNodesAttack(scaleSetVMs *nodeScaleSetVMsApiMock) *nodePoolRebootNodesAttack {
+	machines := &machinesApiMock{}
+	machines.On("NewListPager", "rg-1", "cluster-1", "spot", mock.Anything).Return(spotMachinesPage("0", "1", "2", "3"), nil)
+	return &nodePoolRebootNodesAttack{
+		machinesProvider:    func(string) (MachinesApi, error) { return machines, nil },
+		scaleSetVMsProvider: func(string) (NodeScaleSetVMsApi, error) { return scaleSetVMs, nil },
+		rng:                 identityPerm,
+	}
+}
+
+func TestNodePoolRebootNodes_RestartsSelectedInstances(t *testing.T) {
+	scaleSetVMs := &nodeScaleSetVMsApiMock{}
+	scaleSetVMs.On("BeginRestart", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "0").Return(nil, nil)
+	scaleSetVMs.On("BeginRestart", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "1").Return(nil, nil)
+	attack := newRebootNodesAttack(scaleSetVMs)
+	state := attack.NewEmptyState()
+
+	_, err := attack.Prepare(context.Background(), &state, rebootPrepareRequest(map[string]any{"mode": nodeRebootModeRestart, "percentage": 50}))
+	require.NoError(t, err)
+	_, err = attack.Start(context.Background(), &state)
+	require.NoError(t, err)
+	_, err = attack.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	scaleSetVMs.AssertExpectations(t)
+	scaleSetVMs.AssertNotCalled(t, "BeginStart", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNodePoolRebootNodes_PowersOffInstancesOfZoneAndStartsThemOnStop(t *testing.T) {
+	scaleSetVMs := &nodeScaleSetVMsApiMock{zones: map[string]string{"0": "1", "1": "2", "2": "1", "3": "2"}}
+	scaleSetVMs.On("BeginPowerOff", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "1").Return(nil, nil)
+	scaleSetVMs.On("BeginPowerOff", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "3").Return(nil, nil)
+	scaleSetVMs.On("BeginStart", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "1").Return(nil, nil)
+	scaleSetVMs.On("BeginStart", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "3").Return(nil, nil)
+	attack := newRebootNodesAttack(scaleSetVMs)
+	state := attack.NewEmptyState()
+
+	_, err := attack.Prepare(context.Background(), &state, rebootPrepareRequest(map[string]any{"mode": nodeRebootModePowerOff, "percentage": 100, "zone": "2"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{nodeScaleSet + "/virtualMachines/1", nodeScaleSet + "/virtualMachines/3"}, state.InstanceIds)
+	_, err = attack.Start(context.Background(), &state)
+	require.NoError(t, err)
+	stopResult, err := attack.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	assert.Nil(t, stopResult.Error)
+	require.Len(t, *stopResult.Messages, 2)
+	assert.Equal(t, "The start of "+nodeScaleSet+"/virtualMachines/1 succeeded.", (*stopResult.Messages)[0].Message)
+	scaleSetVMs.AssertExpectations(t)
+}
+
+func TestNodePoolRebootNodes_SkipsStoppedAndFailedInstances(t *testing.T) {
+	// Given
+	scaleSetVMs := &nodeScaleSetVMsApiMock{stopped: []string{"1"}}
+	scaleSetVMs.On("BeginPowerOff", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "0").Return(nil, nil)
+	scaleSetVMs.On("BeginPowerOff", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "2").Return(nil, errors.New("conflict"))
+	scaleSetVMs.On("BeginStart", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "0").Return(nil, nil)
+	attack := newRebootNodesAttack(scaleSetVMs)
+	state := attack.NewEmptyState()
+	_, err := attack.Prepare(context.Background(), &state, rebootPrepareRequest(map[string]any{"mode": nodeRebootModePowerOff, "percentage": 75}))
+	require.NoError(t, err)
+
+	// When
+	startResult, err := attack.Start(context.Background(), &state)
+	require.NoError(t, err)
+	_, err = attack.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	// Then
+	require.Len(t, *startResult.Messages, 3)
+	assert.Equal(t, action_kit_api.Warn, *(*startResult.Messages)[1].Level)
+	assert.Equal(t, nodeScaleSet+"/virtualMachines/1 is already stopped. It is skipped and will not be started on stop.", (*startResult.Messages)[1].Message)
+	assert.Equal(t, "The power-off of "+nodeScaleSet+"/virtualMachines/2 failed: conflict", (*startResult.Messages)[2].Message)
+	assert.Equal(t, map[string]string{nodeScaleSet + "/virtualMachines/1": "stopped", nodeScaleSet + "/virtualMachines/2": "conflict"}, state.Skipped)
+	scaleSetVMs.AssertExpectations(t)
+	scaleSetVMs.AssertNotCalled(t, "BeginPowerOff", mock.Anything, mock.Anything, mock.Anything, "1")
+	scaleSetVMs.AssertNotCalled(t, "BeginStart", mock.Anything, mock.Anything, mock.Anything, "1")
+	scaleSetVMs.AssertNotCalled(t, "BeginStart", mock.Anything, mock.Anything, mock.Anything, "2")
+}
+
+func TestNodePoolRebootNodes_RejectsPoolNotBackedByScaleSet(t *testing.T) {
+	// Given
+	machines := &machinesApiMock{}
+	machines.On("NewListPager", "rg-1", "cluster-1", "spot", mock.Anything).Return(&armcontainerservice.MachinesClientListResponse{MachineListResult: armcontainerservice.MachineListResult{Value: []*armcontainerservice.Machine{{
+		Name:       new("aks-spot-12345-vm0"),
+		Properties: &armcontainerservice.MachineProperties{ResourceID: new("/subscriptions/sub-1/resourceGroups/MC_rg-1_cluster-1_westeurope/providers/Microsoft.Compute/virtualMachines/aks-spot-12345-vm0")},
+	}}}}, nil)
+	attack := &nodePoolRebootNodesAttack{
+		machinesProvider:    func(string) (MachinesApi, error) { return machines, nil },
+		scaleSetVMsProvider: func(string) (NodeScaleSetVMsApi, error) { return &nodeScaleSetVMsApiMock{}, nil },
+		rng:                 identityPerm,
+	}
+	state := attack.NewEmptyState()
+
+	// When
+	_, err := attack.Prepare(context.Background(), &state, rebootPrepareRequest(map[string]any{"mode": nodeRebootModeRestart, "percentage": 100}))
+
+	// Then
+	assert.ErrorContains(t, err, "AKS node pool cluster-1/spot is not backed by a virtual machine scale set.")
+}
+
+func TestParseInstanceId_RejectsStandaloneVirtualMachines(t *testing.T) {
+	instance, err := parseInstanceId(nodeScaleSet + "/virtualMachines/3")
+	require.NoError(t, err)
+	assert.Equal(t, "aks-spot-12345-vmss", instance.Parent.Name)
+	assert.Equal(t, "3", instance.Name)
+
+	_, err = parseInstanceId("/subscriptions/sub-1/resourceGroups/MC_rg-1_cluster-1_westeurope/providers/Microsoft.Compute/virtualMachines/aks-spot-12345-vm0")
+	assert.Error(t, err)
+}
+
+func TestNodePoolRebootNodes_RejectsZoneWithoutInstances(t *testing.T) {
+	scaleSetVMs := &nodeScaleSetVMsApiMock{zones: map[string]string{"0": "1", "1": "1", "2": "1", "3": "1"}}
+	attack := newRebootNodesAttack(scaleSetVMs)
+	state := attack.NewEmptyState()
+
+	_, err := attack.Prepare(context.Background(), &state, rebootPrepareRequest(map[string]any{"mode": nodeRebootModeRestart, "percentage": 50, "zone": "3"}))
+
+	assert.ErrorContains(t, err, "has no scale set instances in zone '3'")
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type NodePoolScaleState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	ResourceGroupName string
+	ClusterName       string
+	NodePoolName      string
+	OriginalCount     int32
+	TargetCount       int32
+	// OriginalAutoScaling, OriginalMinCount and OriginalMaxCount are the cluster autoscaler settings restored by Stop.
+	OriginalAutoScaling bool
+	OriginalMinCount    *int32
+	OriginalMaxCount    *int32
+	DryRun              bool
+	// ScaleResumeToken resumes the scaling, which has to finish before the original count is restored.
+	ScaleResumeToken string
+}
+
+// AgentPoolScaleApi captures the subset of armcontainerservice.AgentPoolsClient used to scale node pools.
+type AgentPoolScaleApi interface {
+	Get(ctx context.Context, resourceGroupName string, resourceName string, agentPoolName string, options *armcontainerservice.AgentPoolsClientGetOptions) (armcontainerservice.AgentPoolsClientGetResponse, error)
+	BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, resourceName string, agentPoolName string, parameters armcontainerservice.AgentPool, options *armcontainerservice.AgentPoolsClientBeginCreateOrUpdateOptions) (*runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], error)
+}
+
+type nodePoolScaleAttack struct {
+	agentPoolsProvider func(subscriptionId string) (AgentPoolScaleApi, error)
+}
+
+var _ action_kit_sdk.Action[NodePoolScaleState] = (*nodePoolScaleAttack)(nil)
+var _ action_kit_sdk.ActionWithStop[NodePoolScaleState] = (*nodePoolScaleAttack)(nil)
+var _ common.ActionWithRequiredPermissions[NodePoolScaleState] = (*nodePoolScaleAttack)(nil)
+
+func NewNodePoolScaleAction() action_kit_sdk.ActionWithStop[NodePoolScaleState] {
+	return &nodePoolScaleAttack{
+		agentPoolsProvider: func(subscriptionId string) (AgentPoolScaleApi, error) {
+			return newAgentPoolsClient(subscriptionId)
+		},
+	}
+}
+
+func (a *nodePoolScaleAttack) NewEmptyState() NodePoolScaleState {
+	return NodePoolScaleState{}
+}
+
+func (a *nodePoolScaleAttack) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    NodePoolScaleActionId,
+		Label: "Scale Node Pool",
+		Description: "Scales an AKS node pool to a given number of nodes or reduces it by a percentage for a given duration and restores the original node count afterwards. " +
+			"The cluster autoscaler of the node pool is disabled while the attack runs, so it doesn't undo the change.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(nodePoolIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDNodePool,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "by cluster and node pool name",
+					Description: new("Find AKS node pool by cluster name and node pool name"),
+					Query:       "azure.aks.cluster.name=\"\" and azure.aks.nodepool.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("AKS"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the node pool keeps the changed node count."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("300s"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  new("Whether to scale to an absolute number of nodes or to reduce the node count by a percentage."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(common.ScaleModePercent),
+				Order:        new(2),
+				Required:     new(true),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "Reduce by percentage", Value: common.ScaleModePercent},
+					action_kit_api.ExplicitParameterOption{Label: "Absolute node count", Value: common.ScaleModeAbsolute},
+				}),
+			},
+			{
+				Name:         "count",
+				Label:        "Count",
+				Description:  new("The number of nodes to scale to, or the percentage of nodes to remove."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("50"),
+				MinValue:     new(0),
+				Order:        new(3),
+				Required:     new(true),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *nodePoolScaleAttack) Prepare(ctx context.Context, state *NodePoolScaleState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.SubscriptionId = mustHave(request.Target.Attributes, "azure.subscription.id")
+	state.ResourceGroupName = mustHave(request.Target.Attributes, "azure.resource-group.name")
+	state.ClusterName = mustHave(request.Target.Attributes, "azure.aks.cluster.name")
+	state.NodePoolName = mustHave(request.Target.Attributes, "azure.aks.nodepool.name")
+	if state.SubscriptionId == "" || state.ResourceGroupName == "" || state.ClusterName == "" || state.NodePoolName == "" {
+		return nil, extension_kit.ToError("Target is missing one of: azure.subscription.id, azure.resource-group.name, azure.aks.cluster.name, azure.aks.nodepool.name", nil)
+	}
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := a.agentPoolsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS agent pools client for subscription %s", state.SubscriptionId), err)
+	}
+	pool, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+	properties := pool.Properties
+	if properties == nil || properties.Count == nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to determine the node count of AKS node pool %s/%s", state.ClusterName, state.NodePoolName), nil)
+	}
+	state.OriginalCount = *properties.Count
+	state.OriginalAutoScaling = properties.EnableAutoScaling != nil && *properties.EnableAutoScaling
+	state.OriginalMinCount = properties.MinCount
+	state.OriginalMaxCount = properties.MaxCount
+
+	targetCount, err := common.TargetCount(state.OriginalCount, extutil.ToString(request.Config["mode"]), int32(extutil.ToInt(request.Config["count"])), "node count")
+	if err != nil {
+		return nil, err
+	}
+	if targetCount == state.OriginalCount {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s already has %d nodes.", state.ClusterName, state.NodePoolName, targetCount), nil)
+	}
+	if targetCount == 0 && properties.Mode != nil && *properties.Mode == armcontainerservice.AgentPoolModeSystem {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s is a system node pool and can't be scaled to 0 nodes.", state.ClusterName, state.NodePoolName), nil)
+	}
+	state.TargetCount = targetCount
+
+	messages := []action_kit_api.Message{{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("AKS node pool %s/%s will be scaled from %d to %d nodes.", state.ClusterName, state.NodePoolName, state.OriginalCount, state.TargetCount),
+	}}
+	if state.OriginalAutoScaling {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("The cluster autoscaler of AKS node pool %s/%s will be disabled until the attack ends.", state.ClusterName, state.NodePoolName),
+		})
+	}
+	return &action_kit_api.PrepareResult{Messages: &messages}, nil
+}
+
+func (a *nodePoolScaleAttack) RequiredPermissions(state *NodePoolScaleState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s/agentPools/%s", state.SubscriptionId, state.ResourceGroupName, state.ClusterName, state.NodePoolName),
+		Operations: []string{
+			"Microsoft.ContainerService/managedClusters/agentPools/read",
+			"Microsoft.ContainerService/managedClusters/agentPools/write",
+		},
+	}}
+}
+
+func (a *nodePoolScaleAttack) Start(ctx context.Context, state *NodePoolScaleState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		mutations := []string{fmt.Sprintf("scale AKS node pool %s/%s from %d to %d nodes", state.ClusterName, state.NodePoolName, state.OriginalCount, state.TargetCount)}
+		if state.OriginalAutoScaling {
+			mutations = append([]string{fmt.Sprintf("disable the cluster autoscaler of AKS node pool %s/%s", state.ClusterName, state.NodePoolName)}, mutations...)
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	client, err := a.agentPoolsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS agent pools client for subscription %s", state.SubscriptionId), err)
+	}
+	operation, err := beginNodePoolScale(ctx, client, state, state.TargetCount, false, nil, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to scale AKS node pool %s/%s to %d nodes", state.ClusterName, state.NodePoolName, state.TargetCount), err)
+	}
+	if operation != nil {
+		if state.ScaleResumeToken, err = operation.ResumeToken(); err != nil {
+			log.Warn().Err(err).Msgf("Failed to get the resume token of the scaling of AKS node pool %s/%s.", state.ClusterName, state.NodePoolName)
+		}
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Started to scale AKS node pool %s/%s from %d to %d nodes.", state.ClusterName, state.NodePoolName, state.OriginalCount, state.TargetCount),
+		}}),
+	}, nil
+}
+
+func (a *nodePoolScaleAttack) Stop(ctx context.Context, state *NodePoolScaleState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("scale AKS node pool %s/%s back to %d nodes and restore its autoscaler settings", state.ClusterName, state.NodePoolName, state.OriginalCount)),
+		}, nil
+	}
+
+	client, err := a.agentPoolsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS agent pools client for subscription %s", state.SubscriptionId), err)
+	}
+
+	ctx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+
+	if state.ScaleResumeToken != "" {
+		operation, err := common.NewOperation(client.BeginCreateOrUpdate(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, armcontainerservice.AgentPool{}, &armcontainerservice.AgentPoolsClientBeginCreateOrUpdateOptions{ResumeToken: state.ScaleResumeToken}))
+		if err == nil && operation != nil {
+			err = common.WaitForPrevious(ctx, operation)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("The scaling of AKS node pool %s/%s to %d nodes did not complete, restoring the original count anyway.", state.ClusterName, state.NodePoolName, state.TargetCount)
+		}
+	}
+
+	operation, err := beginNodePoolScale(ctx, client, state, state.OriginalCount, state.OriginalAutoScaling, state.OriginalMinCount, state.OriginalMaxCount)
+	if err == nil && operation != nil {
+		err = operation.Wait(ctx)
+	}
+	if err != nil && common.IsStopTimeout(ctx) {
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{common.StillInProgress(fmt.Sprintf("scaling of AKS node pool %s/%s back to %d nodes", state.ClusterName, state.NodePoolName, state.OriginalCount))}),
+		}, nil
+	}
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to scale AKS node pool %s/%s back to %d nodes", state.ClusterName, state.NodePoolName, state.OriginalCount), err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("AKS node pool %s/%s has its original %d nodes and autoscaler settings again.", state.ClusterName, state.NodePoolName, state.OriginalCount),
+		}}),
+	}, nil
+}
+
+// beginNodePoolScale writes the node count and autoscaler settings of the node pool. The agent pools API only supports
+// replacing the whole pool, so the current pool is read first to keep all other settings.
+func beginNodePoolScale(ctx context.Context, client AgentPoolScaleApi, state *NodePoolScaleState, count int32, autoScaling bool, minCount *int32, maxCount *int32) (common.Operation, error) {
+	pool, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Properties == nil {
+		pool.Properties = &armcontainerservice.ManagedClusterAgentPoolProfileProperties{}
+	}
+	pool.Properties.Count = new(count)
+	pool.Properties.EnableAutoScaling = new(autoScaling)
+	pool.Properties.MinCount = minCount
+	pool.Properties.MaxCount = maxCount
+	return common.NewOperation(client.BeginCreateOrUpdate(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, pool.AgentPool, nil))
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type agentPoolScaleApiMock struct {
+	mock.Mock
+	pool armcontainerservice.AgentPool
+}
+
+func (m *agentPoolScaleApiMock) Get(context.Context, string, string, string, *armcontainerservice.AgentPoolsClientGetOptions) (armcontainerservice.AgentPoolsClientGetResponse, error) {
+	return armcontainerservice.AgentPoolsClientGetResponse{AgentPool: m.pool}, nil
+}
+
+func (m *agentPoolScaleApiMock) BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, resourceName string, agentPoolName string, parameters armcontainerservice.AgentPool, _ *armcontainerservice.AgentPoolsClientBeginCreateOrUpdateOptions) (*runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], error) {
+	properties := parameters.Properties
+	args := m.Called(ctx, resourceGroupName, resourceName, agentPoolName, *properties.Count, *properties.EnableAutoScaling, properties.MinCount, properties.MaxCount)
+	return nil, args.Error(1)
+}
+
+func scalePrepareRequest(mode string, count int) action_kit_api.PrepareActionRequestBody {
+	return action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"mode": mode, "count": count},
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure.subscription.id":     {"sub-1"},
+			"azure.resource-group.name": {"rg-1"},
+			"azure.aks.cluster.name":    {"cluster-1"},
+			"azure.aks.nodepool.name":   {"pool-1"},
+		}},
+	}
+}
+
+func TestNodePoolScale_DisablesAndRestoresAutoscaler(t *testing.T) {
+	agentPools := &agentPoolScaleApiMock{pool: armcontainerservice.AgentPool{Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{
+		Count:             new(int32(4)),
+		EnableAutoScaling: new(true),
+		MinCount:          new(int32(2)),
+		MaxCount:          new(int32(6)),
+	}}}
+	agentPools.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", "pool-1", int32(2), false, (*int32)(nil), (*int32)(nil)).Return(nil, nil).Once()
+	agentPools.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", "pool-1", int32(4), true, new(int32(2)), new(int32(6))).Return(nil, nil).Once()
+	attack := &nodePoolScaleAttack{agentPoolsProvider: func(string) (AgentPoolScaleApi, error) { return agentPools, nil }}
+	state := attack.NewEmptyState()
+
+	result, err := attack.Prepare(context.Background(), &state, scalePrepareRequest(common.ScaleModePercent, 50))
+	require.NoError(t, err)
+	assert.Len(t, *result.Messages, 2)
+	_, err = attack.Start(context.Background(), &state)
+	require.NoError(t, err)
+	_, err = attack.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	agentPools.AssertExpectations(t)
+}
+
+func TestNodePoolScale_ReportsScalingBackInProgressAtTheStopTimeout(t *testing.T) {
+	// Given
+	previous := common.StopTimeout
+	common.StopTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { common.StopTimeout = previous })
+	agentPools := &agentPoolScaleApiMock{pool: armcontainerservice.AgentPool{Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{}}}
+	agentPools.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", "pool-1", int32(4), false, (*int32)(nil), (*int32)(nil)).
+		Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+		Return(nil, context.DeadlineExceeded)
+	attack := &nodePoolScaleAttack{agentPoolsProvider: func(string) (AgentPoolScaleApi, error) { return agentPools, nil }}
+	state := &NodePoolScaleState{SubscriptionId: "sub-1", ResourceGroupName: "rg-1", ClusterName: "cluster-1", NodePoolName: "pool-1", OriginalCount: 4, TargetCount: 2}
+
+	// When
+	result, err := attack.Stop(context.Background(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "The scaling of AKS node pool cluster-1/pool-1 back to 4 nodes is still in progress after 50ms.", (*result.Messages)[0].Message)
+}
+
+func TestNodePoolScale_RejectsEmptySystemPool(t *testing.T) {
+	agentPools := &agentPoolScaleApiMock{pool: armcontainerservice.AgentPool{Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{
+		Count: new(int32(3)),
+		Mode:  new(armcontainerservice.AgentPoolModeSystem),
+	}}}
+	attack := &nodePoolScaleAttack{agentPoolsProvider: func(string) (AgentPoolScaleApi, error) { return agentPools, nil }}
+	state := attack.NewEmptyState()
+
+	_, err := attack.Prepare(context.Background(), &state, scalePrepareRequest(common.ScaleModeAbsolute, 0))
+
+	assert.ErrorContains(t, err, "is a system node pool and can't be scaled to 0 nodes.")
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type NodePoolSpotEvictionState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	ResourceGroupName string
+	ClusterName       string
+	NodePoolName      string
+	Percentage        int
+	// InstanceIds are the ARM IDs of the scale set instances backing the selected machines.
+	InstanceIds []string
+	DryRun      bool
+}
+
+// ScaleSetVMsApi captures the subset of armcompute.VirtualMachineScaleSetVMsClient used here.
+type ScaleSetVMsApi interface {
+	SimulateEviction(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionOptions) (armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionResponse, error)
+}
+
+type nodePoolSpotEvictionAttack struct {
+	machinesProvider    func(subscriptionId string) (MachinesApi, error)
+	scaleSetVMsProvider func(subscriptionId string) (ScaleSetVMsApi, error)
+	rng                 func(n int) []int
+}
+
+var _ action_kit_sdk.Action[NodePoolSpotEvictionState] = (*nodePoolSpotEvictionAttack)(nil)
+var _ common.ActionWithRequiredPermissions[NodePoolSpotEvictionState] = (*nodePoolSpotEvictionAttack)(nil)
+
+func NewNodePoolSpotEvictionAction() action_kit_sdk.Action[NodePoolSpotEvictionState] {
+	return &nodePoolSpotEvictionAttack{
+		machinesProvider: func(subscriptionId string) (MachinesApi, error) {
+			return newMachinesClient(subscriptionId)
+		},
+		scaleSetVMsProvider: func(subscriptionId string) (ScaleSetVMsApi, error) {
+			return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
+		},
+		rng: rand.Perm,
+	}
+}
+
+func (a *nodePoolSpotEvictionAttack) NewEmptyState() NodePoolSpotEvictionState {
+	return NodePoolSpotEvictionState{}
+}
+
+func (a *nodePoolSpotEvictionAttack) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    NodePoolSpotEvictionActionId,
+		Label: "Simulate Spot Eviction",
+		Description: "Simulates the eviction of a percentage of the nodes of an AKS Spot node pool. " +
+			"Azure sends the eviction notice through the Scheduled Events API and evicts the nodes about 30 seconds later. " +
+			"Validates node draining, pod rescheduling and the replacement of evicted nodes.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(nodePoolIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDNodePool,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "by cluster and node pool name",
+					Description: new("Find AKS Spot node pool by cluster name and node pool name"),
+					Query:       "azure.aks.cluster.name=\"\" and azure.aks.nodepool.name=\"\" and azure.aks.nodepool.scale-set-priority=\"Spot\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("AKS"),
+		TimeControl: action_kit_api.TimeControlInstantaneous,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "percentage",
+				Label:        "Percentage of nodes to evict",
+				Description:  new("Percentage (1-100) of the node pool's nodes to evict. Defaults to 33%."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("33"),
+				Order:        new(1),
+				Required:     new(true),
+				MinValue:     new(1),
+				MaxValue:     new(100),
+			},
+			common.DryRunParameter(),
+		},
+	}
+}
+
+func (a *nodePoolSpotEvictionAttack) Prepare(ctx context.Context, state *NodePoolSpotEvictionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.SubscriptionId = mustHave(request.Target.Attributes, "azure.subscription.id")
+	state.ResourceGroupName = mustHave(request.Target.Attributes, "azure.resource-group.name")
+	state.ClusterName = mustHave(request.Target.Attributes, "azure.aks.cluster.name")
+	state.NodePoolName = mustHave(request.Target.Attributes, "azure.aks.nodepool.name")
+	if state.SubscriptionId == "" || state.ResourceGroupName == "" || state.ClusterName == "" || state.NodePoolName == "" {
+		return nil, extension_kit.ToError("Target is missing one of: azure.subscription.id, azure.resource-group.name, azure.aks.cluster.name, azure.aks.nodepool.name", nil)
+	}
+	if priority := mustHave(request.Target.Attributes, "azure.aks.nodepool.scale-set-priority"); priority != "Spot" {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s is not a Spot node pool (scale-set priority %q). Only Spot nodes can be evicted.", state.ClusterName, state.NodePoolName, priority), nil)
+	}
+
+	pct := extutil.ToInt(request.Config["percentage"])
+	if pct < 1 || pct > 100 {
+		return nil, extension_kit.ToError("percentage must be between 1 and 100.", nil)
+	}
+	state.Percentage = pct
+
+	machinesClient, err := a.machinesProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS machines client for subscription %s", state.SubscriptionId), err)
+	}
+	machines, err := listNodePoolMachines(ctx, machinesClient, state.ResourceGroupName, state.ClusterName, state.NodePoolName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to list machines for AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+	evictable := make([]nodePoolMachine, 0, len(machines))
+	for _, m := range machines {
+		if m.ResourceID != "" {
+			evictable = append(evictable, m)
+		}
+	}
+	if len(evictable) == 0 {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s has no scale set instances to evict", state.ClusterName, state.NodePoolName), nil)
+	}
+
+	sampleSize := sampleSizeOf(len(evictable), pct)
+	perm := a.rng(len(evictable))
+	names := make([]string, 0, sampleSize)
+	state.InstanceIds = make([]string, 0, sampleSize)
+	for i := 0; i < sampleSize; i++ {
+		names = append(names, evictable[perm[i]].Name)
+		state.InstanceIds = append(state.InstanceIds, evictable[perm[i]].ResourceID)
+	}
+	sort.Strings(names)
+	sort.Strings(state.InstanceIds)
+	state.DryRun = common.IsDryRun(request)
+
+	return &action_kit_api.PrepareResult{
+		Messages: new([]action_kit_api.Message{{
+			Level: extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Selected %d of %d machine(s) (%d%%) in AKS node pool %s/%s for eviction: %v",
+				sampleSize, len(evictable), pct, state.ClusterName, state.NodePoolName, names),
+		}}),
+	}, nil
+}
+
+func (a *nodePoolSpotEvictionAttack) RequiredPermissions(state *NodePoolSpotEvictionState) []common.PermissionRequirement {
+	requirements := make([]common.PermissionRequirement, 0, len(state.InstanceIds))
+	for _, id := range state.InstanceIds {
+		requirements = append(requirements, common.PermissionRequirement{
+			Scope:      id,
+			Operations: []string{"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/simulateEviction/action"},
+		})
+	}
+	return requirements
+}
+
+func (a *nodePoolSpotEvictionAttack) Start(ctx context.Context, state *NodePoolSpotEvictionState) (*action_kit_api.StartResult, error) {
+	if len(state.InstanceIds) == 0 {
+		return nil, extension_kit.ToError("No machines selected for eviction.", nil)
+	}
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.InstanceIds))
+		for _, id := range state.InstanceIds {
+			mutations = append(mutations, fmt.Sprintf("simulate the eviction of %s in AKS node pool %s/%s", id, state.ClusterName, state.NodePoolName))
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+	client, err := a.scaleSetVMsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize scale set VMs client for subscription %s", state.SubscriptionId), err)
+	}
+	for _, id := range state.InstanceIds {
+		instance, err := parseInstanceId(id)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Unexpected scale set instance ID %s", id), err)
+		}
+		if _, err := client.SimulateEviction(ctx, instance.ResourceGroupName, instance.Parent.Name, instance.Name, nil); err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to simulate the eviction of %s in AKS node pool %s/%s", id, state.ClusterName, state.NodePoolName), err)
+		}
+	}
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level: extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Simulated the eviction of %d machine(s) in AKS node pool %s/%s. Azure evicts them in about 30 seconds.",
+				len(state.InstanceIds), state.ClusterName, state.NodePoolName),
+		}}),
+	}, nil
+}
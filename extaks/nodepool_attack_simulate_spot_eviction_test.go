@@ -0,0 +1,84 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const nodeScaleSet = "/subscriptions/sub-1/resourceGroups/MC_rg-1_cluster-1_westeurope/providers/Microsoft.Compute/virtualMachineScaleSets/aks-spot-12345-vmss"
+
+type scaleSetVMsApiMock struct {
+	mock.Mock
+}
+
+func (m *scaleSetVMsApiMock) SimulateEviction(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionOptions) (armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionResponse{}, args.Error(1)
+}
+
+func spotMachinesPage(instanceIds ...string) *armcontainerservice.MachinesClientListResponse {
+	machines := make([]*armcontainerservice.Machine, 0, len(instanceIds))
+	for _, id := range instanceIds {
+		machines = append(machines, &armcontainerservice.Machine{
+			Name:       new("aks-spot-12345-vmss00000" + id),
+			Properties: &armcontainerservice.MachineProperties{ResourceID: new(nodeScaleSet + "/virtualMachines/" + id)},
+		})
+	}
+	return &armcontainerservice.MachinesClientListResponse{MachineListResult: armcontainerservice.MachineListResult{Value: machines}}
+}
+
+func spotPrepareRequest(priority string, percentage int) action_kit_api.PrepareActionRequestBody {
+	return action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"percentage": percentage},
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure.subscription.id":                 {"sub-1"},
+			"azure.resource-group.name":             {"rg-1"},
+			"azure.aks.cluster.name":                {"cluster-1"},
+			"azure.aks.nodepool.name":               {"spot"},
+			"azure.aks.nodepool.scale-set-priority": {priority},
+		}},
+	}
+}
+
+func TestNodePoolSpotEviction_EvictsSelectedInstances(t *testing.T) {
+	machines := &machinesApiMock{}
+	machines.On("NewListPager", "rg-1", "cluster-1", "spot", mock.Anything).Return(spotMachinesPage("0", "1", "2"), nil)
+	scaleSetVMs := &scaleSetVMsApiMock{}
+	scaleSetVMs.On("SimulateEviction", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "0").Return(nil, nil)
+	scaleSetVMs.On("SimulateEviction", mock.Anything, "MC_rg-1_cluster-1_westeurope", "aks-spot-12345-vmss", "1").Return(nil, nil)
+	attack := &nodePoolSpotEvictionAttack{
+		machinesProvider:    func(string) (MachinesApi, error) { return machines, nil },
+		scaleSetVMsProvider: func(string) (ScaleSetVMsApi, error) { return scaleSetVMs, nil },
+		rng:                 identityPerm,
+	}
+	state := attack.NewEmptyState()
+
+	_, err := attack.Prepare(context.Background(), &state, spotPrepareRequest("Spot", 50))
+	require.NoError(t, err)
+	assert.Equal(t, []string{nodeScaleSet + "/virtualMachines/0", nodeScaleSet + "/virtualMachines/1"}, state.InstanceIds)
+
+	_, err = attack.Start(context.Background(), &state)
+	require.NoError(t, err)
+	scaleSetVMs.AssertExpectations(t)
+}
+
+func TestNodePoolSpotEviction_RejectsRegularNodePool(t *testing.T) {
+	attack := &nodePoolSpotEvictionAttack{rng: identityPerm}
+	state := attack.NewEmptyState()
+
+	_, err := attack.Prepare(context.Background(), &state, spotPrepareRequest("Regular", 50))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a Spot node pool")
+}
@@ -7,34 +7,49 @@ package extaks
 import (
 	"context"
 	"fmt"
-	"math"
 	"math/rand"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
 	extension_kit "github.com/steadybit/extension-kit"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
 )
 
 type NodePoolTerminateInstancesState struct {
+	common.ExecutionContextState
+
 	SubscriptionId    string
 	ResourceGroupName string
 	ClusterName       string
 	NodePoolName      string
 	Percentage        int
-	MachineNames      []string
+	// Zone restricts the selection to the machines of one availability zone. AllInZone selects all of them.
+	Zone         string
+	AllInZone    bool
+	MachineNames []string
+	// MachineZones maps the selected machine names to their zone, for the report of the affected zones.
+	MachineZones map[string]string
+	// ScaleSetIds are the scale sets whose instances are read to select the machines of the zone.
+	ScaleSetIds []string
+	DryRun      bool
 }
 
 type nodePoolTerminateInstancesAttack struct {
-	machinesProvider   func(subscriptionId string) (MachinesApi, error)
-	agentPoolsProvider func(subscriptionId string) (AgentPoolsApi, error)
-	rng                func(n int) []int
+	machinesProvider    func(subscriptionId string) (MachinesApi, error)
+	agentPoolsProvider  func(subscriptionId string) (AgentPoolsApi, error)
+	scaleSetVMsProvider func(subscriptionId string) (ScaleSetVMReaderApi, error)
+	rng                 func(n int) []int
 }
 
 var _ action_kit_sdk.Action[NodePoolTerminateInstancesState] = (*nodePoolTerminateInstancesAttack)(nil)
+var _ common.ActionWithRequiredPermissions[NodePoolTerminateInstancesState] = (*nodePoolTerminateInstancesAttack)(nil)
 
 func NewNodePoolTerminateInstancesAction() action_kit_sdk.Action[NodePoolTerminateInstancesState] {
 	return &nodePoolTerminateInstancesAttack{
@@ -44,6 +59,9 @@ func NewNodePoolTerminateInstancesAction() action_kit_sdk.Action[NodePoolTermina
 		agentPoolsProvider: func(subscriptionId string) (AgentPoolsApi, error) {
 			return newAgentPoolsClient(subscriptionId)
 		},
+		scaleSetVMsProvider: func(subscriptionId string) (ScaleSetVMReaderApi, error) {
+			return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
+		},
 		rng: rand.Perm,
 	}
 }
@@ -87,6 +105,24 @@ func (a *nodePoolTerminateInstancesAttack) Describe() action_kit_api.ActionDescr
 				MinValue:     new(1),
 				MaxValue:     new(100),
 			},
+			{
+				Name:        "zone",
+				Label:       "Zone",
+				Description: new("Only pick nodes of this availability zone of the node pool, e.g. '1', to simulate a zonal failure. Leave empty to pick from all nodes."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(2),
+				Required:    new(false),
+			},
+			{
+				Name:         "allInZone",
+				Label:        "All nodes in zone",
+				Description:  new("Terminate all nodes of the zone instead of a percentage of them."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("false"),
+				Order:        new(3),
+				Required:     new(false),
+			},
+			common.DryRunParameter(),
 		},
 	}
 }
@@ -100,9 +136,25 @@ func (a *nodePoolTerminateInstancesAttack) Prepare(ctx context.Context, state *N
 		return nil, extension_kit.ToError("Target is missing one of: azure.subscription.id, azure.resource-group.name, azure.aks.cluster.name, azure.aks.nodepool.name", nil)
 	}
 
-	pct := extutil.ToInt(request.Config["percentage"])
-	if pct < 1 || pct > 100 {
-		return nil, extension_kit.ToError("percentage must be between 1 and 100.", nil)
+	state.Zone = strings.TrimSpace(extutil.ToString(request.Config["zone"]))
+	state.AllInZone = extutil.ToBool(request.Config["allInZone"])
+	zones := request.Target.Attributes["azure.aks.nodepool.availability-zones"]
+	if state.Zone != "" && !slices.Contains(zones, state.Zone) {
+		if len(zones) == 0 {
+			return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s doesn't use availability zones.", state.ClusterName, state.NodePoolName), nil)
+		}
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s has no zone '%s'. Its zones are %s.", state.ClusterName, state.NodePoolName, state.Zone, strings.Join(zones, ", ")), nil)
+	}
+	if state.AllInZone && state.Zone == "" {
+		return nil, extension_kit.ToError("A zone is required to terminate all nodes in a zone.", nil)
+	}
+
+	pct := 100
+	if !state.AllInZone {
+		pct = extutil.ToInt(request.Config["percentage"])
+		if pct < 1 || pct > 100 {
+			return nil, extension_kit.ToError("percentage must be between 1 and 100.", nil)
+		}
 	}
 	state.Percentage = pct
 
@@ -111,61 +163,124 @@ func (a *nodePoolTerminateInstancesAttack) Prepare(ctx context.Context, state *N
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS machines client for subscription %s", state.SubscriptionId), err)
 	}
 
-	pager := machinesClient.NewListPager(state.ResourceGroupName, state.ClusterName, state.NodePoolName, nil)
-	allNames := make([]string, 0)
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, extension_kit.ToError(fmt.Sprintf("Failed to list machines for AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	machines, err := listNodePoolMachines(ctx, machinesClient, state.ResourceGroupName, state.ClusterName, state.NodePoolName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to list machines for AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+	if len(machines) == 0 {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s has no machines to terminate", state.ClusterName, state.NodePoolName), nil)
+	}
+	// The zones are resolved for zonal pools only, as they are read per machine from the backing scale set instance.
+	// Without a selected zone they only serve the zone report, which is left out if they cannot be read.
+	if len(zones) > 0 {
+		if state.Zone != "" {
+			state.ScaleSetIds = scaleSetIdsOf(machines)
 		}
-		for _, m := range page.Value {
-			if m == nil || m.Name == nil {
-				continue
+		if err := a.resolveMachineZones(ctx, state.SubscriptionId, machines); err != nil {
+			if state.Zone != "" {
+				return nil, extension_kit.ToError(fmt.Sprintf("Failed to determine the zones of the machines of AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+			}
+			log.Warn().Err(err).Msgf("Failed to determine the zones of the machines of AKS node pool %s/%s, reporting no zones.", state.ClusterName, state.NodePoolName)
+			for i := range machines {
+				machines[i].Zone = ""
 			}
-			allNames = append(allNames, *m.Name)
 		}
 	}
-	sort.Strings(allNames)
 
-	if len(allNames) == 0 {
-		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s has no machines to terminate", state.ClusterName, state.NodePoolName), nil)
+	candidates := make([]nodePoolMachine, 0, len(machines))
+	for _, m := range machines {
+		if state.Zone == "" || m.Zone == state.Zone {
+			candidates = append(candidates, m)
+		}
 	}
-
-	sampleSize := max(int(math.Ceil(float64(len(allNames))*float64(pct)/100.0)), 1)
-	if sampleSize > len(allNames) {
-		sampleSize = len(allNames)
+	if len(candidates) == 0 {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s has no machines in zone '%s'", state.ClusterName, state.NodePoolName, state.Zone), nil)
 	}
+
+	sampleSize := sampleSizeOf(len(candidates), pct)
 	// AKS rejects deleting every node in a system pool (control-plane needs at least one survivor).
 	// Catch it here with an actionable error instead of letting the deleteMachines call fail mid-experiment.
-	if sampleSize >= len(allNames) {
+	if sampleSize >= len(machines) {
 		mode := mustHave(request.Target.Attributes, "azure.aks.nodepool.mode")
 		if mode == "System" {
 			return nil, extension_kit.ToError(fmt.Sprintf(
 				"Cannot terminate all %d node(s) of system node pool %s/%s: AKS requires at least one surviving system node. Reduce the percentage, scale up the pool, or target a user node pool instead.",
-				len(allNames), state.ClusterName, state.NodePoolName), nil)
+				len(machines), state.ClusterName, state.NodePoolName), nil)
 		}
 	}
 
-	perm := a.rng(len(allNames))
+	perm := a.rng(len(candidates))
 	state.MachineNames = make([]string, 0, sampleSize)
+	state.MachineZones = make(map[string]string, sampleSize)
 	for i := 0; i < sampleSize; i++ {
-		state.MachineNames = append(state.MachineNames, allNames[perm[i]])
+		machine := candidates[perm[i]]
+		state.MachineNames = append(state.MachineNames, machine.Name)
+		if machine.Zone != "" {
+			state.MachineZones[machine.Name] = machine.Zone
+		}
 	}
 	sort.Strings(state.MachineNames)
+	state.DryRun = common.IsDryRun(request)
 
+	message := fmt.Sprintf("Selected %d of %d machine(s) (%d%%) in AKS node pool %s/%s for termination: %v",
+		sampleSize, len(candidates), pct, state.ClusterName, state.NodePoolName, state.MachineNames)
+	if state.Zone != "" {
+		message = fmt.Sprintf("Selected %d of %d machine(s) (%d%%) in zone %s of AKS node pool %s/%s for termination: %v",
+			sampleSize, len(candidates), pct, state.Zone, state.ClusterName, state.NodePoolName, state.MachineNames)
+	}
 	return &action_kit_api.PrepareResult{
 		Messages: new([]action_kit_api.Message{{
-			Level: extutil.Ptr(action_kit_api.Info),
-			Message: fmt.Sprintf("Selected %d of %d machine(s) (%d%%) in AKS node pool %s/%s for termination: %v",
-				sampleSize, len(allNames), pct, state.ClusterName, state.NodePoolName, state.MachineNames),
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: message,
 		}}),
 	}, nil
 }
 
+func (a *nodePoolTerminateInstancesAttack) resolveMachineZones(ctx context.Context, subscriptionId string, machines []nodePoolMachine) error {
+	client, err := a.scaleSetVMsProvider(subscriptionId)
+	if err != nil {
+		return fmt.Errorf("failed to initialize scale set VMs client for subscription %s: %w", subscriptionId, err)
+	}
+	return resolveMachineZones(ctx, client, machines)
+}
+
+// scaleSetIdsOf returns the distinct scale sets backing the machines.
+func scaleSetIdsOf(machines []nodePoolMachine) []string {
+	ids := make([]string, 0)
+	for _, m := range machines {
+		if instance, err := parseInstanceId(m.ResourceID); err == nil && !slices.Contains(ids, instance.Parent.String()) {
+			ids = append(ids, instance.Parent.String())
+		}
+	}
+	return ids
+}
+
+func (a *nodePoolTerminateInstancesAttack) RequiredPermissions(state *NodePoolTerminateInstancesState) []common.PermissionRequirement {
+	requirements := []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s/agentPools/%s",
+			state.SubscriptionId, state.ResourceGroupName, state.ClusterName, state.NodePoolName),
+		Operations: []string{"Microsoft.ContainerService/managedClusters/agentPools/deleteMachines/action"},
+	}}
+	for _, id := range state.ScaleSetIds {
+		requirements = append(requirements, common.PermissionRequirement{
+			Scope:      id,
+			Operations: []string{"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/read"},
+		})
+	}
+	return requirements
+}
+
 func (a *nodePoolTerminateInstancesAttack) Start(ctx context.Context, state *NodePoolTerminateInstancesState) (*action_kit_api.StartResult, error) {
 	if len(state.MachineNames) == 0 {
 		return nil, extension_kit.ToError("No machines selected for termination.", nil)
 	}
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.MachineNames))
+		for _, name := range state.MachineNames {
+			mutations = append(mutations, fmt.Sprintf("delete machine %s in AKS node pool %s/%s", name, state.ClusterName, state.NodePoolName))
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
 	client, err := a.agentPoolsProvider(state.SubscriptionId)
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS agent pools client for subscription %s", state.SubscriptionId), err)
@@ -179,13 +294,18 @@ func (a *nodePoolTerminateInstancesAttack) Start(ctx context.Context, state *Nod
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to delete machines in AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
 	}
-	return &action_kit_api.StartResult{
-		Messages: new([]action_kit_api.Message{{
-			Level: extutil.Ptr(action_kit_api.Info),
-			Message: fmt.Sprintf("Deletion requested for %d machine(s) in AKS node pool %s/%s: %v. AKS will replace them via the underlying VMSS.",
-				len(state.MachineNames), state.ClusterName, state.NodePoolName, state.MachineNames),
-		}}),
-	}, nil
+	messages := []action_kit_api.Message{{
+		Level: extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Deletion requested for %d machine(s) in AKS node pool %s/%s: %v. AKS will replace them via the underlying VMSS.",
+			len(state.MachineNames), state.ClusterName, state.NodePoolName, state.MachineNames),
+	}}
+	if report := zoneReport(state.MachineNames, state.MachineZones); report != "" {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Zones of the deleted machines: %s", report),
+		})
+	}
+	return &action_kit_api.StartResult{Messages: &messages}, nil
 }
 
 func mustHave(attrs map[string][]string, key string) string {
@@ -230,3 +230,92 @@ func TestStart_RejectsEmptyMachineList(t *testing.T) {
 	_, err := a.Start(context.Background(), &state)
 	require.Error(t, err)
 }
+
+func zonalPrepareReq(config map[string]any) action_kit_api.PrepareActionRequestBody {
+	request := prepareReq(50, "User")
+	request.Config = config
+	request.Target.Attributes["azure.aks.nodepool.availability-zones"] = []string{"1", "2"}
+	return request
+}
+
+func newZonalAttack(agentPools *agentPoolsApiMock) *nodePoolTerminateInstancesAttack {
+	machines := new(machinesApiMock)
+	machines.On("NewListPager", "rg-1", "cluster-1", "pool-1", mock.Anything).Return(spotMachinesPage("0", "1", "2", "3"), nil)
+	a := newAttack(machines, agentPools)
+	a.scaleSetVMsProvider = func(string) (ScaleSetVMReaderApi, error) {
+		return &nodeScaleSetVMsApiMock{zones: map[string]string{"0": "1", "1": "2", "2": "1", "3": "1"}}, nil
+	}
+	return a
+}
+
+func TestPrepare_Zone(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        map[string]any
+		expected      []string
+		expectedError string
+	}{
+		{name: "percentage of zone", config: map[string]any{"percentage": 50, "zone": "1"}, expected: []string{"aks-spot-12345-vmss000000", "aks-spot-12345-vmss000002"}},
+		{name: "all in zone", config: map[string]any{"percentage": 1, "zone": "1", "allInZone": true}, expected: []string{"aks-spot-12345-vmss000000", "aks-spot-12345-vmss000002", "aks-spot-12345-vmss000003"}},
+		{name: "unknown zone", config: map[string]any{"percentage": 50, "zone": "3"}, expectedError: "has no zone '3'. Its zones are 1, 2."},
+		{name: "all in zone without zone", config: map[string]any{"percentage": 50, "allInZone": true}, expectedError: "A zone is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newZonalAttack(new(agentPoolsApiMock))
+			state := NodePoolTerminateInstancesState{}
+
+			_, err := a.Prepare(context.Background(), &state, zonalPrepareReq(tt.config))
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, state.MachineNames)
+			permissions := a.RequiredPermissions(&state)
+			require.Len(t, permissions, 2)
+			assert.Equal(t, []string{"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/read"}, permissions[1].Operations)
+		})
+	}
+}
+
+func TestPrepare_WithoutZoneSelectsMachinesIfTheirZonesCannotBeRead(t *testing.T) {
+	a := newZonalAttack(new(agentPoolsApiMock))
+	a.scaleSetVMsProvider = func(string) (ScaleSetVMReaderApi, error) {
+		return nil, errors.New("forbidden")
+	}
+	state := NodePoolTerminateInstancesState{}
+
+	_, err := a.Prepare(context.Background(), &state, zonalPrepareReq(map[string]any{"percentage": 50}))
+
+	require.NoError(t, err)
+	assert.Len(t, state.MachineNames, 2)
+	assert.Empty(t, state.MachineZones)
+	assert.Len(t, a.RequiredPermissions(&state), 1)
+}
+
+func TestPrepare_RejectsZoneOfNonZonalPool(t *testing.T) {
+	a := newAttack(new(machinesApiMock), new(agentPoolsApiMock))
+	request := prepareReq(50, "User")
+	request.Config["zone"] = "1"
+
+	state := NodePoolTerminateInstancesState{}
+	_, err := a.Prepare(context.Background(), &state, request)
+	assert.ErrorContains(t, err, "doesn't use availability zones")
+}
+
+func TestStart_ReportsZonesOfDeletedMachines(t *testing.T) {
+	agentPools := new(agentPoolsApiMock)
+	agentPools.On("BeginDeleteMachines", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	a := newZonalAttack(agentPools)
+	state := NodePoolTerminateInstancesState{}
+	_, err := a.Prepare(context.Background(), &state, zonalPrepareReq(map[string]any{"percentage": 50}))
+	require.NoError(t, err)
+
+	result, err := a.Start(context.Background(), &state)
+	require.NoError(t, err)
+
+	require.Len(t, *result.Messages, 2)
+	assert.Equal(t, "Zones of the deleted machines: zone 1: aks-spot-12345-vmss000000; zone 2: aks-spot-12345-vmss000001", (*result.Messages)[1].Message)
+}
@@ -0,0 +1,440 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// maxSurgePattern matches the node counts and percentages AKS accepts as max surge, e.g. "1" or "33%".
+var maxSurgePattern = regexp.MustCompile(`^[0-9]+%?$`)
+
+type NodePoolNodeImageUpgradeState struct {
+	common.ExecutionContextState
+	common.OperationTracking
+
+	SubscriptionId    string
+	ResourceGroupName string
+	ClusterName       string
+	NodePoolName      string
+	// MaxSurge overrides the max surge of the node pool for the upgrade. OriginalMaxSurge is restored by Stop.
+	MaxSurge           string
+	OriginalMaxSurge   string
+	MaxSurgeOverridden bool
+	// InitialMachines are the names of the machines before the upgrade, to report the node churn.
+	InitialMachines []string
+	StartedAt       time.Time
+	DryRun          bool
+}
+
+// AgentPoolUpgradeApi captures the subset of armcontainerservice.AgentPoolsClient used to upgrade node images.
+type AgentPoolUpgradeApi interface {
+	AgentPoolScaleApi
+	BeginUpgradeNodeImageVersion(ctx context.Context, resourceGroupName string, resourceName string, agentPoolName string, options *armcontainerservice.AgentPoolsClientBeginUpgradeNodeImageVersionOptions) (*runtime.Poller[armcontainerservice.AgentPoolsClientUpgradeNodeImageVersionResponse], error)
+}
+
+type nodePoolNodeImageUpgradeAttack struct {
+	machinesProvider   func(subscriptionId string) (MachinesApi, error)
+	agentPoolsProvider func(subscriptionId string) (AgentPoolUpgradeApi, error)
+}
+
+var _ action_kit_sdk.Action[NodePoolNodeImageUpgradeState] = (*nodePoolNodeImageUpgradeAttack)(nil)
+var _ action_kit_sdk.ActionWithStatus[NodePoolNodeImageUpgradeState] = (*nodePoolNodeImageUpgradeAttack)(nil)
+var _ action_kit_sdk.ActionWithStop[NodePoolNodeImageUpgradeState] = (*nodePoolNodeImageUpgradeAttack)(nil)
+var _ common.ActionWithRequiredPermissions[NodePoolNodeImageUpgradeState] = (*nodePoolNodeImageUpgradeAttack)(nil)
+
+func NewNodePoolNodeImageUpgradeAction() action_kit_sdk.ActionWithStatus[NodePoolNodeImageUpgradeState] {
+	return &nodePoolNodeImageUpgradeAttack{
+		machinesProvider: func(subscriptionId string) (MachinesApi, error) {
+			return newMachinesClient(subscriptionId)
+		},
+		agentPoolsProvider: func(subscriptionId string) (AgentPoolUpgradeApi, error) {
+			return newAgentPoolsClient(subscriptionId)
+		},
+	}
+}
+
+func (a *nodePoolNodeImageUpgradeAttack) NewEmptyState() NodePoolNodeImageUpgradeState {
+	return NodePoolNodeImageUpgradeState{}
+}
+
+func (a *nodePoolNodeImageUpgradeAttack) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    NodePoolNodeImageUpgradeActionId,
+		Label: "Upgrade Node Image",
+		Description: "Upgrades the node image of an AKS node pool to the latest version and follows the rollout until the node pool succeeded, reporting the elapsed time and the replaced nodes. " +
+			"Validates that PodDisruptionBudgets and surge settings let node image upgrades finish without downtime.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(nodePoolIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDNodePool,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "by cluster and node pool name",
+					Description: new("Find AKS node pool by cluster name and node pool name"),
+					Query:       "azure.aks.cluster.name=\"\" and azure.aks.nodepool.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("AKS"),
+		TimeControl: action_kit_api.TimeControlInternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:        "maxSurge",
+				Label:       "Max surge",
+				Description: new("Overrides the max surge of the node pool for the upgrade, e.g. '1' or '33%'. Leave empty to keep the current setting. The original setting is restored afterwards."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(1),
+				Required:    new(false),
+			},
+			common.DryRunParameter(),
+			common.CompletionTimeoutParameter(),
+		},
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("10s"),
+		}),
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *nodePoolNodeImageUpgradeAttack) Prepare(ctx context.Context, state *NodePoolNodeImageUpgradeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.SubscriptionId = mustHave(request.Target.Attributes, "azure.subscription.id")
+	state.ResourceGroupName = mustHave(request.Target.Attributes, "azure.resource-group.name")
+	state.ClusterName = mustHave(request.Target.Attributes, "azure.aks.cluster.name")
+	state.NodePoolName = mustHave(request.Target.Attributes, "azure.aks.nodepool.name")
+	if state.SubscriptionId == "" || state.ResourceGroupName == "" || state.ClusterName == "" || state.NodePoolName == "" {
+		return nil, extension_kit.ToError("Target is missing one of: azure.subscription.id, azure.resource-group.name, azure.aks.cluster.name, azure.aks.nodepool.name", nil)
+	}
+	state.MaxSurge = strings.TrimSpace(extutil.ToString(request.Config["maxSurge"]))
+	if state.MaxSurge != "" && !maxSurgePattern.MatchString(state.MaxSurge) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Invalid max surge '%s'. Please use a node count like '1' or a percentage like '33%%'.", state.MaxSurge), nil)
+	}
+	state.PrepareTracking(request)
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := a.agentPoolsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS agent pools client for subscription %s", state.SubscriptionId), err)
+	}
+	pool, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+	if provisioningState := nodePoolProvisioningState(pool.AgentPool); provisioningState != "Succeeded" {
+		return nil, extension_kit.ToError(fmt.Sprintf("AKS node pool %s/%s is in provisioning state '%s'. Wait for the running operation to finish.", state.ClusterName, state.NodePoolName, provisioningState), nil)
+	}
+	if pool.Properties.UpgradeSettings != nil {
+		state.OriginalMaxSurge = common.GetStringValue(pool.Properties.UpgradeSettings.MaxSurge)
+	}
+
+	machinesClient, err := a.machinesProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS machines client for subscription %s", state.SubscriptionId), err)
+	}
+	state.InitialMachines, err = listNodePoolMachineNames(ctx, machinesClient, state)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to list machines for AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+
+	message := fmt.Sprintf("The node image of the %d machine(s) of AKS node pool %s/%s will be upgraded.", len(state.InitialMachines), state.ClusterName, state.NodePoolName)
+	if state.MaxSurge != "" && state.MaxSurge != state.OriginalMaxSurge {
+		message = fmt.Sprintf("The node image of the %d machine(s) of AKS node pool %s/%s will be upgraded with a max surge of %s instead of '%s'.",
+			len(state.InitialMachines), state.ClusterName, state.NodePoolName, state.MaxSurge, state.OriginalMaxSurge)
+	}
+	return &action_kit_api.PrepareResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: message,
+		}}),
+	}, nil
+}
+
+func (a *nodePoolNodeImageUpgradeAttack) RequiredPermissions(state *NodePoolNodeImageUpgradeState) []common.PermissionRequirement {
+	operations := []string{
+		"Microsoft.ContainerService/managedClusters/agentPools/read",
+		"Microsoft.ContainerService/managedClusters/agentPools/upgradeNodeImageVersion/action",
+	}
+	if state.MaxSurge != "" && state.MaxSurge != state.OriginalMaxSurge {
+		operations = append(operations, "Microsoft.ContainerService/managedClusters/agentPools/write")
+	}
+	return []common.PermissionRequirement{{
+		Scope:      fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s/agentPools/%s", state.SubscriptionId, state.ResourceGroupName, state.ClusterName, state.NodePoolName),
+		Operations: operations,
+	}}
+}
+
+func (a *nodePoolNodeImageUpgradeAttack) Start(ctx context.Context, state *NodePoolNodeImageUpgradeState) (*action_kit_api.StartResult, error) {
+	overrideMaxSurge := state.MaxSurge != "" && state.MaxSurge != state.OriginalMaxSurge
+	if state.DryRun {
+		mutations := []string{fmt.Sprintf("upgrade the node image of AKS node pool %s/%s", state.ClusterName, state.NodePoolName)}
+		if overrideMaxSurge {
+			mutations = append([]string{fmt.Sprintf("set the max surge of AKS node pool %s/%s to %s", state.ClusterName, state.NodePoolName, state.MaxSurge)}, mutations...)
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	client, err := a.agentPoolsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS agent pools client for subscription %s", state.SubscriptionId), err)
+	}
+
+	messages := make([]action_kit_api.Message, 0)
+	if overrideMaxSurge {
+		// The upgrade can only start once the pool accepted the new max surge.
+		operation, err := beginMaxSurgeUpdate(ctx, client, state, state.MaxSurge)
+		if err == nil && operation != nil {
+			err = operation.Wait(ctx)
+		}
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to set the max surge of AKS node pool %s/%s to %s", state.ClusterName, state.NodePoolName, state.MaxSurge), err)
+		}
+		state.MaxSurgeOverridden = true
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Set the max surge of AKS node pool %s/%s to %s.", state.ClusterName, state.NodePoolName, state.MaxSurge),
+		})
+	}
+
+	// If the upgrade can't be started, the max surge is put back right away, as Stop is not called for a failed Start.
+	rollback := func() {
+		if err := restoreMaxSurge(ctx, client, state); err != nil {
+			log.Error().Err(err).Msgf("Failed to restore the max surge '%s' of AKS node pool %s/%s.", state.OriginalMaxSurge, state.ClusterName, state.NodePoolName)
+		}
+	}
+	operation, err := common.NewOperation(client.BeginUpgradeNodeImageVersion(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, nil))
+	if err != nil {
+		rollback()
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to upgrade the node image of AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+	if err := state.Track(ctx, operation); err != nil {
+		rollback()
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to track the node image upgrade of AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+	state.StartedAt = time.Now()
+
+	messages = append(messages, action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Started to upgrade the node image of AKS node pool %s/%s.", state.ClusterName, state.NodePoolName),
+	})
+	return &action_kit_api.StartResult{Messages: &messages}, nil
+}
+
+func (a *nodePoolNodeImageUpgradeAttack) Status(ctx context.Context, state *NodePoolNodeImageUpgradeState) (*action_kit_api.StatusResult, error) {
+	if !state.IsTracking() {
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	}
+
+	client, err := a.agentPoolsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS agent pools client for subscription %s", state.SubscriptionId), err)
+	}
+	operation, err := common.NewOperation(client.BeginUpgradeNodeImageVersion(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName,
+		&armcontainerservice.AgentPoolsClientBeginUpgradeNodeImageVersionOptions{ResumeToken: state.ResumeToken}))
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to resume the node image upgrade of AKS node pool %s/%s", state.ClusterName, state.NodePoolName), err)
+	}
+
+	// The operation is polled before the node pool is read, so that the provisioning state is not older than the outcome.
+	result := state.PollTracked(ctx, operation, fmt.Sprintf("node image upgrade of AKS node pool %s/%s", state.ClusterName, state.NodePoolName), nil)
+
+	provisioningState := ""
+	if pool, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, nil); err == nil {
+		provisioningState = nodePoolProvisioningState(pool.AgentPool)
+	} else {
+		log.Warn().Err(err).Msgf("Failed to get AKS node pool %s/%s.", state.ClusterName, state.NodePoolName)
+	}
+	messages := []action_kit_api.Message{{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: a.progressMessage(ctx, state, provisioningState),
+	}}
+	if result.Messages != nil {
+		messages = append(messages, *result.Messages...)
+	}
+	result.Messages = &messages
+
+	if result.Completed && result.Error == nil && provisioningState != "" && provisioningState != "Succeeded" {
+		result.Error = &action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("The node image upgrade of AKS node pool %s/%s finished in provisioning state '%s'.", state.ClusterName, state.NodePoolName, provisioningState),
+			Status: new(action_kit_api.Failed),
+		}
+	}
+	return result, nil
+}
+
+// progressMessage reports the provisioning state, the elapsed time and how many nodes the upgrade added and removed.
+func (a *nodePoolNodeImageUpgradeAttack) progressMessage(ctx context.Context, state *NodePoolNodeImageUpgradeState, provisioningState string) string {
+	elapsed := time.Since(state.StartedAt).Round(time.Second)
+	progress := fmt.Sprintf("AKS node pool %s/%s is %s after %s", state.ClusterName, state.NodePoolName, provisioningState, elapsed)
+	if provisioningState == "" {
+		progress = fmt.Sprintf("The node image upgrade of AKS node pool %s/%s is running for %s", state.ClusterName, state.NodePoolName, elapsed)
+	}
+	machinesClient, err := a.machinesProvider(state.SubscriptionId)
+	if err != nil {
+		return progress + "."
+	}
+	current, err := listNodePoolMachineNames(ctx, machinesClient, state)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to list machines for AKS node pool %s/%s.", state.ClusterName, state.NodePoolName)
+		return progress + "."
+	}
+	added, removed := nodeChurn(state.InitialMachines, current)
+	return fmt.Sprintf("%s: %d node(s) added, %d node(s) removed.", progress, len(added), len(removed))
+}
+
+// nodeChurn returns the machines that are new in current and the initial machines that are gone.
+func nodeChurn(initial []string, current []string) ([]string, []string) {
+	added := make([]string, 0)
+	for _, name := range current {
+		if !slices.Contains(initial, name) {
+			added = append(added, name)
+		}
+	}
+	removed := make([]string, 0)
+	for _, name := range initial {
+		if !slices.Contains(current, name) {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
+
+func (a *nodePoolNodeImageUpgradeAttack) Stop(ctx context.Context, state *NodePoolNodeImageUpgradeState) (*action_kit_api.StopResult, error) {
+	if state.DryRun || !state.MaxSurgeOverridden {
+		return &action_kit_api.StopResult{}, nil
+	}
+
+	client, err := a.agentPoolsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize AKS agent pools client for subscription %s", state.SubscriptionId), err)
+	}
+
+	ctx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+
+	// The node pool rejects the restore while the upgrade is running, e.g. after Status stopped waiting for it at the
+	// completion timeout. The upgrade is waited for at most half of the stop timeout, the rest is left for the restore.
+	provisioningState := ""
+	err = common.WaitUntil(ctx, common.StopTimeout/2, func(ctx context.Context) (bool, error) {
+		pool, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, nil)
+		if err != nil {
+			return false, err
+		}
+		provisioningState = nodePoolProvisioningState(pool.AgentPool)
+		return isNodePoolOperationFinished(provisioningState), nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &action_kit_api.StopResult{
+			Error: &action_kit_api.ActionKitError{
+				Title: fmt.Sprintf("The max surge '%s' of AKS node pool %s/%s is not restored, the node pool is still %s after %s.",
+					state.OriginalMaxSurge, state.ClusterName, state.NodePoolName, provisioningState, common.StopTimeout/2),
+				Detail: new("AKS rejects changes to a node pool during its upgrade. Restore the max surge once the upgrade finished."),
+				Status: new(action_kit_api.Failed),
+			},
+		}, nil
+	}
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to read the provisioning state of AKS node pool %s/%s, restoring the max surge anyway.", state.ClusterName, state.NodePoolName)
+	}
+
+	err = restoreMaxSurge(ctx, client, state)
+	if err != nil && common.IsStopTimeout(ctx) {
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{common.StillInProgress(fmt.Sprintf("restore of the max surge '%s' of AKS node pool %s/%s", state.OriginalMaxSurge, state.ClusterName, state.NodePoolName))}),
+		}, nil
+	}
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to restore the max surge '%s' of AKS node pool %s/%s", state.OriginalMaxSurge, state.ClusterName, state.NodePoolName), err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Restored the max surge '%s' of AKS node pool %s/%s.", state.OriginalMaxSurge, state.ClusterName, state.NodePoolName),
+		}}),
+	}, nil
+}
+
+// restoreMaxSurge puts the max surge of the node pool back that Start overrode.
+func restoreMaxSurge(ctx context.Context, client AgentPoolScaleApi, state *NodePoolNodeImageUpgradeState) error {
+	if !state.MaxSurgeOverridden {
+		return nil
+	}
+	operation, err := beginMaxSurgeUpdate(ctx, client, state, state.OriginalMaxSurge)
+	if err == nil && operation != nil {
+		err = operation.Wait(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	state.MaxSurgeOverridden = false
+	return nil
+}
+
+// beginMaxSurgeUpdate writes the max surge of the node pool, keeping all other settings. An empty max surge removes the
+// setting, so that AKS applies its default again.
+func beginMaxSurgeUpdate(ctx context.Context, client AgentPoolScaleApi, state *NodePoolNodeImageUpgradeState, maxSurge string) (common.Operation, error) {
+	pool, err := client.Get(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Properties == nil {
+		pool.Properties = &armcontainerservice.ManagedClusterAgentPoolProfileProperties{}
+	}
+	if pool.Properties.UpgradeSettings == nil {
+		pool.Properties.UpgradeSettings = &armcontainerservice.AgentPoolUpgradeSettings{}
+	}
+	pool.Properties.UpgradeSettings.MaxSurge = nil
+	if maxSurge != "" {
+		pool.Properties.UpgradeSettings.MaxSurge = new(maxSurge)
+	}
+	return common.NewOperation(client.BeginCreateOrUpdate(ctx, state.ResourceGroupName, state.ClusterName, state.NodePoolName, pool.AgentPool, nil))
+}
+
+func nodePoolProvisioningState(pool armcontainerservice.AgentPool) string {
+	if pool.Properties == nil {
+		return ""
+	}
+	return common.GetStringValue(pool.Properties.ProvisioningState)
+}
+
+// isNodePoolOperationFinished reports whether no operation runs on the node pool anymore, whatever its outcome.
+func isNodePoolOperationFinished(provisioningState string) bool {
+	switch strings.ToLower(provisioningState) {
+	case "", "succeeded", "failed", "canceled":
+		return true
+	default:
+		return false
+	}
+}
+
+func listNodePoolMachineNames(ctx context.Context, client MachinesApi, state *NodePoolNodeImageUpgradeState) ([]string, error) {
+	machines, err := listNodePoolMachines(ctx, client, state.ResourceGroupName, state.ClusterName, state.NodePoolName)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(machines))
+	for _, m := range machines {
+		names = append(names, m.Name)
+	}
+	return names, nil
+}
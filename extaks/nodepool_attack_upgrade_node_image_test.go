@@ -0,0 +1,174 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type agentPoolUpgradeApiMock struct {
+	mock.Mock
+	pool armcontainerservice.AgentPool
+}
+
+func (m *agentPoolUpgradeApiMock) Get(context.Context, string, string, string, *armcontainerservice.AgentPoolsClientGetOptions) (armcontainerservice.AgentPoolsClientGetResponse, error) {
+	return armcontainerservice.AgentPoolsClientGetResponse{AgentPool: m.pool}, nil
+}
+
+func (m *agentPoolUpgradeApiMock) BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, resourceName string, agentPoolName string, parameters armcontainerservice.AgentPool, _ *armcontainerservice.AgentPoolsClientBeginCreateOrUpdateOptions) (*runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, resourceName, agentPoolName, *parameters.Properties.UpgradeSettings.MaxSurge)
+	return nil, args.Error(1)
+}
+
+func (m *agentPoolUpgradeApiMock) BeginUpgradeNodeImageVersion(ctx context.Context, resourceGroupName string, resourceName string, agentPoolName string, _ *armcontainerservice.AgentPoolsClientBeginUpgradeNodeImageVersionOptions) (*runtime.Poller[armcontainerservice.AgentPoolsClientUpgradeNodeImageVersionResponse], error) {
+	args := m.Called(ctx, resourceGroupName, resourceName, agentPoolName)
+	return nil, args.Error(1)
+}
+
+func newNodeImageUpgradeAttack(agentPools *agentPoolUpgradeApiMock) *nodePoolNodeImageUpgradeAttack {
+	machines := new(machinesApiMock)
+	machines.On("NewListPager", "rg-1", "cluster-1", "pool-1", mock.Anything).Return(machinesPageWithNames("a", "b"), nil)
+	return &nodePoolNodeImageUpgradeAttack{
+		machinesProvider:   func(string) (MachinesApi, error) { return machines, nil },
+		agentPoolsProvider: func(string) (AgentPoolUpgradeApi, error) { return agentPools, nil },
+	}
+}
+
+func upgradeablePool(provisioningState string) armcontainerservice.AgentPool {
+	return armcontainerservice.AgentPool{Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{
+		ProvisioningState: new(provisioningState),
+		UpgradeSettings:   &armcontainerservice.AgentPoolUpgradeSettings{MaxSurge: new("10%")},
+	}}
+}
+
+func TestNodePoolNodeImageUpgrade_OverridesAndRestoresMaxSurge(t *testing.T) {
+	agentPools := &agentPoolUpgradeApiMock{pool: upgradeablePool("Succeeded")}
+	agentPools.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", "pool-1", "50%").Return(nil, nil).Once()
+	agentPools.On("BeginUpgradeNodeImageVersion", mock.Anything, "rg-1", "cluster-1", "pool-1").Return(nil, nil)
+	agentPools.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", "pool-1", "10%").Return(nil, nil).Once()
+	attack := newNodeImageUpgradeAttack(agentPools)
+	state := attack.NewEmptyState()
+	request := prepareReq(0, "User")
+	request.Config = map[string]any{"maxSurge": "50%"}
+
+	_, err := attack.Prepare(context.Background(), &state, request)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, state.InitialMachines)
+	assert.Equal(t, "10%", state.OriginalMaxSurge)
+	_, err = attack.Start(context.Background(), &state)
+	require.NoError(t, err)
+	assert.True(t, state.MaxSurgeOverridden)
+	_, err = attack.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	assert.False(t, state.MaxSurgeOverridden)
+	agentPools.AssertExpectations(t)
+}
+
+func TestNodePoolNodeImageUpgrade_RestoresMaxSurgeIfUpgradeFailsToStart(t *testing.T) {
+	// Given
+	agentPools := &agentPoolUpgradeApiMock{pool: upgradeablePool("Succeeded")}
+	agentPools.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", "pool-1", "50%").Return(nil, nil).Once()
+	agentPools.On("BeginUpgradeNodeImageVersion", mock.Anything, "rg-1", "cluster-1", "pool-1").Return(nil, errors.New("conflict"))
+	agentPools.On("BeginCreateOrUpdate", mock.Anything, "rg-1", "cluster-1", "pool-1", "10%").Return(nil, nil).Once()
+	attack := newNodeImageUpgradeAttack(agentPools)
+	state := attack.NewEmptyState()
+	request := prepareReq(0, "User")
+	request.Config = map[string]any{"maxSurge": "50%"}
+	_, err := attack.Prepare(context.Background(), &state, request)
+	require.NoError(t, err)
+
+	// When
+	_, err = attack.Start(context.Background(), &state)
+
+	// Then
+	assert.ErrorContains(t, err, "Failed to upgrade the node image of AKS node pool cluster-1/pool-1")
+	assert.False(t, state.MaxSurgeOverridden)
+	agentPools.AssertExpectations(t)
+}
+
+func TestNodePoolNodeImageUpgrade_StopLeavesMaxSurgeWhileUpgradeIsRunning(t *testing.T) {
+	// Given
+	previous := common.StopTimeout
+	common.StopTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { common.StopTimeout = previous })
+	agentPools := &agentPoolUpgradeApiMock{pool: upgradeablePool("Upgrading")}
+	attack := newNodeImageUpgradeAttack(agentPools)
+	state := &NodePoolNodeImageUpgradeState{SubscriptionId: "sub-1", ResourceGroupName: "rg-1", ClusterName: "cluster-1", NodePoolName: "pool-1",
+		MaxSurge: "50%", OriginalMaxSurge: "10%", MaxSurgeOverridden: true}
+
+	// When
+	result, err := attack.Stop(context.Background(), state)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "The max surge '10%' of AKS node pool cluster-1/pool-1 is not restored, the node pool is still Upgrading after 50ms.", result.Error.Title)
+	assert.True(t, state.MaxSurgeOverridden)
+	agentPools.AssertNotCalled(t, "BeginCreateOrUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNodePoolNodeImageUpgrade_PrepareRejects(t *testing.T) {
+	tests := []struct {
+		name          string
+		pool          armcontainerservice.AgentPool
+		maxSurge      string
+		expectedError string
+	}{
+		{name: "running operation", pool: upgradeablePool("Upgrading"), expectedError: "is in provisioning state 'Upgrading'"},
+		{name: "invalid max surge", pool: upgradeablePool("Succeeded"), maxSurge: "many", expectedError: "Invalid max surge 'many'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := newNodeImageUpgradeAttack(&agentPoolUpgradeApiMock{pool: tt.pool})
+			state := attack.NewEmptyState()
+			request := prepareReq(0, "User")
+			request.Config = map[string]any{"maxSurge": tt.maxSurge}
+
+			_, err := attack.Prepare(context.Background(), &state, request)
+
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestNodePoolNodeImageUpgrade_StatusForwardsResumeError(t *testing.T) {
+	agentPools := &agentPoolUpgradeApiMock{pool: upgradeablePool("UpgradingNodeImageVersion")}
+	agentPools.On("BeginUpgradeNodeImageVersion", mock.Anything, "rg-1", "cluster-1", "pool-1").Return(nil, errors.New("invalid resume token"))
+	attack := newNodeImageUpgradeAttack(agentPools)
+	state := NodePoolNodeImageUpgradeState{SubscriptionId: "sub-1", ResourceGroupName: "rg-1", ClusterName: "cluster-1", NodePoolName: "pool-1"}
+	state.CompletionTimeout = time.Minute
+	state.ResumeToken = "token"
+
+	_, err := attack.Status(context.Background(), &state)
+
+	assert.ErrorContains(t, err, "Failed to resume the node image upgrade of AKS node pool cluster-1/pool-1")
+}
+
+func TestNodeChurn(t *testing.T) {
+	added, removed := nodeChurn([]string{"a", "b", "c"}, []string{"b", "c", "d", "e"})
+
+	assert.Equal(t, []string{"d", "e"}, added)
+	assert.Equal(t, []string{"a"}, removed)
+}
+
+func TestNodePoolNodeImageUpgradeDescribe(t *testing.T) {
+	desc := NewNodePoolNodeImageUpgradeAction().Describe()
+	assert.Equal(t, NodePoolNodeImageUpgradeActionId, desc.Id)
+	assert.Equal(t, action_kit_api.TimeControlInternal, desc.TimeControl)
+	assert.NotNil(t, desc.Status)
+	assert.NotNil(t, desc.Stop)
+}
@@ -27,10 +27,33 @@ import (
 type nodePoolDiscovery struct{}
 
 var (
-	_ discovery_kit_sdk.TargetDescriber    = (*nodePoolDiscovery)(nil)
-	_ discovery_kit_sdk.AttributeDescriber = (*nodePoolDiscovery)(nil)
+	_ discovery_kit_sdk.TargetDescriber          = (*nodePoolDiscovery)(nil)
+	_ discovery_kit_sdk.AttributeDescriber       = (*nodePoolDiscovery)(nil)
+	_ discovery_kit_sdk.EnrichmentRulesDescriber = (*nodePoolDiscovery)(nil)
 )
 
+// aksNodePoolEnrichmentTargetTypes are the extension-kubernetes target types that get enriched with the
+// attributes of the node pool they run in. AKS labels every node with its pool name (agentpool=<pool>), which
+// the K8s extension exposes as k8s.label.agentpool; together with k8s.cluster-name it identifies the pool.
+var aksNodePoolEnrichmentTargetTypes = []string{
+	"com.steadybit.extension_kubernetes.kubernetes-node",
+}
+
+// aksNodePoolPodEnrichmentTargetType is enriched by the name of the node a pod runs on, as pods don't carry
+// the labels of their node. Node pool targets list the names of their nodes for that.
+const aksNodePoolPodEnrichmentTargetType = "com.steadybit.extension_kubernetes.kubernetes-pod"
+
+// aksNodePoolEnrichmentAttributes are the node pool attributes copied onto matching Kubernetes targets, so that
+// experiments can e.g. select nodes of Spot or system pools.
+var aksNodePoolEnrichmentAttributes = []discovery_kit_api.Attribute{
+	{Matcher: discovery_kit_api.Equals, Name: "azure.aks.nodepool.name"},
+	{Matcher: discovery_kit_api.Equals, Name: "azure.aks.nodepool.mode"},
+	{Matcher: discovery_kit_api.Equals, Name: "azure.aks.nodepool.scale-set-priority"},
+	{Matcher: discovery_kit_api.Equals, Name: "azure.aks.nodepool.vm-size"},
+	{Matcher: discovery_kit_api.Equals, Name: "azure.aks.nodepool.availability-zones"},
+	{Matcher: discovery_kit_api.Equals, Name: "azure.aks.nodepool.orchestrator-version"},
+}
+
 func NewNodePoolDiscovery() discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(&nodePoolDiscovery{},
 		discovery_kit_sdk.WithRefreshTargetsNow(),
@@ -87,16 +110,75 @@ func (d *nodePoolDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDe
 		{Attribute: "azure.aks.nodepool.taints", Label: discovery_kit_api.PluralLabel{One: "AKS node pool taint", Other: "AKS node pool taints"}},
 		{Attribute: "azure.aks.nodepool.upgrade-settings.max-surge", Label: discovery_kit_api.PluralLabel{One: "AKS node pool upgrade max surge", Other: "AKS node pool upgrade max surges"}},
 		{Attribute: "azure.aks.nodepool.provisioning-state", Label: discovery_kit_api.PluralLabel{One: "AKS node pool provisioning state", Other: "AKS node pool provisioning states"}},
+		{Attribute: "azure.aks.nodepool.node.name", Label: discovery_kit_api.PluralLabel{One: "AKS node pool node name", Other: "AKS node pool node names"}},
 		{Attribute: "k8s.cluster-name", Label: discovery_kit_api.PluralLabel{One: "Kubernetes cluster name", Other: "Kubernetes cluster names"}},
 	}
 }
 
+func (d *nodePoolDiscovery) DescribeEnrichmentRules() []discovery_kit_api.TargetEnrichmentRule {
+	rules := make([]discovery_kit_api.TargetEnrichmentRule, 0, len(aksNodePoolEnrichmentTargetTypes))
+	for _, t := range aksNodePoolEnrichmentTargetTypes {
+		rules = append(rules, aksNodePoolToK8sEnrichmentRule(t))
+	}
+	return append(rules, aksNodePoolToPodEnrichmentRule())
+}
+
+func aksNodePoolToK8sEnrichmentRule(destTargetType string) discovery_kit_api.TargetEnrichmentRule {
+	return discovery_kit_api.TargetEnrichmentRule{
+		Id:      fmt.Sprintf("com.steadybit.extension_azure.aks.nodepool-to-%s", destTargetType),
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Src: discovery_kit_api.SourceOrDestination{
+			Type: TargetIDNodePool,
+			Selector: map[string]string{
+				"k8s.cluster-name":        "${dest.k8s.cluster-name}",
+				"azure.aks.nodepool.name": "${dest.k8s.label.agentpool}",
+			},
+		},
+		Dest: discovery_kit_api.SourceOrDestination{
+			Type: destTargetType,
+			Selector: map[string]string{
+				"k8s.cluster-name":    "${src.k8s.cluster-name}",
+				"k8s.label.agentpool": "${src.azure.aks.nodepool.name}",
+			},
+		},
+		Attributes: aksNodePoolEnrichmentAttributes,
+	}
+}
+
+func aksNodePoolToPodEnrichmentRule() discovery_kit_api.TargetEnrichmentRule {
+	return discovery_kit_api.TargetEnrichmentRule{
+		Id:      fmt.Sprintf("com.steadybit.extension_azure.aks.nodepool-to-%s", aksNodePoolPodEnrichmentTargetType),
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Src: discovery_kit_api.SourceOrDestination{
+			Type: TargetIDNodePool,
+			Selector: map[string]string{
+				"k8s.cluster-name":             "${dest.k8s.cluster-name}",
+				"azure.aks.nodepool.node.name": "${dest.host.hostname}",
+			},
+		},
+		Dest: discovery_kit_api.SourceOrDestination{
+			Type: aksNodePoolPodEnrichmentTargetType,
+			Selector: map[string]string{
+				"k8s.cluster-name": "${src.k8s.cluster-name}",
+				"host.hostname":    "${src.azure.aks.nodepool.node.name}",
+			},
+		},
+		Attributes: aksNodePoolEnrichmentAttributes,
+	}
+}
+
 func (d *nodePoolDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDNodePool, d.discoverTargets)
+}
+
+func (d *nodePoolDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	rgClient, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Resource Graph client: %w", err)
 	}
-	return getAllAksNodePools(ctx, rgClient, sdkAksAgentPoolLister(newAgentPoolsClient))
+	return getAllAksNodePools(ctx, rgClient, sdkAksAgentPoolLister(newAgentPoolsClient), sdkAksNodeNameLister(func(subscriptionId string) (MachinesApi, error) {
+		return newMachinesClient(subscriptionId)
+	}))
 }
 
 // aksClusterRef carries the addressing fields needed to issue per-cluster AgentPool listings.
@@ -131,12 +213,34 @@ func sdkAksAgentPoolLister(provider func(subscriptionId string) (*armcontainerse
 	}
 }
 
+// aksNodeNameLister returns the names of the nodes of one node pool.
+type aksNodeNameLister func(ctx context.Context, subscriptionId, resourceGroup, clusterName, nodePoolName string) ([]string, error)
+
+// sdkAksNodeNameLister lists the machines of the node pool, which are named like their nodes. Production use only.
+func sdkAksNodeNameLister(provider func(subscriptionId string) (MachinesApi, error)) aksNodeNameLister {
+	return func(ctx context.Context, subscriptionId, resourceGroup, clusterName, nodePoolName string) ([]string, error) {
+		client, err := provider(subscriptionId)
+		if err != nil {
+			return nil, err
+		}
+		machines, err := listNodePoolMachines(ctx, client, resourceGroup, clusterName, nodePoolName)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(machines))
+		for _, m := range machines {
+			names = append(names, m.Name)
+		}
+		return names, nil
+	}
+}
+
 // getAllAksNodePools lists AKS node pools via direct ARM. Resource Graph indexes the
 // Microsoft.ContainerService/managedClusters/agentPools type with a multi-minute lag, which makes
 // ad-hoc dev testing painful (newly-created clusters' pools don't appear for ~15-20 min). Direct
 // ARM is real-time. Clusters themselves still come from Resource Graph because their cardinality
 // is low and the lag matters less at the cluster level.
-func getAllAksNodePools(ctx context.Context, rgClient common.ArmResourceGraphApi, lister aksAgentPoolLister) ([]discovery_kit_api.Target, error) {
+func getAllAksNodePools(ctx context.Context, rgClient common.ArmResourceGraphApi, lister aksAgentPoolLister, nodeNames aksNodeNameLister) ([]discovery_kit_api.Target, error) {
 	clusters, err := listAksClusterRefs(ctx, rgClient)
 	if err != nil {
 		return nil, err
@@ -153,7 +257,16 @@ func getAllAksNodePools(ctx context.Context, rgClient common.ArmResourceGraphApi
 			if p == nil {
 				continue
 			}
-			targets = append(targets, nodePoolTargetFromSDK(p, c))
+			target := nodePoolTargetFromSDK(p, c)
+			if nodeNames != nil && p.Name != nil {
+				// Without node names the pods of the pool are not enriched, the node pool itself is still discovered.
+				if names, err := nodeNames(ctx, c.subscriptionId, c.resourceGroup, c.name, *p.Name); err != nil {
+					log.Warn().Err(err).Msgf("failed to list the nodes of AKS node pool %s/%s", c.name, *p.Name)
+				} else if len(names) > 0 {
+					target.Attributes["azure.aks.nodepool.node.name"] = names
+				}
+			}
+			targets = append(targets, target)
 		}
 	}
 	return discovery_kit_commons.ApplyAttributeExcludes(targets, config.Config.DiscoveryAttributesExcludesAksNodePool), nil
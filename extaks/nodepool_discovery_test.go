@@ -102,7 +102,7 @@ func TestGetAllAksNodePools_HappyPath(t *testing.T) {
 		}, nil
 	}
 
-	targets, err := getAllAksNodePools(context.Background(), rg, lister)
+	targets, err := getAllAksNodePools(context.Background(), rg, lister, nil)
 	require.NoError(t, err)
 	require.Len(t, targets, 1)
 }
@@ -124,7 +124,7 @@ func TestGetAllAksNodePools_PerClusterListerErrorIsTolerated(t *testing.T) {
 		}, nil
 	}
 
-	targets, err := getAllAksNodePools(context.Background(), rg, lister)
+	targets, err := getAllAksNodePools(context.Background(), rg, lister, nil)
 	require.NoError(t, err)
 	require.Len(t, targets, 1, "c2 pool survives even though c1 failed")
 }
@@ -135,10 +135,38 @@ func TestGetAllAksNodePools_RGError(t *testing.T) {
 
 	_, err := getAllAksNodePools(context.Background(), rg, func(context.Context, string, string, string) ([]*armcontainerservice.AgentPool, error) {
 		return nil, nil
-	})
+	}, nil)
 	require.Error(t, err)
 }
 
+func TestGetAllAksNodePools_NodeNames(t *testing.T) {
+	rg := new(rgClientMock)
+	rg.On("Resources", mock.Anything, mock.Anything, mock.Anything).
+		Return(rgResponse(map[string]any{"name": "aks-c", "resourceGroup": "rg-1", "subscriptionId": "sub-1", "location": "westeurope"}), nil)
+
+	lister := func(context.Context, string, string, string) ([]*armcontainerservice.AgentPool, error) {
+		return []*armcontainerservice.AgentPool{
+			{ID: new("/.../aks-c/np/np1"), Name: new("np1"), Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{}},
+			{ID: new("/.../aks-c/np/np2"), Name: new("np2"), Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{}},
+		}, nil
+	}
+	nodeNames := func(ctx context.Context, sub, rg, cluster, nodePool string) ([]string, error) {
+		assert.Equal(t, "sub-1", sub)
+		assert.Equal(t, "rg-1", rg)
+		assert.Equal(t, "aks-c", cluster)
+		if nodePool == "np2" {
+			return nil, errors.New("transient")
+		}
+		return []string{"aks-np1-12345678-vmss000000", "aks-np1-12345678-vmss000001"}, nil
+	}
+
+	targets, err := getAllAksNodePools(context.Background(), rg, lister, nodeNames)
+	require.NoError(t, err)
+	require.Len(t, targets, 2, "np2 survives even though its nodes can't be listed")
+	assert.Equal(t, []string{"aks-np1-12345678-vmss000000", "aks-np1-12345678-vmss000001"}, targets[0].Attributes["azure.aks.nodepool.node.name"])
+	assert.NotContains(t, targets[1].Attributes, "azure.aks.nodepool.node.name")
+}
+
 func TestListAksClusterRefs_ParsesRows(t *testing.T) {
 	rg := new(rgClientMock)
 	rg.On("Resources", mock.Anything, mock.Anything, mock.Anything).
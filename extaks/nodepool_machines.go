@@ -0,0 +1,121 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extaks
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+)
+
+const scaleSetInstanceResourceType = "Microsoft.Compute/virtualMachineScaleSets/virtualMachines"
+
+// nodePoolMachine is a node of an AKS node pool and, for VMSS-backed pools, the ID of its scale set instance.
+type nodePoolMachine struct {
+	Name       string
+	ResourceID string
+	// Zone is the availability zone of the machine, once resolved by resolveMachineZones.
+	Zone string
+}
+
+// ScaleSetVMReaderApi captures the subset of armcompute.VirtualMachineScaleSetVMsClient used to read the zones of nodes.
+type ScaleSetVMReaderApi interface {
+	Get(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientGetOptions) (armcompute.VirtualMachineScaleSetVMsClientGetResponse, error)
+}
+
+// listNodePoolMachines returns the machines of a node pool sorted by name.
+func listNodePoolMachines(ctx context.Context, client MachinesApi, resourceGroupName, clusterName, nodePoolName string) ([]nodePoolMachine, error) {
+	pager := client.NewListPager(resourceGroupName, clusterName, nodePoolName, nil)
+	machines := make([]nodePoolMachine, 0)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range page.Value {
+			if m == nil || m.Name == nil {
+				continue
+			}
+			machine := nodePoolMachine{Name: *m.Name}
+			if m.Properties != nil && m.Properties.ResourceID != nil {
+				machine.ResourceID = *m.Properties.ResourceID
+			}
+			machines = append(machines, machine)
+		}
+	}
+	sort.Slice(machines, func(i, j int) bool { return machines[i].Name < machines[j].Name })
+	return machines, nil
+}
+
+// sampleSizeOf returns how many of total machines make up pct percent, rounded up and at least one.
+func sampleSizeOf(total int, pct int) int {
+	return min(max(int(math.Ceil(float64(total)*float64(pct)/100.0)), 1), total)
+}
+
+// isScaleSetInstance reports whether the machine is backed by a scale set instance, not by a standalone virtual machine.
+func (m nodePoolMachine) isScaleSetInstance() bool {
+	_, err := parseInstanceId(m.ResourceID)
+	return err == nil
+}
+
+// resolveMachineZones sets the zone of the VMSS-backed machines. The machines API doesn't report zones, so the scale set
+// instances are read for that.
+func resolveMachineZones(ctx context.Context, client ScaleSetVMReaderApi, machines []nodePoolMachine) error {
+	for i, m := range machines {
+		if !m.isScaleSetInstance() {
+			continue
+		}
+		instance, err := parseInstanceId(m.ResourceID)
+		if err != nil {
+			return err
+		}
+		vm, err := client.Get(ctx, instance.ResourceGroupName, instance.Parent.Name, instance.Name, nil)
+		if err != nil {
+			return err
+		}
+		if len(vm.Zones) > 0 && vm.Zones[0] != nil {
+			machines[i].Zone = *vm.Zones[0]
+		}
+	}
+	return nil
+}
+
+// parseInstanceId parses the ID of a scale set instance. IDs of other resources, e.g. of the standalone virtual machines
+// of node pools not backed by a scale set, are rejected.
+func parseInstanceId(id string) (*arm.ResourceID, error) {
+	instance, err := arm.ParseResourceID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(instance.ResourceType.String(), scaleSetInstanceResourceType) || instance.Parent == nil {
+		return nil, fmt.Errorf("unexpected scale set instance ID %s", id)
+	}
+	return instance, nil
+}
+
+// zoneReport lists the machines per zone, e.g. "zone 1: a, b; zone 2: c". It returns "" if no zone is known.
+func zoneReport(machineNames []string, zones map[string]string) string {
+	byZone := make(map[string][]string)
+	for _, name := range machineNames {
+		if zone := zones[name]; zone != "" {
+			byZone[zone] = append(byZone[zone], name)
+		}
+	}
+	keys := make([]string, 0, len(byZone))
+	for zone := range byZone {
+		keys = append(keys, zone)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, zone := range keys {
+		parts = append(parts, fmt.Sprintf("zone %s: %s", zone, strings.Join(byZone[zone], ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -85,6 +85,10 @@ func (d *apimDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescri
 }
 
 func (d *apimDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDApiManagement, d.discoverTargets)
+}
+
+func (d *apimDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
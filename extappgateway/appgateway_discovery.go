@@ -91,6 +91,10 @@ func (d *appGatewayDiscovery) DescribeAttributes() []discovery_kit_api.Attribute
 }
 
 func (d *appGatewayDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDAppGateway, d.discoverTargets)
+}
+
+func (d *appGatewayDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extavailabilityset
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/steadybit/extension-azure/config"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	TargetIDAvailabilitySet = "com.steadybit.extension_azure.availability-set"
+	targetIcon              = "data:image/svg+xml;base64,PHN2ZyB3aWR0aD0iMjQiIGhlaWdodD0iMjQiIHZpZXdCb3g9IjAgMCAyNCAyNCIgZmlsbD0ibm9uZSIgeG1sbnM9Imh0dHA6Ly93d3cudzMub3JnLzIwMDAvc3ZnIj48cmVjdCB4PSIzIiB5PSI0IiB3aWR0aD0iNC41IiBoZWlnaHQ9IjE2IiByeD0iMSIgc3Ryb2tlPSJjdXJyZW50Q29sb3IiIHN0cm9rZS13aWR0aD0iMS41Ii8+PHJlY3QgeD0iOS43NSIgeT0iNCIgd2lkdGg9IjQuNSIgaGVpZ2h0PSIxNiIgcng9IjEiIHN0cm9rZT0iY3VycmVudENvbG9yIiBzdHJva2Utd2lkdGg9IjEuNSIvPjxyZWN0IHg9IjE2LjUiIHk9IjQiIHdpZHRoPSI0LjUiIGhlaWdodD0iMTYiIHJ4PSIxIiBzdHJva2U9ImN1cnJlbnRDb2xvciIgc3Ryb2tlLXdpZHRoPSIxLjUiLz48cGF0aCBkPSJNNC41IDhoMS41TTExLjI1IDhoMS41TTE4IDhoMS41TTQuNSAxMWgxLjVNMTEuMjUgMTFoMS41TTE4IDExaDEuNSIgc3Ryb2tlPSJjdXJyZW50Q29sb3IiIHN0cm9rZS13aWR0aD0iMS41IiBzdHJva2UtbGluZWNhcD0icm91bmQiLz48L3N2Zz4="
+)
+
+type availabilitySetDiscovery struct{}
+
+var (
+	_ discovery_kit_sdk.TargetDescriber    = (*availabilitySetDiscovery)(nil)
+	_ discovery_kit_sdk.AttributeDescriber = (*availabilitySetDiscovery)(nil)
+)
+
+func NewAvailabilitySetDiscovery() discovery_kit_sdk.TargetDiscovery {
+	return discovery_kit_sdk.NewCachedTargetDiscovery(&availabilitySetDiscovery{},
+		discovery_kit_sdk.WithRefreshTargetsNow(),
+		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 60*time.Second),
+	)
+}
+
+func (d *availabilitySetDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
+	return discovery_kit_api.DiscoveryDescription{
+		Id:       TargetIDAvailabilitySet,
+		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{CallInterval: new("60s")},
+	}
+}
+
+func (d *availabilitySetDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
+	return discovery_kit_api.TargetDescription{
+		Id:       TargetIDAvailabilitySet,
+		Version:  extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:     new(targetIcon),
+		Label:    discovery_kit_api.PluralLabel{One: "Azure Availability Set", Other: "Azure Availability Sets"},
+		Category: new("cloud"),
+		Table: discovery_kit_api.Table{
+			Columns: []discovery_kit_api.Column{
+				{Attribute: "steadybit.label"},
+				{Attribute: "azure.availability-set.platform-fault-domain-count"},
+				{Attribute: "azure.availability-set.platform-update-domain-count"},
+				{Attribute: "azure.availability-set.vm.count"},
+				{Attribute: "azure.location"},
+			},
+			OrderBy: []discovery_kit_api.OrderBy{{Attribute: "steadybit.label", Direction: "ASC"}},
+		},
+	}
+}
+
+func (d *availabilitySetDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescription {
+	return []discovery_kit_api.AttributeDescription{
+		{Attribute: "azure.availability-set.name", Label: discovery_kit_api.PluralLabel{One: "Availability set name", Other: "Availability set names"}},
+		{Attribute: "azure.availability-set.sku-name", Label: discovery_kit_api.PluralLabel{One: "Availability set SKU", Other: "Availability set SKUs"}},
+		{Attribute: "azure.availability-set.platform-fault-domain-count", Label: discovery_kit_api.PluralLabel{One: "Fault domain count", Other: "Fault domain counts"}},
+		{Attribute: "azure.availability-set.platform-update-domain-count", Label: discovery_kit_api.PluralLabel{One: "Update domain count", Other: "Update domain counts"}},
+		{Attribute: "azure.availability-set.vm.name", Label: discovery_kit_api.PluralLabel{One: "Member VM", Other: "Member VMs"}},
+		{Attribute: "azure.availability-set.vm.count", Label: discovery_kit_api.PluralLabel{One: "Member VM count", Other: "Member VM counts"}},
+		{Attribute: "azure.availability-set.proximity-placement-group.id", Label: discovery_kit_api.PluralLabel{One: "Proximity placement group ID", Other: "Proximity placement group IDs"}},
+	}
+}
+
+func (d *availabilitySetDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDAvailabilitySet, d.discoverTargets)
+}
+
+func (d *availabilitySetDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	client, err := common.GetClientByCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	return getAllAvailabilitySets(ctx, client)
+}
+
+func getAllAvailabilitySets(ctx context.Context, client common.ArmResourceGraphApi) ([]discovery_kit_api.Target, error) {
+	targets, err := common.DiscoverViaResourceGraph(ctx, client,
+		"Resources | where type =~ 'Microsoft.Compute/availabilitySets' | project id, name, type, resourceGroup, location, tags, properties, sku, subscriptionId",
+		toAvailabilitySetTarget)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get availability set results")
+		return nil, err
+	}
+	return discovery_kit_commons.ApplyAttributeExcludes(targets, config.Config.DiscoveryAttributesExcludesAvailabilitySet), nil
+}
+
+func toAvailabilitySetTarget(items map[string]any) discovery_kit_api.Target {
+	properties := common.GetMapValue(items, "properties")
+	sku := common.GetMapValue(items, "sku")
+
+	id, _ := items["id"].(string)
+	name, _ := items["name"].(string)
+
+	attributes := make(map[string][]string)
+	attributes["azure.availability-set.name"] = []string{name}
+	attributes["azure.subscription.id"] = []string{common.StringFromMap(items, "subscriptionId")}
+	attributes["azure.resource-group.name"] = []string{common.StringFromMap(items, "resourceGroup")}
+	attributes["azure.location"] = []string{common.StringFromMap(items, "location")}
+
+	if v := common.StringFromMap(sku, "name"); v != "" {
+		attributes["azure.availability-set.sku-name"] = []string{v}
+	}
+	if v, ok := properties["platformFaultDomainCount"].(float64); ok {
+		attributes["azure.availability-set.platform-fault-domain-count"] = []string{strconv.Itoa(int(v))}
+	}
+	if v, ok := properties["platformUpdateDomainCount"].(float64); ok {
+		attributes["azure.availability-set.platform-update-domain-count"] = []string{strconv.Itoa(int(v))}
+	}
+	if v := common.StringFromMap(common.GetMapValue(properties, "proximityPlacementGroup"), "id"); v != "" {
+		attributes["azure.availability-set.proximity-placement-group.id"] = []string{v}
+	}
+
+	// Members are referenced by resource ID, which Azure often reports upper-cased. They always live in the resource
+	// group of the availability set, so the name is enough to address them.
+	members := make([]string, 0)
+	if vms, ok := properties["virtualMachines"].([]any); ok {
+		for _, vm := range vms {
+			if ref, ok := vm.(map[string]any); ok {
+				if vmId := common.StringFromMap(ref, "id"); vmId != "" {
+					members = append(members, path.Base(vmId))
+				}
+			}
+		}
+	}
+	sort.Strings(members)
+	if len(members) > 0 {
+		attributes["azure.availability-set.vm.name"] = members
+	}
+	attributes["azure.availability-set.vm.count"] = []string{strconv.Itoa(len(members))}
+
+	for k, v := range common.GetMapValue(items, "tags") {
+		attributes[fmt.Sprintf("azure.availability-set.label.%s", strings.ToLower(k))] = []string{extutil.ToString(v)}
+	}
+
+	return discovery_kit_api.Target{
+		Id:         id,
+		TargetType: TargetIDAvailabilitySet,
+		Label:      name,
+		Attributes: attributes,
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extavailabilityset
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type rgClientMock struct{ mock.Mock }
+
+func (m *rgClientMock) Resources(ctx context.Context, q armresourcegraph.QueryRequest, o *armresourcegraph.ClientResourcesOptions) (armresourcegraph.ClientResourcesResponse, error) {
+	args := m.Called(ctx, q, o)
+	if r := args.Get(0); r != nil {
+		return *(r.(*armresourcegraph.ClientResourcesResponse)), args.Error(1)
+	}
+	return armresourcegraph.ClientResourcesResponse{}, args.Error(1)
+}
+
+func rgResponse(rows ...map[string]any) *armresourcegraph.ClientResourcesResponse {
+	var total = int64(len(rows))
+	data := make([]any, 0, len(rows))
+	for _, r := range rows {
+		data = append(data, r)
+	}
+	return &armresourcegraph.ClientResourcesResponse{
+		QueryResponse: armresourcegraph.QueryResponse{TotalRecords: &total, Data: data},
+	}
+}
+
+func TestAvailabilitySetDescribe(t *testing.T) {
+	d := &availabilitySetDiscovery{}
+	assert.Equal(t, TargetIDAvailabilitySet, d.Describe().Id)
+	td := d.DescribeTarget()
+	assert.Equal(t, TargetIDAvailabilitySet, td.Id)
+	assert.Equal(t, "Azure Availability Set", td.Label.One)
+	assert.NotEmpty(t, td.Table.Columns)
+	for _, a := range d.DescribeAttributes() {
+		assert.NotEmpty(t, a.Attribute)
+		assert.NotEmpty(t, a.Label.One)
+		assert.NotEmpty(t, a.Label.Other)
+	}
+}
+
+func TestToAvailabilitySetTarget(t *testing.T) {
+	in := map[string]any{
+		"id":             "/subscriptions/s1/resourceGroups/rg1/providers/Microsoft.Compute/availabilitySets/as1",
+		"name":           "as1",
+		"subscriptionId": "s1",
+		"resourceGroup":  "rg1",
+		"location":       "westeurope",
+		"sku":            map[string]any{"name": "Aligned"},
+		"tags":           map[string]any{"Team": "payments"},
+		"properties": map[string]any{
+			"platformFaultDomainCount":  float64(2),
+			"platformUpdateDomainCount": float64(5),
+			"proximityPlacementGroup":   map[string]any{"id": "/subscriptions/s1/resourceGroups/rg1/providers/Microsoft.Compute/proximityPlacementGroups/ppg1"},
+			"virtualMachines": []any{
+				map[string]any{"id": "/subscriptions/s1/resourceGroups/RG1/providers/Microsoft.Compute/virtualMachines/VM-2"},
+				map[string]any{"id": "/subscriptions/s1/resourceGroups/RG1/providers/Microsoft.Compute/virtualMachines/VM-1"},
+			},
+		},
+	}
+
+	got := toAvailabilitySetTarget(in)
+
+	assert.Equal(t, "/subscriptions/s1/resourceGroups/rg1/providers/Microsoft.Compute/availabilitySets/as1", got.Id)
+	assert.Equal(t, TargetIDAvailabilitySet, got.TargetType)
+	assert.Equal(t, "as1", got.Label)
+	assert.Equal(t, []string{"as1"}, got.Attributes["azure.availability-set.name"])
+	assert.Equal(t, []string{"rg1"}, got.Attributes["azure.resource-group.name"])
+	assert.Equal(t, []string{"Aligned"}, got.Attributes["azure.availability-set.sku-name"])
+	assert.Equal(t, []string{"2"}, got.Attributes["azure.availability-set.platform-fault-domain-count"])
+	assert.Equal(t, []string{"5"}, got.Attributes["azure.availability-set.platform-update-domain-count"])
+	assert.Equal(t, []string{"/subscriptions/s1/resourceGroups/rg1/providers/Microsoft.Compute/proximityPlacementGroups/ppg1"}, got.Attributes["azure.availability-set.proximity-placement-group.id"])
+	assert.Equal(t, []string{"VM-1", "VM-2"}, got.Attributes["azure.availability-set.vm.name"])
+	assert.Equal(t, []string{"2"}, got.Attributes["azure.availability-set.vm.count"])
+	assert.Equal(t, []string{"payments"}, got.Attributes["azure.availability-set.label.team"])
+}
+
+func TestToAvailabilitySetTarget_WithoutMembers(t *testing.T) {
+	got := toAvailabilitySetTarget(map[string]any{"id": "as-id", "name": "as1", "properties": map[string]any{}})
+
+	assert.NotContains(t, got.Attributes, "azure.availability-set.vm.name")
+	assert.Equal(t, []string{"0"}, got.Attributes["azure.availability-set.vm.count"])
+	assert.NotContains(t, got.Attributes, "azure.availability-set.platform-fault-domain-count")
+}
+
+func TestGetAllAvailabilitySets(t *testing.T) {
+	m := new(rgClientMock)
+	m.On("Resources", mock.Anything, mock.Anything, mock.Anything).Return(rgResponse(
+		map[string]any{"id": "as-a", "name": "a", "properties": map[string]any{}},
+		map[string]any{"id": "as-b", "name": "b", "properties": map[string]any{}},
+	), nil)
+
+	targets, err := getAllAvailabilitySets(context.Background(), m)
+
+	require.NoError(t, err)
+	assert.Len(t, targets, 2)
+}
+
+func TestGetAllAvailabilitySets_Error(t *testing.T) {
+	m := new(rgClientMock)
+	m.On("Resources", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("boom"))
+
+	_, err := getAllAvailabilitySets(context.Background(), m)
+
+	require.Error(t, err)
+}
@@ -0,0 +1,300 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extavailabilityset
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const FaultDomainOutageActionId = "com.steadybit.extension_azure.availability-set.fault-domain-outage"
+
+type FaultDomainOutageState struct {
+	common.ExecutionContextState
+
+	SubscriptionId      string
+	ResourceGroupName   string
+	AvailabilitySetName string
+	FaultDomain         int
+	DryRun              bool
+	VirtualMachines     []FaultDomainVirtualMachine
+}
+
+// FaultDomainVirtualMachine is a member of the availability set powered off by the outage. Error is set if Start
+// failed to power it off, so Stop leaves it alone.
+type FaultDomainVirtualMachine struct {
+	Name        string
+	ResumeToken string
+	Error       string
+}
+
+type faultDomainVirtualMachinesApi interface {
+	InstanceView(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientInstanceViewOptions) (armcompute.VirtualMachinesClientInstanceViewResponse, error)
+	BeginPowerOff(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse], error)
+	BeginStart(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachinesClientStartResponse], error)
+}
+
+type faultDomainOutageAction struct {
+	clientProvider func(subscriptionId string) (faultDomainVirtualMachinesApi, error)
+}
+
+var _ action_kit_sdk.Action[FaultDomainOutageState] = (*faultDomainOutageAction)(nil)
+var _ action_kit_sdk.ActionWithStop[FaultDomainOutageState] = (*faultDomainOutageAction)(nil)
+var _ common.ActionWithRequiredPermissions[FaultDomainOutageState] = (*faultDomainOutageAction)(nil)
+
+func NewFaultDomainOutageAction() action_kit_sdk.ActionWithStop[FaultDomainOutageState] {
+	return &faultDomainOutageAction{
+		clientProvider: func(subscriptionId string) (faultDomainVirtualMachinesApi, error) {
+			return common.GetVirtualMachinesClient(subscriptionId)
+		},
+	}
+}
+
+func (a *faultDomainOutageAction) NewEmptyState() FaultDomainOutageState {
+	return FaultDomainOutageState{}
+}
+
+func (a *faultDomainOutageAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    FaultDomainOutageActionId,
+		Label: "Fault Domain Outage",
+		Description: "Simulates the outage of a fault domain of an availability set by powering off every running member virtual machine " +
+			"in it for a given duration and starting them again afterwards. Validates that the remaining fault domains carry the load.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDAvailabilitySet,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "by availability set name",
+					Description: new("Find availability set by name"),
+					Query:       "azure.availability-set.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Availability Sets"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the fault domain stays down."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("5m"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:         "faultDomain",
+				Label:        "Fault Domain",
+				Description:  new("The fault domain to take down, starting at 0."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("0"),
+				MinValue:     new(0),
+				Order:        new(2),
+				Required:     new(true),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *faultDomainOutageAction) Prepare(ctx context.Context, state *FaultDomainOutageState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.SubscriptionId = mustHave(request.Target.Attributes, "azure.subscription.id")
+	state.ResourceGroupName = mustHave(request.Target.Attributes, "azure.resource-group.name")
+	state.AvailabilitySetName = mustHave(request.Target.Attributes, "azure.availability-set.name")
+	if state.SubscriptionId == "" || state.ResourceGroupName == "" || state.AvailabilitySetName == "" {
+		return nil, extension_kit.ToError("Target is missing one of: azure.subscription.id, azure.resource-group.name, azure.availability-set.name", nil)
+	}
+	faultDomainCount, err := strconv.Atoi(mustHave(request.Target.Attributes, "azure.availability-set.platform-fault-domain-count"))
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Availability set %s has no fault domain count.", state.AvailabilitySetName), nil)
+	}
+	state.FaultDomain = extutil.ToInt(request.Config["faultDomain"])
+	if state.FaultDomain < 0 || state.FaultDomain >= faultDomainCount {
+		return nil, extension_kit.ToError(fmt.Sprintf("Availability set %s has no fault domain %d. Its fault domains are 0 to %d.", state.AvailabilitySetName, state.FaultDomain, faultDomainCount-1), nil)
+	}
+	members := request.Target.Attributes["azure.availability-set.vm.name"]
+	if len(members) == 0 {
+		return nil, extension_kit.ToError(fmt.Sprintf("Availability set %s has no virtual machines.", state.AvailabilitySetName), nil)
+	}
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize virtual machines client for subscription %s", state.SubscriptionId), err)
+	}
+	// The fault domain of a member is only known to the platform, so it is read from the instance view of every member.
+	state.VirtualMachines = make([]FaultDomainVirtualMachine, 0)
+	skipped := make([]string, 0)
+	for _, name := range members {
+		view, err := client.InstanceView(ctx, state.ResourceGroupName, name, nil)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to get the instance view of virtual machine %s", name), err)
+		}
+		if view.PlatformFaultDomain == nil || int(*view.PlatformFaultDomain) != state.FaultDomain {
+			continue
+		}
+		if powerState := common.PowerStateOf(view.Statuses); powerState != "running" {
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", name, powerState))
+			continue
+		}
+		state.VirtualMachines = append(state.VirtualMachines, FaultDomainVirtualMachine{Name: name})
+	}
+	if len(state.VirtualMachines) == 0 {
+		return nil, extension_kit.ToError(fmt.Sprintf("No running virtual machine of availability set %s is in fault domain %d.", state.AvailabilitySetName, state.FaultDomain), nil)
+	}
+
+	messages := []action_kit_api.Message{{
+		Level: extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Fault domain %d of availability set %s will be taken down: %s.",
+			state.FaultDomain, state.AvailabilitySetName, strings.Join(state.virtualMachineNames(), ", ")),
+	}}
+	if len(skipped) > 0 {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Warn),
+			Message: fmt.Sprintf("Virtual machines in fault domain %d which are not running are left alone: %s.", state.FaultDomain, strings.Join(skipped, ", ")),
+		})
+	}
+	return &action_kit_api.PrepareResult{Messages: &messages}, nil
+}
+
+func (s *FaultDomainOutageState) virtualMachineNames() []string {
+	names := make([]string, 0, len(s.VirtualMachines))
+	for _, vm := range s.VirtualMachines {
+		names = append(names, vm.Name)
+	}
+	return names
+}
+
+func (a *faultDomainOutageAction) RequiredPermissions(state *FaultDomainOutageState) []common.PermissionRequirement {
+	requirements := make([]common.PermissionRequirement, 0, len(state.VirtualMachines))
+	for _, vm := range state.VirtualMachines {
+		requirements = append(requirements, common.PermissionRequirement{
+			Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s", state.SubscriptionId, state.ResourceGroupName, vm.Name),
+			Operations: []string{
+				"Microsoft.Compute/virtualMachines/read",
+				"Microsoft.Compute/virtualMachines/powerOff/action",
+				"Microsoft.Compute/virtualMachines/start/action",
+			},
+		})
+	}
+	return requirements
+}
+
+// Start powers the virtual machines off rather than deallocating them, so they keep their hosts and thus their fault
+// domain when they are started again.
+func (a *faultDomainOutageAction) Start(ctx context.Context, state *FaultDomainOutageState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.VirtualMachines))
+		for _, vm := range state.VirtualMachines {
+			mutations = append(mutations, fmt.Sprintf("power off virtual machine '%s' in resource group '%s'", vm.Name, state.ResourceGroupName))
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize virtual machines client for subscription %s", state.SubscriptionId), err)
+	}
+	messages := make([]action_kit_api.Message, 0, len(state.VirtualMachines))
+	failed := 0
+	for i := range state.VirtualMachines {
+		vm := &state.VirtualMachines[i]
+		operation, err := common.NewOperation(client.BeginPowerOff(ctx, state.ResourceGroupName, vm.Name, nil))
+		if err == nil && operation != nil {
+			if vm.ResumeToken, err = operation.ResumeToken(); err != nil {
+				log.Warn().Err(err).Msgf("Failed to get the resume token of the power off of vm '%s'.", vm.Name)
+				err = nil
+			}
+		}
+		messages = append(messages, common.Outcome(err, fmt.Sprintf("power off of virtual machine '%s'", vm.Name)))
+		if err != nil {
+			vm.Error = err.Error()
+			failed++
+		}
+	}
+
+	if failed == len(state.VirtualMachines) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to power off any virtual machine in fault domain %d of availability set %s", state.FaultDomain, state.AvailabilitySetName), nil)
+	}
+	return &action_kit_api.StartResult{Messages: &messages}, nil
+}
+
+func (a *faultDomainOutageAction) Stop(ctx context.Context, state *FaultDomainOutageState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.VirtualMachines))
+		for _, vm := range state.VirtualMachines {
+			mutations = append(mutations, fmt.Sprintf("start virtual machine '%s' in resource group '%s'", vm.Name, state.ResourceGroupName))
+		}
+		return &action_kit_api.StopResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize virtual machines client for subscription %s", state.SubscriptionId), err)
+	}
+
+	ctx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+
+	powerOffs := make([]common.Operation, 0, len(state.VirtualMachines))
+	restarts := make([]common.Restart, 0, len(state.VirtualMachines))
+	for _, vm := range state.VirtualMachines {
+		if vm.Error != "" {
+			continue
+		}
+		if vm.ResumeToken != "" {
+			operation, err := common.NewOperation(client.BeginPowerOff(ctx, state.ResourceGroupName, vm.Name, &armcompute.VirtualMachinesClientBeginPowerOffOptions{ResumeToken: vm.ResumeToken}))
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to resume the power off of vm '%s', starting it anyway.", vm.Name)
+			} else if operation != nil {
+				powerOffs = append(powerOffs, operation)
+			}
+		}
+		restarts = append(restarts, common.Restart{
+			Description: fmt.Sprintf("start of virtual machine '%s'", vm.Name),
+			Begin: func(ctx context.Context) (common.Operation, error) {
+				return common.NewOperation(client.BeginStart(ctx, state.ResourceGroupName, vm.Name, nil))
+			},
+		})
+	}
+	messages, failed := common.BringBack(ctx, fmt.Sprintf("virtual machines of fault domain %d of availability set %s", state.FaultDomain, state.AvailabilitySetName), powerOffs, restarts)
+
+	if len(failed) > 0 {
+		return &action_kit_api.StopResult{
+			Messages: &messages,
+			Error: &action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Failed to bring %d virtual machine(s) of fault domain %d of availability set %s back.", len(failed), state.FaultDomain, state.AvailabilitySetName),
+				Detail: new(strings.Join(failed, ", ")),
+				Status: new(action_kit_api.Errored),
+			},
+		}, nil
+	}
+	return &action_kit_api.StopResult{Messages: &messages}, nil
+}
+
+func mustHave(attrs map[string][]string, key string) string {
+	v, ok := attrs[key]
+	if !ok || len(v) == 0 {
+		return ""
+	}
+	return v[0]
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extavailabilityset
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type virtualMachinesApiMock struct {
+	mock.Mock
+	views map[string]armcompute.VirtualMachineInstanceView
+}
+
+func (m *virtualMachinesApiMock) InstanceView(_ context.Context, _ string, vmName string, _ *armcompute.VirtualMachinesClientInstanceViewOptions) (armcompute.VirtualMachinesClientInstanceViewResponse, error) {
+	view, ok := m.views[vmName]
+	if !ok {
+		return armcompute.VirtualMachinesClientInstanceViewResponse{}, errors.New("not found")
+	}
+	return armcompute.VirtualMachinesClientInstanceViewResponse{VirtualMachineInstanceView: view}, nil
+}
+
+func (m *virtualMachinesApiMock) BeginPowerOff(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *virtualMachinesApiMock) BeginStart(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachinesClientStartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func instanceView(faultDomain int32, powerState string) armcompute.VirtualMachineInstanceView {
+	return armcompute.VirtualMachineInstanceView{
+		PlatformFaultDomain: new(faultDomain),
+		Statuses: []*armcompute.InstanceViewStatus{
+			{Code: new("ProvisioningState/succeeded")},
+			{Code: new("PowerState/" + powerState)},
+		},
+	}
+}
+
+func newTestAction(vms *virtualMachinesApiMock) *faultDomainOutageAction {
+	vms.views = map[string]armcompute.VirtualMachineInstanceView{
+		"vm-1": instanceView(0, "running"),
+		"vm-2": instanceView(1, "running"),
+		"vm-3": instanceView(0, "running"),
+		"vm-4": instanceView(0, "deallocated"),
+	}
+	return &faultDomainOutageAction{
+		clientProvider: func(string) (faultDomainVirtualMachinesApi, error) { return vms, nil },
+	}
+}
+
+func outageRequest(faultDomain int, members ...string) action_kit_api.PrepareActionRequestBody {
+	return action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"faultDomain": faultDomain},
+		Target: new(action_kit_api.Target{Attributes: map[string][]string{
+			"azure.subscription.id":                              {"42"},
+			"azure.resource-group.name":                          {"rg-42"},
+			"azure.availability-set.name":                        {"as-1"},
+			"azure.availability-set.platform-fault-domain-count": {"2"},
+			"azure.availability-set.vm.name":                     members,
+		}}),
+	}
+}
+
+func TestFaultDomainOutageAction_Prepare(t *testing.T) {
+	tests := []struct {
+		name            string
+		request         action_kit_api.PrepareActionRequestBody
+		expectedVMs     []string
+		expectedWarning bool
+		expectedError   string
+	}{
+		{name: "selects the running members of the fault domain", request: outageRequest(0, "vm-1", "vm-2", "vm-3", "vm-4"), expectedVMs: []string{"vm-1", "vm-3"}, expectedWarning: true},
+		{name: "selects another fault domain", request: outageRequest(1, "vm-1", "vm-2", "vm-3"), expectedVMs: []string{"vm-2"}},
+		{name: "rejects unknown fault domains", request: outageRequest(2, "vm-1"), expectedError: "Availability set as-1 has no fault domain 2. Its fault domains are 0 to 1."},
+		{name: "rejects empty availability sets", request: outageRequest(0), expectedError: "Availability set as-1 has no virtual machines."},
+		{name: "rejects fault domains without running members", request: outageRequest(1, "vm-1", "vm-4"), expectedError: "No running virtual machine of availability set as-1 is in fault domain 1."},
+		{name: "fails for unknown members", request: outageRequest(0, "vm-1", "vm-9"), expectedError: "Failed to get the instance view of virtual machine vm-9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := newTestAction(new(virtualMachinesApiMock))
+			state := action.NewEmptyState()
+
+			result, err := action.Prepare(context.Background(), &state, tt.request)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedVMs, state.virtualMachineNames())
+			if tt.expectedWarning {
+				require.Len(t, *result.Messages, 2)
+				assert.Equal(t, "Virtual machines in fault domain 0 which are not running are left alone: vm-4 (deallocated).", (*result.Messages)[1].Message)
+			} else {
+				assert.Len(t, *result.Messages, 1)
+			}
+		})
+	}
+}
+
+func TestFaultDomainOutageAction_StartAndStopReportPerVirtualMachineOutcomes(t *testing.T) {
+	// Given
+	vms := new(virtualMachinesApiMock)
+	vms.On("BeginPowerOff", mock.Anything, "rg-42", "vm-1").Return(nil, nil)
+	vms.On("BeginPowerOff", mock.Anything, "rg-42", "vm-3").Return(nil, errors.New("conflict"))
+	vms.On("BeginStart", mock.Anything, "rg-42", "vm-1").Return(nil, nil)
+	action := newTestAction(vms)
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.Background(), &state, outageRequest(0, "vm-1", "vm-2", "vm-3"))
+	require.NoError(t, err)
+
+	// When
+	startResult, err := action.Start(context.Background(), &state)
+	require.NoError(t, err)
+	stopResult, err := action.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	// Then
+	require.Len(t, *startResult.Messages, 2)
+	assert.Equal(t, "The power off of virtual machine 'vm-3' failed: conflict", (*startResult.Messages)[1].Message)
+	assert.Equal(t, "conflict", state.VirtualMachines[1].Error)
+	require.Len(t, *stopResult.Messages, 1)
+	assert.Nil(t, stopResult.Error)
+	vms.AssertExpectations(t)
+	vms.AssertNotCalled(t, "BeginStart", mock.Anything, "rg-42", "vm-3")
+}
+
+func TestFaultDomainOutageAction_StopReportsVirtualMachinesNotBroughtBack(t *testing.T) {
+	// Given
+	vms := new(virtualMachinesApiMock)
+	vms.On("BeginStart", mock.Anything, "rg-42", "vm-1").Return(nil, errors.New("allocation failed"))
+	vms.On("BeginStart", mock.Anything, "rg-42", "vm-3").Return(nil, nil)
+	action := newTestAction(vms)
+	state := FaultDomainOutageState{
+		SubscriptionId:      "42",
+		ResourceGroupName:   "rg-42",
+		AvailabilitySetName: "as-1",
+		VirtualMachines:     []FaultDomainVirtualMachine{{Name: "vm-1"}, {Name: "vm-3"}},
+	}
+
+	// When
+	result, err := action.Stop(context.Background(), &state)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "Failed to bring 1 virtual machine(s) of fault domain 0 of availability set as-1 back.", result.Error.Title)
+	assert.Equal(t, "start of virtual machine 'vm-1'", *result.Error.Detail)
+}
+
+func TestFaultDomainOutageAction_Describe(t *testing.T) {
+	desc := NewFaultDomainOutageAction().Describe()
+	assert.Equal(t, FaultDomainOutageActionId, desc.Id)
+	assert.Equal(t, TargetIDAvailabilitySet, desc.TargetSelection.TargetType)
+	assert.NotNil(t, desc.Stop)
+}
@@ -89,6 +89,10 @@ func (d *accountDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDes
 }
 
 func (d *accountDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDCosmosDbAccount, d.discoverTargets)
+}
+
+func (d *accountDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
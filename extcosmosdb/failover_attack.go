@@ -26,6 +26,8 @@ const CosmosDbFailoverActionId = "com.steadybit.extension_azure.cosmosdb.account
 // 5-15 min per call, so a transient experiment-style "set & restore" semantics is misleading. Users
 // who want to switch back can run the experiment again against the (now-secondary) region.
 type CosmosDbFailoverState struct {
+	common.ExecutionContextState
+
 	SubscriptionId    string
 	ResourceGroupName string
 	AccountName       string
@@ -34,6 +36,7 @@ type CosmosDbFailoverState struct {
 	// NewPolicies is the full priority order to submit at Start (computed from the current order
 	// at Prepare, with PromotedRegion swapped to priority 0).
 	NewPolicies []failoverPolicy
+	DryRun      bool
 }
 
 type failoverPolicy struct {
@@ -51,6 +54,7 @@ type cosmosFailoverAttack struct {
 }
 
 var _ action_kit_sdk.Action[CosmosDbFailoverState] = (*cosmosFailoverAttack)(nil)
+var _ common.ActionWithRequiredPermissions[CosmosDbFailoverState] = (*cosmosFailoverAttack)(nil)
 
 func NewCosmosDbFailoverAction() action_kit_sdk.Action[CosmosDbFailoverState] {
 	return &cosmosFailoverAttack{
@@ -59,7 +63,7 @@ func NewCosmosDbFailoverAction() action_kit_sdk.Action[CosmosDbFailoverState] {
 			if err != nil {
 				return nil, err
 			}
-			factory, err := armcosmos.NewClientFactory(subscriptionId, cred, nil)
+			factory, err := armcosmos.NewClientFactory(subscriptionId, cred, common.ArmClientOptions())
 			if err != nil {
 				return nil, err
 			}
@@ -94,7 +98,7 @@ func (a *cosmosFailoverAttack) Describe() action_kit_api.ActionDescription {
 		Category:    new("Cosmos DB"),
 		TimeControl: action_kit_api.TimeControlInstantaneous,
 		Kind:        action_kit_api.Attack,
-		Parameters:  []action_kit_api.ActionParameter{},
+		Parameters:  []action_kit_api.ActionParameter{common.DryRunParameter()},
 	}
 }
 
@@ -134,6 +138,7 @@ func (a *cosmosFailoverAttack) Prepare(ctx context.Context, state *CosmosDbFailo
 	// automatically promote on a regional outage. Most realistic chaos signal.
 	state.PromotedRegion = secondaryWithLowestPriority(current)
 	state.NewPolicies = promotePolicies(current, state.PromotedRegion)
+	state.DryRun = common.IsDryRun(request)
 	return &action_kit_api.PrepareResult{
 		Messages: new([]action_kit_api.Message{{
 			Level:   extutil.Ptr(action_kit_api.Info),
@@ -142,7 +147,22 @@ func (a *cosmosFailoverAttack) Prepare(ctx context.Context, state *CosmosDbFailo
 	}, nil
 }
 
+func (a *cosmosFailoverAttack) RequiredPermissions(state *CosmosDbFailoverState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DocumentDB/databaseAccounts/%s",
+			state.SubscriptionId, state.ResourceGroupName, state.AccountName),
+		Operations: []string{"Microsoft.DocumentDB/databaseAccounts/failoverPriorityChange/action"},
+	}}
+}
+
 func (a *cosmosFailoverAttack) Start(ctx context.Context, state *CosmosDbFailoverState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.NewPolicies))
+		for _, p := range state.NewPolicies {
+			mutations = append(mutations, fmt.Sprintf("set failover priority of region %q to %d for Cosmos DB account %s", p.LocationName, p.Priority, state.AccountName))
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
 	client, err := a.clientProvider(state.SubscriptionId)
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize Cosmos DB client for subscription %s", state.SubscriptionId), err)
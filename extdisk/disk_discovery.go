@@ -89,6 +89,10 @@ func (d *diskDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescri
 }
 
 func (d *diskDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDDisk, d.discoverTargets)
+}
+
+func (d *diskDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
@@ -77,6 +77,10 @@ func (d *subscriptionDiscovery) DescribeAttributes() []discovery_kit_api.Attribu
 }
 
 func (d *subscriptionDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDSubscription, d.discoverTargets)
+}
+
+func (d *subscriptionDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
@@ -83,6 +83,10 @@ func (d *loadBalancerDiscovery) DescribeAttributes() []discovery_kit_api.Attribu
 }
 
 func (d *loadBalancerDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDLoadBalancer, d.discoverTargets)
+}
+
+func (d *loadBalancerDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
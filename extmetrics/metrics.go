@@ -0,0 +1,166 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+// Package extmetrics exposes Prometheus metrics about the extension's own
+// behaviour: discovery cycles, Azure API traffic and action lifecycle
+// outcomes. The metrics are served on the extension's HTTP port under
+// /metrics so that SREs can alert on failed restores and stalled discoveries.
+package extmetrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "steadybit_extension_azure"
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+const (
+	OperationPrepare = "prepare"
+	OperationStart   = "start"
+	OperationStatus  = "status"
+	OperationStop    = "stop"
+)
+
+var (
+	Registry = prometheus.NewRegistry()
+
+	discoveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "discovery_duration_seconds",
+		Help:      "Duration of a discovery cycle per target type.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"target_type", "outcome"})
+
+	discoveryTargets = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "discovery_targets",
+		Help:      "Number of targets returned by the last successful discovery cycle per target type.",
+	}, []string{"target_type"})
+
+	discoveryLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "discovery_last_success_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful discovery cycle per target type. Use it to alert on stalled discoveries.",
+	}, []string{"target_type"})
+
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_requests_total",
+		Help:      "Number of HTTP requests sent to Azure, including retries, by API, resource provider, method and status code.",
+	}, []string{"api", "provider", "method", "status_code"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Latency of HTTP requests sent to Azure by API and resource provider.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"api", "provider"})
+
+	actionOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "action_operations_total",
+		Help:      "Number of action lifecycle calls by action id, operation (prepare, start, status, stop) and outcome.",
+	}, []string{"action_id", "operation", "outcome"})
+
+	actionRestoreFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "action_restore_failures_total",
+		Help:      "Number of stop calls that failed to restore the original state of a target.",
+	}, []string{"action_id"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		discoveryDuration,
+		discoveryTargets,
+		discoveryLastSuccess,
+		apiRequests,
+		apiRequestDuration,
+		actionOperations,
+		actionRestoreFailures,
+	)
+}
+
+// RegisterHandler serves the metrics under /metrics on the extension's HTTP server.
+func RegisterHandler() {
+	http.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// ObserveDiscovery records the duration and result of one discovery cycle.
+func ObserveDiscovery(targetType string, duration time.Duration, targetCount int, err error) {
+	if err != nil {
+		discoveryDuration.WithLabelValues(targetType, OutcomeError).Observe(duration.Seconds())
+		return
+	}
+	discoveryDuration.WithLabelValues(targetType, OutcomeSuccess).Observe(duration.Seconds())
+	discoveryTargets.WithLabelValues(targetType).Set(float64(targetCount))
+	discoveryLastSuccess.WithLabelValues(targetType).SetToCurrentTime()
+}
+
+// ObserveActionOperation records the outcome of a Prepare, Start, Status or Stop call.
+func ObserveActionOperation(actionId string, operation string, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	actionOperations.WithLabelValues(actionId, operation, outcome).Inc()
+}
+
+// ObserveRestoreFailure records a Stop call that left a target in its attacked state.
+func ObserveRestoreFailure(actionId string) {
+	actionRestoreFailures.WithLabelValues(actionId).Inc()
+}
+
+// NewApiPolicy returns an azcore pipeline policy counting every request sent to
+// Azure. Add it as a per-retry policy so throttled (429) attempts are counted too.
+func NewApiPolicy() policy.Policy {
+	return apiPolicy{}
+}
+
+type apiPolicy struct{}
+
+func (apiPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	api, provider := classifyRequest(raw.URL.Host, raw.URL.Path)
+	start := time.Now()
+	resp, err := req.Next()
+	apiRequestDuration.WithLabelValues(api, provider).Observe(time.Since(start).Seconds())
+	statusCode := OutcomeError
+	if resp != nil {
+		statusCode = strconv.Itoa(resp.StatusCode)
+	}
+	apiRequests.WithLabelValues(api, provider, raw.Method, statusCode).Inc()
+	return resp, err
+}
+
+// classifyRequest derives the api and resource provider labels from a request
+// URL. Resource Graph shares the ARM host, so it is told apart by its provider.
+func classifyRequest(host string, path string) (string, string) {
+	if strings.HasSuffix(host, ".azconfig.io") {
+		return "app-configuration", "microsoft.appconfiguration"
+	}
+	provider := ""
+	lower := strings.ToLower(path)
+	if idx := strings.LastIndex(lower, "/providers/"); idx >= 0 {
+		provider = strings.SplitN(lower[idx+len("/providers/"):], "/", 2)[0]
+	}
+	if provider == "microsoft.resourcegraph" {
+		return "resource-graph", provider
+	}
+	return "arm", provider
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extmetrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		host         string
+		path         string
+		wantApi      string
+		wantProvider string
+	}{
+		{"resource graph", "management.azure.com", "/providers/Microsoft.ResourceGraph/resources", "resource-graph", "microsoft.resourcegraph"},
+		{"arm compute", "management.azure.com", "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm/restart", "arm", "microsoft.compute"},
+		{"arm without provider", "management.azure.com", "/subscriptions/s", "arm", ""},
+		{"app configuration", "store.azconfig.io", "/kv/key", "app-configuration", "microsoft.appconfiguration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, provider := classifyRequest(tt.host, tt.path)
+			assert.Equal(t, tt.wantApi, api)
+			assert.Equal(t, tt.wantProvider, provider)
+		})
+	}
+}
+
+func TestApiPolicy_CountsThrottledRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	pipeline := runtime.NewPipeline("test", "v0", runtime.PipelineOptions{}, &policy.ClientOptions{
+		PerRetryPolicies: []policy.Policy{NewApiPolicy()},
+		Retry:            policy.RetryOptions{MaxRetries: -1},
+	})
+	req, err := runtime.NewRequest(t.Context(), http.MethodPost, server.URL+"/subscriptions/s/providers/Microsoft.Compute/virtualMachines/vm/start")
+	require.NoError(t, err)
+
+	before := testutil.ToFloat64(apiRequests.WithLabelValues("arm", "microsoft.compute", http.MethodPost, "429"))
+	resp, err := pipeline.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, before+1, testutil.ToFloat64(apiRequests.WithLabelValues("arm", "microsoft.compute", http.MethodPost, "429")))
+}
+
+func TestObserveDiscovery(t *testing.T) {
+	ObserveDiscovery("test.target", time.Second, 3, nil)
+	assert.Equal(t, float64(3), testutil.ToFloat64(discoveryTargets.WithLabelValues("test.target")))
+	assert.Greater(t, testutil.ToFloat64(discoveryLastSuccess.WithLabelValues("test.target")), float64(0))
+
+	ObserveDiscovery("test.target", time.Second, 0, errors.New("boom"))
+	assert.Equal(t, float64(3), testutil.ToFloat64(discoveryTargets.WithLabelValues("test.target")), "a failed cycle keeps the last known target count")
+}
+
+func TestRegistry_ExposesMetrics(t *testing.T) {
+	ObserveActionOperation("com.steadybit.test", OperationStop, errors.New("boom"))
+	ObserveRestoreFailure("com.steadybit.test")
+
+	names, err := testutil.GatherAndCount(Registry, namespace+"_action_operations_total", namespace+"_action_restore_failures_total")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, names, 2)
+
+	lint, err := testutil.GatherAndLint(Registry)
+	require.NoError(t, err)
+	for _, problem := range lint {
+		assert.False(t, strings.HasPrefix(problem.Metric, namespace), "%s: %s", problem.Metric, problem.Text)
+	}
+}
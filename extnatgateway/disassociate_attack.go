@@ -24,6 +24,8 @@ const NatGatewayDisassociateActionId = "com.steadybit.extension_azure.nat-gatewa
 
 // NatGatewayDisassociateState holds enough information to restore each subnet's NAT Gateway association.
 type NatGatewayDisassociateState struct {
+	common.ExecutionContextState
+
 	SubscriptionId    string
 	ResourceGroupName string
 	NatGatewayId      string
@@ -32,6 +34,7 @@ type NatGatewayDisassociateState struct {
 	// We re-fetch each subnet at stop time and restore only the NatGateway field, so concurrent edits to other
 	// subnet fields by other operators are preserved.
 	SubnetRefs []string
+	DryRun     bool
 }
 
 type subnetsApi interface {
@@ -45,6 +48,7 @@ type natGatewayDisassociateAttack struct {
 
 var _ action_kit_sdk.Action[NatGatewayDisassociateState] = (*natGatewayDisassociateAttack)(nil)
 var _ action_kit_sdk.ActionWithStop[NatGatewayDisassociateState] = (*natGatewayDisassociateAttack)(nil)
+var _ common.ActionWithRequiredPermissions[NatGatewayDisassociateState] = (*natGatewayDisassociateAttack)(nil)
 
 func NewNatGatewayDisassociateAction() action_kit_sdk.ActionWithStop[NatGatewayDisassociateState] {
 	return &natGatewayDisassociateAttack{
@@ -53,7 +57,7 @@ func NewNatGatewayDisassociateAction() action_kit_sdk.ActionWithStop[NatGatewayD
 			if err != nil {
 				return nil, err
 			}
-			return armnetwork.NewSubnetsClient(subscriptionId, cred, nil)
+			return armnetwork.NewSubnetsClient(subscriptionId, cred, common.ArmClientOptions())
 		},
 	}
 }
@@ -94,6 +98,7 @@ func (a *natGatewayDisassociateAttack) Describe() action_kit_api.ActionDescripti
 				Order:        new(1),
 				Required:     new(true),
 			},
+			common.DryRunParameter(),
 		},
 		Stop: new(action_kit_api.MutatingEndpointReference{}),
 	}
@@ -114,6 +119,7 @@ func (a *natGatewayDisassociateAttack) Prepare(_ context.Context, state *NatGate
 		return nil, extension_kit.ToError(fmt.Sprintf("NAT Gateway %s currently has no associated subnets", state.NatGatewayName), nil)
 	}
 	state.SubnetRefs = append(state.SubnetRefs, subnets...)
+	state.DryRun = common.IsDryRun(request)
 	return &action_kit_api.PrepareResult{
 		Messages: new([]action_kit_api.Message{{
 			Level:   extutil.Ptr(action_kit_api.Info),
@@ -122,7 +128,29 @@ func (a *natGatewayDisassociateAttack) Prepare(_ context.Context, state *NatGate
 	}, nil
 }
 
+// RequiredPermissions covers updating every subnet and joining the NAT Gateway back to them on stop.
+func (a *natGatewayDisassociateAttack) RequiredPermissions(state *NatGatewayDisassociateState) []common.PermissionRequirement {
+	requirements := []common.PermissionRequirement{{
+		Scope:      state.NatGatewayId,
+		Operations: []string{"Microsoft.Network/natGateways/join/action"},
+	}}
+	for _, ref := range state.SubnetRefs {
+		requirements = append(requirements, common.PermissionRequirement{
+			Scope:      ref,
+			Operations: []string{"Microsoft.Network/virtualNetworks/subnets/read", "Microsoft.Network/virtualNetworks/subnets/write"},
+		})
+	}
+	return requirements
+}
+
 func (a *natGatewayDisassociateAttack) Start(ctx context.Context, state *NatGatewayDisassociateState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.SubnetRefs))
+		for _, ref := range state.SubnetRefs {
+			mutations = append(mutations, fmt.Sprintf("disassociate NAT Gateway %s from subnet %s", state.NatGatewayName, ref))
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
 	client, err := a.subnetsClientProvider(state.SubscriptionId)
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize subnets client for subscription %s", state.SubscriptionId), err)
@@ -148,6 +176,13 @@ func (a *natGatewayDisassociateAttack) Start(ctx context.Context, state *NatGate
 }
 
 func (a *natGatewayDisassociateAttack) Stop(ctx context.Context, state *NatGatewayDisassociateState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.SubnetRefs))
+		for _, ref := range state.SubnetRefs {
+			mutations = append(mutations, fmt.Sprintf("re-associate subnet %s with NAT Gateway %s", ref, state.NatGatewayId))
+		}
+		return &action_kit_api.StopResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
 	client, err := a.subnetsClientProvider(state.SubscriptionId)
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize subnets client for subscription %s", state.SubscriptionId), err)
@@ -187,3 +187,20 @@ func TestStart_SkipsUnparseableSubnetRefs(t *testing.T) {
 	require.NoError(t, err)
 	client.AssertExpectations(t)
 }
+
+func TestStart_DryRunReportsSubnetsWithoutUpdating(t *testing.T) {
+	client := new(subnetsApiMock)
+	a := newAttack(client)
+	state := NatGatewayDisassociateState{
+		SubscriptionId:    "sub-1",
+		ResourceGroupName: "rg-1",
+		NatGatewayName:    "ngw-1",
+		SubnetRefs:        []string{subnetIDFor("snet-a")},
+		DryRun:            true,
+	}
+	result, err := a.Start(context.Background(), &state)
+	require.NoError(t, err)
+	require.Len(t, *result.Messages, 1)
+	assert.Equal(t, "[dry run] Would disassociate NAT Gateway ngw-1 from subnet "+subnetIDFor("snet-a"), (*result.Messages)[0].Message)
+	client.AssertNotCalled(t, "BeginCreateOrUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
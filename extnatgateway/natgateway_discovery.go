@@ -81,6 +81,10 @@ func (d *natGatewayDiscovery) DescribeAttributes() []discovery_kit_api.Attribute
 }
 
 func (d *natGatewayDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDNatGateway, d.discoverTargets)
+}
+
+func (d *natGatewayDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
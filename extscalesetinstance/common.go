@@ -1,7 +1,10 @@
 package extscalesetinstance
 
 const (
-	TargetIDScaleSetInstance      = "com.steadybit.extension_azure.scale_set.instance"
-	ScaleSetInstanceStateActionId = "com.steadybit.extension_azure.scale_set.instance.state"
-	targetIcon                    = "data:image/svg+xml,%3Csvg%20viewBox%3D%220%200%2024%2024%22%20fill%3D%22none%22%20xmlns%3D%22http%3A%2F%2Fwww.w3.org%2F2000%2Fsvg%22%3E%3Cpath%20d%3D%22M8%2011.8182C8%2012.0145%208.12422%2012.2109%208.32298%2012.2982L11.4727%2014V9.85455L8%208V11.8182Z%22%20fill%3D%22currentColor%22%2F%3E%3Cpath%20d%3D%22M12.1933%205.03405C12.0625%204.93881%2011.8942%204.93881%2011.7634%205.03405L8.5%207.03405L11.9783%209.03405L15.2634%207.03405L12.1933%205.03405Z%22%20fill%3D%22currentColor%22%2F%3E%3Cpath%20d%3D%22M12.5%2014L15.7578%2012.2982C15.9068%2012.2109%2016%2012.0145%2016%2011.8182V8L12.5%209.85455V14Z%22%20fill%3D%22currentColor%22%2F%3E%3Cpath%20d%3D%22M19.8804%203H4.05666C2.9234%203%202%203.90514%202%205.016V15.8983C2%2017.0091%202.9234%2017.9143%204.05666%2017.9143H10.2267L9.95383%2019.7863H8.94648C8.6107%2019.7863%208.31689%2020.0331%208.31689%2020.3623C8.29591%2020.712%208.58972%2021%208.94648%2021H15.0535C15.4103%2021%2015.6831%2020.712%2015.6831%2020.3623C15.6621%2020.0331%2015.3683%2019.7863%2015.0535%2019.7863H14.0462L13.7733%2017.9143H19.9433C21.0766%2017.9143%2022%2017.0091%2022%2015.8983V4.99543C21.937%203.90514%2021.0136%203%2019.8804%203ZM20.6988%2014.088C20.6988%2014.52%2020.3421%2014.8697%2019.9014%2014.8697H4.05666C3.61595%2014.8697%203.25918%2014.52%203.25918%2014.088V4.99543C3.25918%204.56343%203.61595%204.21371%204.05666%204.21371H19.9014C20.3421%204.21371%2020.6988%204.56343%2020.6988%204.99543V14.088Z%22%20fill%3D%22currentColor%22%2F%3E%3C%2Fsvg%3E"
+	TargetIDScaleSetInstance             = "com.steadybit.extension_azure.scale_set.instance"
+	ScaleSetInstanceStateActionId        = "com.steadybit.extension_azure.scale_set.instance.state"
+	ScaleSetInstanceStateWaitActionId    = "com.steadybit.extension_azure.scale_set.instance.state.wait"
+	ScaleSetInstanceStopActionId         = "com.steadybit.extension_azure.scale_set.instance.stop"
+	ScaleSetInstanceSpotEvictionActionId = "com.steadybit.extension_azure.scale_set.instance.simulate-spot-eviction"
+	targetIcon                           = "data:image/svg+xml,%3Csvg%20viewBox%3D%220%200%2024%2024%22%20fill%3D%22none%22%20xmlns%3D%22http%3A%2F%2Fwww.w3.org%2F2000%2Fsvg%22%3E%3Cpath%20d%3D%22M8%2011.8182C8%2012.0145%208.12422%2012.2109%208.32298%2012.2982L11.4727%2014V9.85455L8%208V11.8182Z%22%20fill%3D%22currentColor%22%2F%3E%3Cpath%20d%3D%22M12.1933%205.03405C12.0625%204.93881%2011.8942%204.93881%2011.7634%205.03405L8.5%207.03405L11.9783%209.03405L15.2634%207.03405L12.1933%205.03405Z%22%20fill%3D%22currentColor%22%2F%3E%3Cpath%20d%3D%22M12.5%2014L15.7578%2012.2982C15.9068%2012.2109%2016%2012.0145%2016%2011.8182V8L12.5%209.85455V14Z%22%20fill%3D%22currentColor%22%2F%3E%3Cpath%20d%3D%22M19.8804%203H4.05666C2.9234%203%202%203.90514%202%205.016V15.8983C2%2017.0091%202.9234%2017.9143%204.05666%2017.9143H10.2267L9.95383%2019.7863H8.94648C8.6107%2019.7863%208.31689%2020.0331%208.31689%2020.3623C8.29591%2020.712%208.58972%2021%208.94648%2021H15.0535C15.4103%2021%2015.6831%2020.712%2015.6831%2020.3623C15.6621%2020.0331%2015.3683%2019.7863%2015.0535%2019.7863H14.0462L13.7733%2017.9143H19.9433C21.0766%2017.9143%2022%2017.0091%2022%2015.8983V4.99543C21.937%203.90514%2021.0136%203%2019.8804%203ZM20.6988%2014.088C20.6988%2014.52%2020.3421%2014.8697%2019.9014%2014.8697H4.05666C3.61595%2014.8697%203.25918%2014.52%203.25918%2014.088V4.99543C3.25918%204.56343%203.61595%204.21371%204.05666%204.21371H19.9014C20.3421%204.21371%2020.6988%204.56343%2020.6988%204.99543V14.088Z%22%20fill%3D%22currentColor%22%2F%3E%3C%2Fsvg%3E"
 )
@@ -22,13 +22,20 @@ type scaleSetInstanceAction struct {
 
 // Make sure lambdaAction implements all required interfaces
 var _ action_kit_sdk.Action[ScaleSetInstanceChangeState] = (*scaleSetInstanceAction)(nil)
+var _ common.ActionWithRequiredPermissions[ScaleSetInstanceChangeState] = (*scaleSetInstanceAction)(nil)
+var _ action_kit_sdk.ActionWithStatus[ScaleSetInstanceChangeState] = (*scaleSetInstanceWaitAction)(nil)
+var _ common.ActionWithRequiredPermissions[ScaleSetInstanceChangeState] = (*scaleSetInstanceWaitAction)(nil)
 
 type ScaleSetInstanceChangeState struct {
+	common.ExecutionContextState
+	common.OperationTracking
+
 	SubscriptionId    string
 	VmScaleSetName    string
 	InstanceID        string
 	ResourceGroupName string
 	Action            string
+	DryRun            bool
 }
 
 type scaleSetInstanceChangeApi interface {
@@ -36,6 +43,7 @@ type scaleSetInstanceChangeApi interface {
 	BeginDelete(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginDeleteOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientDeleteResponse], error)
 	BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientPowerOffResponse], error)
 	BeginDeallocate(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientDeallocateResponse], error)
+	GetInstanceView(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewOptions) (armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse, error)
 }
 
 func NewScaleSetInstanceStateAction() action_kit_sdk.Action[ScaleSetInstanceChangeState] {
@@ -103,6 +111,7 @@ func (e *scaleSetInstanceAction) Describe() action_kit_api.ActionDescription {
 					},
 				}),
 			},
+			common.DryRunParameter(),
 		},
 	}
 }
@@ -138,34 +147,128 @@ func (e *scaleSetInstanceAction) Prepare(_ context.Context, state *ScaleSetInsta
 	state.InstanceID = instanceId[0]
 	state.ResourceGroupName = resourceGroupName[0]
 	state.Action = action.(string)
+	state.DryRun = common.IsDryRun(request)
 	return nil, nil
 }
 
 func (e *scaleSetInstanceAction) Start(ctx context.Context, state *ScaleSetInstanceChangeState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("%s instance '%s' of scale set '%s' in resource group '%s'", state.Action, state.InstanceID, state.VmScaleSetName, state.ResourceGroupName)),
+		}, nil
+	}
+
 	client, err := e.clientProvider(state.SubscriptionId)
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
 	}
 
-	if state.Action == "restart" {
-		_, err = client.BeginRestart(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, nil)
-	} else if state.Action == "power-off" {
-		_, err = client.BeginPowerOff(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, nil)
-	} else if state.Action == "delete" {
-		_, err = client.BeginDelete(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, nil)
-	} else if state.Action == "deallocate" {
-		_, err = client.BeginDeallocate(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, nil)
-	} else {
+	if _, ok := scaleSetInstanceOperations[state.Action]; !ok {
 		return nil, extension_kit.ToError(fmt.Sprintf("Unknown state change attack '%s'", state.Action), nil)
 	}
 
+	operation, err := beginStateChange(ctx, client, state, "")
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to execute state change attack '%s' on vm '%s'", state.Action, state.VmScaleSetName), err)
 	}
 
+	if err := state.Track(ctx, operation); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to track state change attack '%s' on vm '%s'", state.Action, state.VmScaleSetName), err)
+	}
+
 	return nil, nil
 }
 
+// scaleSetInstanceWaitAction changes the state like scaleSetInstanceAction and waits in Status until Azure completed the
+// operation. It is a separate action as the time control of an action cannot depend on its parameters.
+type scaleSetInstanceWaitAction struct {
+	scaleSetInstanceAction
+}
+
+func NewScaleSetInstanceStateWaitAction() action_kit_sdk.ActionWithStatus[ScaleSetInstanceChangeState] {
+	return &scaleSetInstanceWaitAction{scaleSetInstanceAction{defaultClientProvider}}
+}
+
+func (e *scaleSetInstanceWaitAction) Describe() action_kit_api.ActionDescription {
+	description := e.scaleSetInstanceAction.Describe()
+	description.Id = ScaleSetInstanceStateWaitActionId
+	description.Label = "Change Virtual Machine State and Wait"
+	description.Description = "Restart, stop, deallocate or delete Azure scale set instances and wait until Azure completed the operation"
+	description.TimeControl = action_kit_api.TimeControlInternal
+	description.Parameters = append(description.Parameters, common.CompletionTimeoutParameter())
+	description.Status = new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+		CallInterval: new("10s"),
+	})
+	return description
+}
+
+func (e *scaleSetInstanceWaitAction) Prepare(ctx context.Context, state *ScaleSetInstanceChangeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	result, err := e.scaleSetInstanceAction.Prepare(ctx, state, request)
+	if err != nil {
+		return nil, err
+	}
+	state.PrepareTracking(request)
+	return result, nil
+}
+
+func (e *scaleSetInstanceWaitAction) Status(ctx context.Context, state *ScaleSetInstanceChangeState) (*action_kit_api.StatusResult, error) {
+	if !state.IsTracking() {
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+
+	var messages []action_kit_api.Message
+	if instanceView, err := client.GetInstanceView(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, nil); err == nil {
+		messages = state.ObservePowerState(fmt.Sprintf("power state of instance '%s' of scale set '%s'", state.InstanceID, state.VmScaleSetName), common.PowerStateOf(instanceView.Statuses))
+	}
+
+	operation, err := beginStateChange(ctx, client, state, state.ResumeToken)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to resume state change attack '%s' on vm '%s'", state.Action, state.VmScaleSetName), err)
+	}
+	return state.PollTracked(ctx, operation, fmt.Sprintf("%s of instance '%s' of scale set '%s'", state.Action, state.InstanceID, state.VmScaleSetName), messages), nil
+}
+
+// beginStateChange starts the state change, or resumes it if a resume token is given.
+func beginStateChange(ctx context.Context, client scaleSetInstanceChangeApi, state *ScaleSetInstanceChangeState, resumeToken string) (common.Operation, error) {
+	switch state.Action {
+	case "restart":
+		return common.NewOperation(client.BeginRestart(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, &armcompute.VirtualMachineScaleSetVMsClientBeginRestartOptions{ResumeToken: resumeToken}))
+	case "power-off":
+		return common.NewOperation(client.BeginPowerOff(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, &armcompute.VirtualMachineScaleSetVMsClientBeginPowerOffOptions{ResumeToken: resumeToken}))
+	case "delete":
+		return common.NewOperation(client.BeginDelete(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, &armcompute.VirtualMachineScaleSetVMsClientBeginDeleteOptions{ResumeToken: resumeToken}))
+	case "deallocate":
+		return common.NewOperation(client.BeginDeallocate(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, &armcompute.VirtualMachineScaleSetVMsClientBeginDeallocateOptions{ResumeToken: resumeToken}))
+	default:
+		return nil, fmt.Errorf("unknown state change '%s'", state.Action)
+	}
+}
+
+// scaleSetInstanceOperations maps each state change to the Azure operation it performs.
+var scaleSetInstanceOperations = map[string]string{
+	"restart":    "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/restart/action",
+	"power-off":  "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/powerOff/action",
+	"delete":     "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/delete",
+	"deallocate": "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/deallocate/action",
+}
+
+func (e *scaleSetInstanceAction) RequiredPermissions(state *ScaleSetInstanceChangeState) []common.PermissionRequirement {
+	operation, ok := scaleSetInstanceOperations[state.Action]
+	if !ok {
+		return nil
+	}
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s/virtualMachines/%s",
+			state.SubscriptionId, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID),
+		Operations: []string{operation},
+	}}
+}
+
 func defaultClientProvider(subscriptionId string) (scaleSetInstanceChangeApi, error) {
 	return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
 }
@@ -173,6 +173,11 @@ func (m *azureClientApiMock) BeginDeallocate(ctx context.Context, resourceGroupN
 	return nil, args.Error(1)
 }
 
+func (m *azureClientApiMock) GetInstanceView(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewOptions) (armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse{}, args.Error(1)
+}
+
 func TestAzureScaleSetInstanceAction_ReStart(t *testing.T) {
 	// Given
 	api := new(azureClientApiMock)
@@ -159,11 +159,15 @@ func (d *ssiDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescrip
 }
 
 func (d *ssiDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDScaleSetInstance, d.discoverTargets)
+}
+
+func (d *ssiDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
-	scaleSets, err := getAllScaleSets(ctx, client)
+	scaleSets, err := GetAllScaleSets(ctx, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all scale sets: %w", err)
 	}
@@ -342,7 +346,7 @@ func getKubernetesManagedClusters(ctx context.Context, client common.ArmResource
 		return kubernetesServices, nil
 	}
 }
-func getAllScaleSets(ctx context.Context, client common.ArmResourceGraphApi) ([]ScaleSet, error) {
+func GetAllScaleSets(ctx context.Context, client common.ArmResourceGraphApi) ([]ScaleSet, error) {
 	subscriptionId := os.Getenv("AZURE_SUBSCRIPTION_ID")
 	var subscriptions []*string
 	if subscriptionId != "" {
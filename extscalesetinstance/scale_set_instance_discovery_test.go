@@ -71,7 +71,7 @@ func TestGetAllAzureScaleSets(t *testing.T) {
 	mockedApi.On("Resources", mock.Anything, mock.Anything, mock.Anything).Return(&mockedReturnValue, nil)
 
 	// When
-	scaleSets, err := getAllScaleSets(context.Background(), mockedApi)
+	scaleSets, err := GetAllScaleSets(context.Background(), mockedApi)
 
 	// Then
 	assert.Equal(t, nil, err)
@@ -172,7 +172,7 @@ func TestGetAllError(t *testing.T) {
 	mockedApi.On("Resources", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("expected"))
 
 	// When
-	_, err := getAllScaleSets(context.Background(), mockedApi)
+	_, err := GetAllScaleSets(context.Background(), mockedApi)
 
 	// Then
 	assert.Equal(t, err.Error(), "expected")
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extscalesetinstance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type scaleSetInstanceSpotEvictionAction struct {
+	clientProvider    func(subscriptionId string) (scaleSetInstanceSpotEvictionApi, error)
+	scaleSetsProvider func(subscriptionId string) (scaleSetsApi, error)
+}
+
+var _ action_kit_sdk.Action[ScaleSetInstanceSpotEvictionState] = (*scaleSetInstanceSpotEvictionAction)(nil)
+var _ common.ActionWithRequiredPermissions[ScaleSetInstanceSpotEvictionState] = (*scaleSetInstanceSpotEvictionAction)(nil)
+
+type ScaleSetInstanceSpotEvictionState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	VmScaleSetName    string
+	InstanceID        string
+	ResourceGroupName string
+	DryRun            bool
+}
+
+type scaleSetInstanceSpotEvictionApi interface {
+	SimulateEviction(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionOptions) (armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionResponse, error)
+}
+
+type scaleSetsApi interface {
+	Get(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientGetOptions) (armcompute.VirtualMachineScaleSetsClientGetResponse, error)
+}
+
+func NewScaleSetInstanceSpotEvictionAction() action_kit_sdk.Action[ScaleSetInstanceSpotEvictionState] {
+	return &scaleSetInstanceSpotEvictionAction{
+		clientProvider: func(subscriptionId string) (scaleSetInstanceSpotEvictionApi, error) {
+			return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
+		},
+		scaleSetsProvider: func(subscriptionId string) (scaleSetsApi, error) {
+			return common.GetVirtualMachineScaleSetsClient(subscriptionId)
+		},
+	}
+}
+
+func (e *scaleSetInstanceSpotEvictionAction) NewEmptyState() ScaleSetInstanceSpotEvictionState {
+	return ScaleSetInstanceSpotEvictionState{}
+}
+
+func (e *scaleSetInstanceSpotEvictionAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    ScaleSetInstanceSpotEvictionActionId,
+		Label: "Simulate Spot Eviction",
+		Description: "Simulates the eviction of instances of Azure Spot scale sets. Azure sends the eviction notice through the Scheduled Events API " +
+			"and evicts the instance about 30 seconds later, according to the eviction policy of the scale set.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDScaleSetInstance,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "instance name",
+					Description: new("Find azure scale set instance by name"),
+					Query:       "azure-scale-set-instance.name=\"\"",
+				},
+				{
+					Label:       "scaleset name",
+					Description: new("Find azure scale set instance by scale set name"),
+					Query:       "azure-scale-set.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Virtual Machines"),
+		TimeControl: action_kit_api.TimeControlInstantaneous,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			common.DryRunParameter(),
+		},
+	}
+}
+
+func (e *scaleSetInstanceSpotEvictionAction) Prepare(ctx context.Context, state *ScaleSetInstanceSpotEvictionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	vmScaleSetName := request.Target.Attributes["azure-scale-set.name"]
+	if len(vmScaleSetName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-scaleset.name' attribute.", nil)
+	}
+
+	instanceId := request.Target.Attributes["azure-scale-set-instance.id"]
+	if len(instanceId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-scaleset-instance.id' attribute.", nil)
+	}
+
+	subscriptionId := request.Target.Attributes["azure.subscription.id"]
+	if len(subscriptionId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.subscription.id' attribute.", nil)
+	}
+
+	resourceGroupName := request.Target.Attributes["azure.resource-group.name"]
+	if len(resourceGroupName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.resource-group.name' attribute.", nil)
+	}
+
+	state.SubscriptionId = subscriptionId[0]
+	state.VmScaleSetName = vmScaleSetName[0]
+	state.InstanceID = instanceId[0]
+	state.ResourceGroupName = resourceGroupName[0]
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := e.scaleSetsProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	scaleSet, err := client.Get(ctx, state.ResourceGroupName, state.VmScaleSetName, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get scale set '%s'", state.VmScaleSetName), err)
+	}
+	if !isSpotScaleSet(scaleSet.VirtualMachineScaleSet) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Scale set '%s' does not use Spot priority. Only Spot instances can be evicted.", state.VmScaleSetName), nil)
+	}
+	return nil, nil
+}
+
+func isSpotScaleSet(scaleSet armcompute.VirtualMachineScaleSet) bool {
+	if scaleSet.Properties == nil || scaleSet.Properties.VirtualMachineProfile == nil {
+		return false
+	}
+	priority := scaleSet.Properties.VirtualMachineProfile.Priority
+	return priority != nil && *priority == armcompute.VirtualMachinePriorityTypesSpot
+}
+
+func (e *scaleSetInstanceSpotEvictionAction) RequiredPermissions(state *ScaleSetInstanceSpotEvictionState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s/virtualMachines/%s",
+			state.SubscriptionId, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID),
+		Operations: []string{"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/simulateEviction/action"},
+	}}
+}
+
+func (e *scaleSetInstanceSpotEvictionAction) Start(ctx context.Context, state *ScaleSetInstanceSpotEvictionState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("simulate the eviction of instance '%s' of scale set '%s' in resource group '%s'", state.InstanceID, state.VmScaleSetName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	if _, err := client.SimulateEviction(ctx, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID, nil); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to simulate the eviction of instance '%s' of scale set '%s'", state.InstanceID, state.VmScaleSetName), err)
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Simulated the eviction of instance '%s' of scale set '%s'. Azure evicts it in about 30 seconds.", state.InstanceID, state.VmScaleSetName),
+		}}),
+	}, nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extscalesetinstance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type scaleSetInstanceSpotEvictionApiMock struct {
+	mock.Mock
+}
+
+func (m *scaleSetInstanceSpotEvictionApiMock) SimulateEviction(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionOptions) (armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return armcompute.VirtualMachineScaleSetVMsClientSimulateEvictionResponse{}, args.Error(1)
+}
+
+type scaleSetsApiMock struct {
+	mock.Mock
+}
+
+func (m *scaleSetsApiMock) Get(ctx context.Context, resourceGroupName string, vmScaleSetName string, _ *armcompute.VirtualMachineScaleSetsClientGetOptions) (armcompute.VirtualMachineScaleSetsClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName)
+	return args.Get(0).(armcompute.VirtualMachineScaleSetsClientGetResponse), args.Error(1)
+}
+
+func scaleSetWithPriority(priority armcompute.VirtualMachinePriorityTypes) armcompute.VirtualMachineScaleSetsClientGetResponse {
+	return armcompute.VirtualMachineScaleSetsClientGetResponse{VirtualMachineScaleSet: armcompute.VirtualMachineScaleSet{
+		Properties: &armcompute.VirtualMachineScaleSetProperties{
+			VirtualMachineProfile: &armcompute.VirtualMachineScaleSetVMProfile{Priority: new(priority)},
+		},
+	}}
+}
+
+func TestScaleSetInstanceSpotEvictionAction(t *testing.T) {
+	tests := []struct {
+		name        string
+		priority    armcompute.VirtualMachinePriorityTypes
+		wantedError error
+	}{
+		{
+			name:     "evicts spot instance",
+			priority: armcompute.VirtualMachinePriorityTypesSpot,
+		},
+		{
+			name:        "refuses regular scale set",
+			priority:    armcompute.VirtualMachinePriorityTypesRegular,
+			wantedError: extension_kit.ToError("Scale set 'my-scaleSet' does not use Spot priority. Only Spot instances can be evicted.", nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			scaleSets := new(scaleSetsApiMock)
+			scaleSets.On("Get", mock.Anything, "rg-42", "my-scaleSet").Return(scaleSetWithPriority(tt.priority), nil)
+			api := new(scaleSetInstanceSpotEvictionApiMock)
+			api.On("SimulateEviction", mock.Anything, "rg-42", "my-scaleSet", "3").Return(nil, nil)
+			action := scaleSetInstanceSpotEvictionAction{
+				clientProvider:    func(string) (scaleSetInstanceSpotEvictionApi, error) { return api, nil },
+				scaleSetsProvider: func(string) (scaleSetsApi, error) { return scaleSets, nil },
+			}
+			state := action.NewEmptyState()
+
+			// When
+			_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+				Target: new(action_kit_api.Target{Attributes: map[string][]string{
+					"azure-scale-set.name":        {"my-scaleSet"},
+					"azure-scale-set-instance.id": {"3"},
+					"azure.subscription.id":       {"42"},
+					"azure.resource-group.name":   {"rg-42"},
+				}}),
+			})
+
+			// Then
+			if tt.wantedError != nil {
+				assert.EqualError(t, err, tt.wantedError.Error())
+				return
+			}
+			require.NoError(t, err)
+			_, err = action.Start(context.Background(), &state)
+			require.NoError(t, err)
+			api.AssertCalled(t, "SimulateEviction", mock.Anything, "rg-42", "my-scaleSet", "3")
+		})
+	}
+}
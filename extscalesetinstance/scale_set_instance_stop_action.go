@@ -0,0 +1,229 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extscalesetinstance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type scaleSetInstanceStopAction struct {
+	clientProvider func(subscriptionId string) (scaleSetInstanceStopApi, error)
+}
+
+var _ action_kit_sdk.Action[ScaleSetInstanceStopState] = (*scaleSetInstanceStopAction)(nil)
+var _ action_kit_sdk.ActionWithStop[ScaleSetInstanceStopState] = (*scaleSetInstanceStopAction)(nil)
+var _ common.ActionWithRequiredPermissions[ScaleSetInstanceStopState] = (*scaleSetInstanceStopAction)(nil)
+
+type ScaleSetInstanceStopState struct {
+	common.ExecutionContextState
+	common.InstanceStopState
+
+	SubscriptionId    string
+	VmScaleSetName    string
+	InstanceID        string
+	ResourceGroupName string
+}
+
+type scaleSetInstanceStopApi interface {
+	BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientPowerOffResponse], error)
+	BeginDeallocate(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientDeallocateResponse], error)
+	BeginStart(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientStartResponse], error)
+	GetInstanceView(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewOptions) (armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse, error)
+}
+
+func NewScaleSetInstanceStopAction() action_kit_sdk.ActionWithStop[ScaleSetInstanceStopState] {
+	return &scaleSetInstanceStopAction{
+		clientProvider: func(subscriptionId string) (scaleSetInstanceStopApi, error) {
+			return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
+		},
+	}
+}
+
+func (e *scaleSetInstanceStopAction) NewEmptyState() ScaleSetInstanceStopState {
+	return ScaleSetInstanceStopState{}
+}
+
+func (e *scaleSetInstanceStopAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          ScaleSetInstanceStopActionId,
+		Label:       "Stop Scale Set Instance",
+		Description: "Power off or deallocate Azure scale set instances for a given duration and start them again afterwards",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDScaleSetInstance,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "cluster name",
+					Description: new("Find azure scale set instance by cluster name"),
+					Query:       "azure-containerservice-managed-cluster.name=\"\"",
+				},
+				{
+					Label:       "instance name",
+					Description: new("Find azure scale set instance by name"),
+					Query:       "azure-scale-set-instance.name=\"\"",
+				},
+				{
+					Label:       "scaleset name",
+					Description: new("Find azure scale set instance by scale set name"),
+					Query:       "azure-scale-set.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Virtual Machines"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the scale set instances stay stopped. They are started again on stop."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("60s"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  new("Power off keeps the compute resources allocated, deallocate releases them."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("power-off"),
+				Order:        new(2),
+				Required:     new(true),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Power Off",
+						Value: "power-off",
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Deallocate",
+						Value: "deallocate",
+					},
+				}),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (e *scaleSetInstanceStopAction) Prepare(_ context.Context, state *ScaleSetInstanceStopState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	vmScaleSetName := request.Target.Attributes["azure-scale-set.name"]
+	if len(vmScaleSetName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-scaleset.name' attribute.", nil)
+	}
+
+	instanceId := request.Target.Attributes["azure-scale-set-instance.id"]
+	if len(instanceId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-scaleset-instance.id' attribute.", nil)
+	}
+
+	subscriptionId := request.Target.Attributes["azure.subscription.id"]
+	if len(subscriptionId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.subscription.id' attribute.", nil)
+	}
+
+	resourceGroupName := request.Target.Attributes["azure.resource-group.name"]
+	if len(resourceGroupName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.resource-group.name' attribute.", nil)
+	}
+
+	mode := extutil.ToString(request.Config["mode"])
+	if _, ok := scaleSetInstanceStopOperations[mode]; !ok {
+		return nil, extension_kit.ToError(fmt.Sprintf("Unknown stop mode '%s'", mode), nil)
+	}
+
+	state.SubscriptionId = subscriptionId[0]
+	state.VmScaleSetName = vmScaleSetName[0]
+	state.InstanceID = instanceId[0]
+	state.ResourceGroupName = resourceGroupName[0]
+	state.Mode = mode
+	state.DryRun = common.IsDryRun(request)
+	return nil, nil
+}
+
+func (e *scaleSetInstanceStopAction) Start(ctx context.Context, state *ScaleSetInstanceStopState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("%s instance '%s' of scale set '%s' in resource group '%s'", state.Mode, state.InstanceID, state.VmScaleSetName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	return common.StopInstance(ctx, &stoppableScaleSetInstance{client, state}, &state.InstanceStopState)
+}
+
+func (e *scaleSetInstanceStopAction) Stop(ctx context.Context, state *ScaleSetInstanceStopState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("start instance '%s' of scale set '%s' in resource group '%s'", state.InstanceID, state.VmScaleSetName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	return common.RestartInstance(ctx, &stoppableScaleSetInstance{client, state}, &state.InstanceStopState)
+}
+
+// stoppableScaleSetInstance is the scale set instance of a stop attack.
+type stoppableScaleSetInstance struct {
+	client scaleSetInstanceStopApi
+	state  *ScaleSetInstanceStopState
+}
+
+func (i *stoppableScaleSetInstance) Name() string {
+	return fmt.Sprintf("instance '%s' of scale set '%s'", i.state.InstanceID, i.state.VmScaleSetName)
+}
+
+func (i *stoppableScaleSetInstance) PowerState(ctx context.Context) (string, error) {
+	instanceView, err := i.client.GetInstanceView(ctx, i.state.ResourceGroupName, i.state.VmScaleSetName, i.state.InstanceID, nil)
+	return common.PowerStateOf(instanceView.Statuses), err
+}
+
+func (i *stoppableScaleSetInstance) BeginStop(ctx context.Context, mode string, resumeToken string) (common.Operation, error) {
+	if mode == "deallocate" {
+		return common.NewOperation(i.client.BeginDeallocate(ctx, i.state.ResourceGroupName, i.state.VmScaleSetName, i.state.InstanceID, &armcompute.VirtualMachineScaleSetVMsClientBeginDeallocateOptions{ResumeToken: resumeToken}))
+	}
+	return common.NewOperation(i.client.BeginPowerOff(ctx, i.state.ResourceGroupName, i.state.VmScaleSetName, i.state.InstanceID, &armcompute.VirtualMachineScaleSetVMsClientBeginPowerOffOptions{ResumeToken: resumeToken}))
+}
+
+func (i *stoppableScaleSetInstance) BeginStart(ctx context.Context) (common.Operation, error) {
+	return common.NewOperation(i.client.BeginStart(ctx, i.state.ResourceGroupName, i.state.VmScaleSetName, i.state.InstanceID, nil))
+}
+
+// scaleSetInstanceStopOperations maps each stop mode to the Azure operation it performs.
+var scaleSetInstanceStopOperations = map[string]string{
+	"power-off":  "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/powerOff/action",
+	"deallocate": "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/deallocate/action",
+}
+
+func (e *scaleSetInstanceStopAction) RequiredPermissions(state *ScaleSetInstanceStopState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s/virtualMachines/%s",
+			state.SubscriptionId, state.ResourceGroupName, state.VmScaleSetName, state.InstanceID),
+		Operations: []string{
+			"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/instanceView/read",
+			scaleSetInstanceStopOperations[state.Mode],
+			"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/start/action",
+		},
+	}}
+}
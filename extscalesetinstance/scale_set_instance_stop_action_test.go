@@ -0,0 +1,92 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extscalesetinstance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type scaleSetInstanceStopApiMock struct {
+	mock.Mock
+}
+
+func (m *scaleSetInstanceStopApiMock) BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientPowerOffResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return nil, args.Error(1)
+}
+
+func (m *scaleSetInstanceStopApiMock) BeginDeallocate(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientDeallocateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return nil, args.Error(1)
+}
+
+func (m *scaleSetInstanceStopApiMock) BeginStart(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetVMsClientStartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return nil, args.Error(1)
+}
+
+func (m *scaleSetInstanceStopApiMock) GetInstanceView(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewOptions) (armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return args.Get(0).(armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse), args.Error(1)
+}
+
+func instanceViewWithPowerState(powerState string) armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse {
+	return armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse{
+		VirtualMachineScaleSetVMInstanceView: armcompute.VirtualMachineScaleSetVMInstanceView{
+			Statuses: []*armcompute.InstanceViewStatus{{Code: new("PowerState/" + powerState)}},
+		},
+	}
+}
+
+func TestScaleSetInstanceStopAction_DeallocatesAndStartsInstance(t *testing.T) {
+	// Given
+	api := new(scaleSetInstanceStopApiMock)
+	api.On("GetInstanceView", mock.Anything, "rg-42", "my-scaleSet", "0").Return(instanceViewWithPowerState("running"), nil)
+	api.On("BeginDeallocate", mock.Anything, "rg-42", "my-scaleSet", "0").Return(nil, nil)
+	api.On("BeginStart", mock.Anything, "rg-42", "my-scaleSet", "0").Return(nil, nil)
+	action := scaleSetInstanceStopAction{clientProvider: func(string) (scaleSetInstanceStopApi, error) {
+		return api, nil
+	}}
+	state := &ScaleSetInstanceStopState{SubscriptionId: "42", VmScaleSetName: "my-scaleSet", InstanceID: "0", ResourceGroupName: "rg-42", InstanceStopState: common.InstanceStopState{Mode: "deallocate"}}
+
+	// When
+	_, err := action.Start(context.Background(), state)
+	require.NoError(t, err)
+	result, err := action.Stop(context.Background(), state)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, "The instance '0' of scale set 'my-scaleSet' is running again.", (*result.Messages)[0].Message)
+	api.AssertExpectations(t)
+}
+
+func TestScaleSetInstanceStopAction_SkipsStoppedInstance(t *testing.T) {
+	// Given
+	api := new(scaleSetInstanceStopApiMock)
+	api.On("GetInstanceView", mock.Anything, "rg-42", "my-scaleSet", "0").Return(instanceViewWithPowerState("stopped"), nil)
+	action := scaleSetInstanceStopAction{clientProvider: func(string) (scaleSetInstanceStopApi, error) {
+		return api, nil
+	}}
+	state := &ScaleSetInstanceStopState{SubscriptionId: "42", VmScaleSetName: "my-scaleSet", InstanceID: "0", ResourceGroupName: "rg-42", InstanceStopState: common.InstanceStopState{Mode: "power-off"}}
+
+	// When
+	_, err := action.Start(context.Background(), state)
+	require.NoError(t, err)
+	_, err = action.Stop(context.Background(), state)
+	require.NoError(t, err)
+
+	// Then
+	assert.True(t, state.AlreadyStopped)
+	api.AssertNotCalled(t, "BeginPowerOff", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	api.AssertNotCalled(t, "BeginStart", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
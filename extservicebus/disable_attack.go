@@ -26,11 +26,14 @@ const (
 // EntityDisableState captures the original entity status so we can restore it on stop.
 // Used by both the queue-disable and topic-disable attacks.
 type EntityDisableState struct {
+	common.ExecutionContextState
+
 	SubscriptionId    string
 	ResourceGroupName string
 	NamespaceName     string
 	EntityName        string
 	OriginalStatus    string
+	DryRun            bool
 }
 
 type queuesApi interface {
@@ -51,6 +54,7 @@ type queueDisableAttack struct {
 
 var _ action_kit_sdk.Action[EntityDisableState] = (*queueDisableAttack)(nil)
 var _ action_kit_sdk.ActionWithStop[EntityDisableState] = (*queueDisableAttack)(nil)
+var _ common.ActionWithRequiredPermissions[EntityDisableState] = (*queueDisableAttack)(nil)
 
 func NewQueueDisableAction() action_kit_sdk.ActionWithStop[EntityDisableState] {
 	return &queueDisableAttack{
@@ -59,7 +63,7 @@ func NewQueueDisableAction() action_kit_sdk.ActionWithStop[EntityDisableState] {
 			if err != nil {
 				return nil, err
 			}
-			factory, err := armservicebus.NewClientFactory(subscriptionId, cred, nil)
+			factory, err := armservicebus.NewClientFactory(subscriptionId, cred, common.ArmClientOptions())
 			if err != nil {
 				return nil, err
 			}
@@ -102,6 +106,7 @@ func (a *queueDisableAttack) Describe() action_kit_api.ActionDescription {
 				Order:        new(1),
 				Required:     new(true),
 			},
+			common.DryRunParameter(),
 		},
 		Stop: new(action_kit_api.MutatingEndpointReference{}),
 	}
@@ -128,10 +133,20 @@ func (a *queueDisableAttack) Prepare(ctx context.Context, state *EntityDisableSt
 	} else {
 		state.OriginalStatus = string(armservicebus.EntityStatusActive)
 	}
+	state.DryRun = common.IsDryRun(request)
 	return nil, nil
 }
 
+func (a *queueDisableAttack) RequiredPermissions(state *EntityDisableState) []common.PermissionRequirement {
+	return entityPermissions(state, "queues")
+}
+
 func (a *queueDisableAttack) Start(ctx context.Context, state *EntityDisableState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("set status of Service Bus queue %s/%s to %s (was %s)", state.NamespaceName, state.EntityName, armservicebus.EntityStatusDisabled, state.OriginalStatus)),
+		}, nil
+	}
 	if err := setQueueStatus(ctx, a.clientProvider, state, armservicebus.EntityStatusDisabled); err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to disable Service Bus queue %s/%s", state.NamespaceName, state.EntityName), err)
 	}
@@ -144,6 +159,11 @@ func (a *queueDisableAttack) Start(ctx context.Context, state *EntityDisableStat
 }
 
 func (a *queueDisableAttack) Stop(ctx context.Context, state *EntityDisableState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("restore status of Service Bus queue %s/%s to %s", state.NamespaceName, state.EntityName, state.OriginalStatus)),
+		}, nil
+	}
 	if err := setQueueStatus(ctx, a.clientProvider, state, armservicebus.EntityStatus(state.OriginalStatus)); err != nil {
 		log.Error().Err(err).Msgf("Failed to restore Service Bus queue %s/%s status", state.NamespaceName, state.EntityName)
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to restore Service Bus queue %s/%s status to %s", state.NamespaceName, state.EntityName, state.OriginalStatus), err)
@@ -181,6 +201,7 @@ type topicDisableAttack struct {
 
 var _ action_kit_sdk.Action[EntityDisableState] = (*topicDisableAttack)(nil)
 var _ action_kit_sdk.ActionWithStop[EntityDisableState] = (*topicDisableAttack)(nil)
+var _ common.ActionWithRequiredPermissions[EntityDisableState] = (*topicDisableAttack)(nil)
 
 func NewTopicDisableAction() action_kit_sdk.ActionWithStop[EntityDisableState] {
 	return &topicDisableAttack{
@@ -189,7 +210,7 @@ func NewTopicDisableAction() action_kit_sdk.ActionWithStop[EntityDisableState] {
 			if err != nil {
 				return nil, err
 			}
-			factory, err := armservicebus.NewClientFactory(subscriptionId, cred, nil)
+			factory, err := armservicebus.NewClientFactory(subscriptionId, cred, common.ArmClientOptions())
 			if err != nil {
 				return nil, err
 			}
@@ -232,6 +253,7 @@ func (a *topicDisableAttack) Describe() action_kit_api.ActionDescription {
 				Order:        new(1),
 				Required:     new(true),
 			},
+			common.DryRunParameter(),
 		},
 		Stop: new(action_kit_api.MutatingEndpointReference{}),
 	}
@@ -258,10 +280,20 @@ func (a *topicDisableAttack) Prepare(ctx context.Context, state *EntityDisableSt
 	} else {
 		state.OriginalStatus = string(armservicebus.EntityStatusActive)
 	}
+	state.DryRun = common.IsDryRun(request)
 	return nil, nil
 }
 
+func (a *topicDisableAttack) RequiredPermissions(state *EntityDisableState) []common.PermissionRequirement {
+	return entityPermissions(state, "topics")
+}
+
 func (a *topicDisableAttack) Start(ctx context.Context, state *EntityDisableState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("set status of Service Bus topic %s/%s to %s (was %s)", state.NamespaceName, state.EntityName, armservicebus.EntityStatusDisabled, state.OriginalStatus)),
+		}, nil
+	}
 	if err := setTopicStatus(ctx, a.clientProvider, state, armservicebus.EntityStatusDisabled); err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to disable Service Bus topic %s/%s", state.NamespaceName, state.EntityName), err)
 	}
@@ -274,6 +306,11 @@ func (a *topicDisableAttack) Start(ctx context.Context, state *EntityDisableStat
 }
 
 func (a *topicDisableAttack) Stop(ctx context.Context, state *EntityDisableState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("restore status of Service Bus topic %s/%s to %s", state.NamespaceName, state.EntityName, state.OriginalStatus)),
+		}, nil
+	}
 	if err := setTopicStatus(ctx, a.clientProvider, state, armservicebus.EntityStatus(state.OriginalStatus)); err != nil {
 		log.Error().Err(err).Msgf("Failed to restore Service Bus topic %s/%s status", state.NamespaceName, state.EntityName)
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to restore Service Bus topic %s/%s status to %s", state.NamespaceName, state.EntityName, state.OriginalStatus), err)
@@ -303,6 +340,17 @@ func setTopicStatus(ctx context.Context, provider func(subscriptionId string) (t
 	return err
 }
 
+func entityPermissions(state *EntityDisableState, entityType string) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/%s/%s",
+			state.SubscriptionId, state.ResourceGroupName, state.NamespaceName, entityType, state.EntityName),
+		Operations: []string{
+			fmt.Sprintf("Microsoft.ServiceBus/namespaces/%s/read", entityType),
+			fmt.Sprintf("Microsoft.ServiceBus/namespaces/%s/write", entityType),
+		},
+	}}
+}
+
 func mustHave(attrs map[string][]string, key string) string {
 	v, ok := attrs[key]
 	if !ok || len(v) == 0 {
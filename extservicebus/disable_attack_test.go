@@ -188,6 +188,16 @@ func TestQueue_Start_PropagatesError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestQueue_Start_DryRunDoesNotUpdate(t *testing.T) {
+	client := new(queuesApiMock)
+	a := newQueueAttack(client)
+	state := EntityDisableState{SubscriptionId: "sub-1", ResourceGroupName: "rg-1", NamespaceName: "ns-1", EntityName: "queue-1", OriginalStatus: "Active", DryRun: true}
+	result, err := a.Start(context.Background(), &state)
+	require.NoError(t, err)
+	assert.Equal(t, "[dry run] Would set status of Service Bus queue ns-1/queue-1 to Disabled (was Active)", (*result.Messages)[0].Message)
+	client.AssertNotCalled(t, "CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 // --- topic tests ---
 
 func TestTopic_Prepare_CapturesOriginalStatus(t *testing.T) {
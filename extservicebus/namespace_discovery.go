@@ -83,6 +83,10 @@ func (d *namespaceDiscovery) DescribeAttributes() []discovery_kit_api.AttributeD
 }
 
 func (d *namespaceDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDNamespace, d.discoverTargets)
+}
+
+func (d *namespaceDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
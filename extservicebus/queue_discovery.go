@@ -83,6 +83,10 @@ func (d *queueDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescr
 }
 
 func (d *queueDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDQueue, d.discoverTargets)
+}
+
+func (d *queueDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	rgClient, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Resource Graph client: %w", err)
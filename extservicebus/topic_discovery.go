@@ -77,6 +77,10 @@ func (d *topicDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescr
 }
 
 func (d *topicDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDTopic, d.discoverTargets)
+}
+
+func (d *topicDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	rgClient, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Resource Graph client: %w", err)
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+// Package exttracing sets up OpenTelemetry tracing for discovery cycles, action
+// lifecycle calls and the Azure SDK clients. Spans are exported via OTLP/HTTP
+// to the endpoint configured with STEADYBIT_EXTENSION_TRACING_OTLP_ENDPOINT;
+// without an endpoint the global no-op tracer provider stays in place.
+package exttracing
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
+	"github.com/Azure/azure-sdk-for-go/sdk/tracing/azotel"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-azure/config"
+	"github.com/steadybit/extension-kit/extbuild"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName         = "extension-azure"
+	instrumentationName = "github.com/steadybit/extension-azure"
+)
+
+var (
+	AttributeTargetType    = attribute.Key("steadybit.target.type")
+	AttributeTargetCount   = attribute.Key("steadybit.target.count")
+	AttributeActionId      = attribute.Key("steadybit.action.id")
+	AttributeExperimentKey = attribute.Key("steadybit.experiment.key")
+	AttributeExecutionId   = attribute.Key("steadybit.execution.id")
+)
+
+// Init installs the global tracer provider exporting to the configured OTLP
+// endpoint. The returned function flushes pending spans and must be called on shutdown.
+func Init(ctx context.Context) func(ctx context.Context) error {
+	endpoint := config.Config.TracingOtlpEndpoint
+	if endpoint == "" {
+		return func(context.Context) error { return nil }
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create OTLP trace exporter, tracing is disabled.")
+		return func(context.Context) error { return nil }
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(extbuild.GetSemverVersionStringOrUnknown()),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	log.Info().Msgf("Exporting traces to %s.", endpoint)
+	return provider.Shutdown
+}
+
+// Tracer returns the tracer used for the extension's own spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// AzureTracingProvider bridges the azcore tracing abstraction to OpenTelemetry,
+// so that every Azure SDK call shows up as a child span of the calling discovery or action.
+func AzureTracingProvider() tracing.Provider {
+	return azotel.NewTracingProvider(otel.GetTracerProvider(), nil)
+}
+
+// EndSpan records the error, if any, and ends the span.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -22,12 +22,19 @@ type virtualMachineStateAction struct {
 
 // Make sure lambdaAction implements all required interfaces
 var _ action_kit_sdk.Action[VirtualMachineStateChangeState] = (*virtualMachineStateAction)(nil)
+var _ common.ActionWithRequiredPermissions[VirtualMachineStateChangeState] = (*virtualMachineStateAction)(nil)
+var _ action_kit_sdk.ActionWithStatus[VirtualMachineStateChangeState] = (*virtualMachineStateWaitAction)(nil)
+var _ common.ActionWithRequiredPermissions[VirtualMachineStateChangeState] = (*virtualMachineStateWaitAction)(nil)
 
 type VirtualMachineStateChangeState struct {
+	common.ExecutionContextState
+	common.OperationTracking
+
 	SubscriptionId    string
 	VmName            string
 	ResourceGroupName string
 	Action            string
+	DryRun            bool
 }
 
 type virtualMachineStateChangeApi interface {
@@ -35,6 +42,11 @@ type virtualMachineStateChangeApi interface {
 	BeginDelete(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginDeleteOptions) (*runtime.Poller[armcompute.VirtualMachinesClientDeleteResponse], error)
 	BeginPowerOff(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse], error)
 	BeginDeallocate(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachinesClientDeallocateResponse], error)
+	BeginRedeploy(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginRedeployOptions) (*runtime.Poller[armcompute.VirtualMachinesClientRedeployResponse], error)
+	BeginReimage(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginReimageOptions) (*runtime.Poller[armcompute.VirtualMachinesClientReimageResponse], error)
+	BeginPerformMaintenance(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginPerformMaintenanceOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPerformMaintenanceResponse], error)
+	Get(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientGetOptions) (armcompute.VirtualMachinesClientGetResponse, error)
+	InstanceView(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientInstanceViewOptions) (armcompute.VirtualMachinesClientInstanceViewResponse, error)
 }
 
 func NewVirtualMachineStateAction() action_kit_sdk.Action[VirtualMachineStateChangeState] {
@@ -49,7 +61,7 @@ func (e *virtualMachineStateAction) Describe() action_kit_api.ActionDescription
 	return action_kit_api.ActionDescription{
 		Id:          VirtualMachineStateActionId,
 		Label:       "Change Virtual Machine State",
-		Description: "Restart, stop, deallocate or delete Azure virtual machines",
+		Description: "Restart, stop, deallocate, delete, redeploy, reimage or perform maintenance on Azure virtual machines",
 		Version:     extbuild.GetSemverVersionStringOrUnknown(),
 		Icon:        new(targetIcon),
 		TargetSelection: new(action_kit_api.TargetSelection{
@@ -95,13 +107,26 @@ func (e *virtualMachineStateAction) Describe() action_kit_api.ActionDescription
 						Label: "Deallocate",
 						Value: "deallocate",
 					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Redeploy",
+						Value: "redeploy",
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Reimage (ephemeral OS disk only)",
+						Value: "reimage",
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Perform Maintenance",
+						Value: "perform-maintenance",
+					},
 				}),
 			},
+			common.DryRunParameter(),
 		},
 	}
 }
 
-func (e *virtualMachineStateAction) Prepare(_ context.Context, state *VirtualMachineStateChangeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+func (e *virtualMachineStateAction) Prepare(ctx context.Context, state *VirtualMachineStateChangeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	vmName := request.Target.Attributes["azure-vm.vm.name"]
 	if len(vmName) == 0 {
 		return nil, extension_kit.ToError("Target is missing the 'azure-vm.vm.name' attribute.", nil)
@@ -126,34 +151,179 @@ func (e *virtualMachineStateAction) Prepare(_ context.Context, state *VirtualMac
 	state.VmName = vmName[0]
 	state.ResourceGroupName = resourceGroupName[0]
 	state.Action = action.(string)
+	state.DryRun = common.IsDryRun(request)
+
+	if state.Action == "reimage" || state.Action == "perform-maintenance" {
+		client, err := e.clientProvider(state.SubscriptionId)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+		}
+		if err := validateStateChange(ctx, client, state); err != nil {
+			return nil, err
+		}
+	}
 	return nil, nil
 }
 
+// validateStateChange refuses state changes that Azure would reject for the virtual machine: reimage is only
+// supported for ephemeral OS disks and maintenance can only be performed while a maintenance is pending.
+func validateStateChange(ctx context.Context, client virtualMachineStateChangeApi, state *VirtualMachineStateChangeState) error {
+	switch state.Action {
+	case "reimage":
+		vm, err := client.Get(ctx, state.ResourceGroupName, state.VmName, nil)
+		if err != nil {
+			return extension_kit.ToError(fmt.Sprintf("Failed to get vm '%s'", state.VmName), err)
+		}
+		if !hasEphemeralOsDisk(vm.VirtualMachine) {
+			return extension_kit.ToError(fmt.Sprintf("Virtual machine '%s' has no ephemeral OS disk. Reimage is only supported for virtual machines with an ephemeral OS disk.", state.VmName), nil)
+		}
+	case "perform-maintenance":
+		instanceView, err := client.InstanceView(ctx, state.ResourceGroupName, state.VmName, nil)
+		if err != nil {
+			return extension_kit.ToError(fmt.Sprintf("Failed to get the instance view of vm '%s'", state.VmName), err)
+		}
+		status := instanceView.MaintenanceRedeployStatus
+		if status == nil || status.IsCustomerInitiatedMaintenanceAllowed == nil || !*status.IsCustomerInitiatedMaintenanceAllowed {
+			return extension_kit.ToError(fmt.Sprintf("Virtual machine '%s' has no pending maintenance that can be performed now.", state.VmName), nil)
+		}
+	}
+	return nil
+}
+
+func hasEphemeralOsDisk(vm armcompute.VirtualMachine) bool {
+	if vm.Properties == nil || vm.Properties.StorageProfile == nil || vm.Properties.StorageProfile.OSDisk == nil {
+		return false
+	}
+	settings := vm.Properties.StorageProfile.OSDisk.DiffDiskSettings
+	return settings != nil && settings.Option != nil && *settings.Option == armcompute.DiffDiskOptionsLocal
+}
+
 func (e *virtualMachineStateAction) Start(ctx context.Context, state *VirtualMachineStateChangeState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("%s virtual machine '%s' in resource group '%s'", state.Action, state.VmName, state.ResourceGroupName)),
+		}, nil
+	}
+
 	client, err := e.clientProvider(state.SubscriptionId)
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
 	}
 
-	if state.Action == "restart" {
-		_, err = client.BeginRestart(ctx, state.ResourceGroupName, state.VmName, nil)
-	} else if state.Action == "power-off" {
-		_, err = client.BeginPowerOff(ctx, state.ResourceGroupName, state.VmName, nil)
-	} else if state.Action == "delete" {
-		_, err = client.BeginDelete(ctx, state.ResourceGroupName, state.VmName, nil)
-	} else if state.Action == "deallocate" {
-		_, err = client.BeginDeallocate(ctx, state.ResourceGroupName, state.VmName, nil)
-	} else {
+	if _, ok := virtualMachineOperations[state.Action]; !ok {
 		return nil, extension_kit.ToError(fmt.Sprintf("Unknown state change attack '%s'", state.Action), nil)
 	}
 
+	operation, err := beginStateChange(ctx, client, state, "")
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to execute state change attack '%s' on vm '%s'", state.Action, state.VmName), err)
 	}
 
+	if err := state.Track(ctx, operation); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to track state change attack '%s' on vm '%s'", state.Action, state.VmName), err)
+	}
+
 	return nil, nil
 }
 
+// virtualMachineStateWaitAction changes the state like virtualMachineStateAction and waits in Status until Azure completed the
+// operation. It is a separate action as the time control of an action cannot depend on its parameters.
+type virtualMachineStateWaitAction struct {
+	virtualMachineStateAction
+}
+
+func NewVirtualMachineStateWaitAction() action_kit_sdk.ActionWithStatus[VirtualMachineStateChangeState] {
+	return &virtualMachineStateWaitAction{virtualMachineStateAction{defaultClientProvider}}
+}
+
+func (e *virtualMachineStateWaitAction) Describe() action_kit_api.ActionDescription {
+	description := e.virtualMachineStateAction.Describe()
+	description.Id = VirtualMachineStateWaitActionId
+	description.Label = "Change Virtual Machine State and Wait"
+	description.Description = "Restart, stop, deallocate, delete, redeploy, reimage or perform maintenance on Azure virtual machines and wait until Azure completed the operation"
+	description.TimeControl = action_kit_api.TimeControlInternal
+	description.Parameters = append(description.Parameters, common.CompletionTimeoutParameter())
+	description.Status = new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+		CallInterval: new("10s"),
+	})
+	return description
+}
+
+func (e *virtualMachineStateWaitAction) Prepare(ctx context.Context, state *VirtualMachineStateChangeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	result, err := e.virtualMachineStateAction.Prepare(ctx, state, request)
+	if err != nil {
+		return nil, err
+	}
+	state.PrepareTracking(request)
+	return result, nil
+}
+
+func (e *virtualMachineStateWaitAction) Status(ctx context.Context, state *VirtualMachineStateChangeState) (*action_kit_api.StatusResult, error) {
+	if !state.IsTracking() {
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+
+	var messages []action_kit_api.Message
+	if instanceView, err := client.InstanceView(ctx, state.ResourceGroupName, state.VmName, nil); err == nil {
+		messages = state.ObservePowerState(fmt.Sprintf("power state of virtual machine '%s'", state.VmName), common.PowerStateOf(instanceView.Statuses))
+	}
+
+	operation, err := beginStateChange(ctx, client, state, state.ResumeToken)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to resume state change attack '%s' on vm '%s'", state.Action, state.VmName), err)
+	}
+	return state.PollTracked(ctx, operation, fmt.Sprintf("%s of virtual machine '%s'", state.Action, state.VmName), messages), nil
+}
+
+// beginStateChange starts the state change, or resumes it if a resume token is given.
+func beginStateChange(ctx context.Context, client virtualMachineStateChangeApi, state *VirtualMachineStateChangeState, resumeToken string) (common.Operation, error) {
+	switch state.Action {
+	case "restart":
+		return common.NewOperation(client.BeginRestart(ctx, state.ResourceGroupName, state.VmName, &armcompute.VirtualMachinesClientBeginRestartOptions{ResumeToken: resumeToken}))
+	case "power-off":
+		return common.NewOperation(client.BeginPowerOff(ctx, state.ResourceGroupName, state.VmName, &armcompute.VirtualMachinesClientBeginPowerOffOptions{ResumeToken: resumeToken}))
+	case "delete":
+		return common.NewOperation(client.BeginDelete(ctx, state.ResourceGroupName, state.VmName, &armcompute.VirtualMachinesClientBeginDeleteOptions{ResumeToken: resumeToken}))
+	case "deallocate":
+		return common.NewOperation(client.BeginDeallocate(ctx, state.ResourceGroupName, state.VmName, &armcompute.VirtualMachinesClientBeginDeallocateOptions{ResumeToken: resumeToken}))
+	case "redeploy":
+		return common.NewOperation(client.BeginRedeploy(ctx, state.ResourceGroupName, state.VmName, &armcompute.VirtualMachinesClientBeginRedeployOptions{ResumeToken: resumeToken}))
+	case "reimage":
+		return common.NewOperation(client.BeginReimage(ctx, state.ResourceGroupName, state.VmName, &armcompute.VirtualMachinesClientBeginReimageOptions{ResumeToken: resumeToken}))
+	case "perform-maintenance":
+		return common.NewOperation(client.BeginPerformMaintenance(ctx, state.ResourceGroupName, state.VmName, &armcompute.VirtualMachinesClientBeginPerformMaintenanceOptions{ResumeToken: resumeToken}))
+	default:
+		return nil, fmt.Errorf("unknown state change '%s'", state.Action)
+	}
+}
+
+// virtualMachineOperations maps each state change to the Azure operation it performs.
+var virtualMachineOperations = map[string]string{
+	"restart":             "Microsoft.Compute/virtualMachines/restart/action",
+	"power-off":           "Microsoft.Compute/virtualMachines/powerOff/action",
+	"delete":              "Microsoft.Compute/virtualMachines/delete",
+	"deallocate":          "Microsoft.Compute/virtualMachines/deallocate/action",
+	"redeploy":            "Microsoft.Compute/virtualMachines/redeploy/action",
+	"reimage":             "Microsoft.Compute/virtualMachines/reimage/action",
+	"perform-maintenance": "Microsoft.Compute/virtualMachines/performMaintenance/action",
+}
+
+func (e *virtualMachineStateAction) RequiredPermissions(state *VirtualMachineStateChangeState) []common.PermissionRequirement {
+	operation, ok := virtualMachineOperations[state.Action]
+	if !ok {
+		return nil
+	}
+	return []common.PermissionRequirement{{
+		Scope:      fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s", state.SubscriptionId, state.ResourceGroupName, state.VmName),
+		Operations: []string{operation},
+	}}
+}
+
 func defaultClientProvider(subscriptionId string) (virtualMachineStateChangeApi, error) {
 	return common.GetVirtualMachinesClient(subscriptionId)
 }
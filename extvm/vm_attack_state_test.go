@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestAzureVirtualMachineStateAction_Prepare(t *testing.T) {
@@ -152,6 +153,34 @@ func (m *azureClientApiMock) BeginDeallocate(ctx context.Context, resourceGroupN
 	return nil, args.Error(1)
 }
 
+func (m *azureClientApiMock) BeginRedeploy(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginRedeployOptions) (*runtime.Poller[armcompute.VirtualMachinesClientRedeployResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *azureClientApiMock) BeginReimage(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginReimageOptions) (*runtime.Poller[armcompute.VirtualMachinesClientReimageResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *azureClientApiMock) BeginPerformMaintenance(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginPerformMaintenanceOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPerformMaintenanceResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *azureClientApiMock) Get(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientGetOptions) (armcompute.VirtualMachinesClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return args.Get(0).(armcompute.VirtualMachinesClientGetResponse), args.Error(1)
+}
+
+func (m *azureClientApiMock) InstanceView(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientInstanceViewOptions) (armcompute.VirtualMachinesClientInstanceViewResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	if response, ok := args.Get(0).(armcompute.VirtualMachinesClientInstanceViewResponse); ok {
+		return response, args.Error(1)
+	}
+	return armcompute.VirtualMachinesClientInstanceViewResponse{}, args.Error(1)
+}
+
 func TestAzureVirtualMachineStateAction_ReStart(t *testing.T) {
 	// Given
 	api := new(azureClientApiMock)
@@ -300,3 +329,205 @@ func TestStartVirtualMachineStateChangeForwardsError(t *testing.T) {
 
 	api.AssertExpectations(t)
 }
+
+func TestAzureVirtualMachineStateAction_DryRun(t *testing.T) {
+	// Given
+	api := new(azureClientApiMock)
+	action := virtualMachineStateAction{clientProvider: func(account string) (virtualMachineStateChangeApi, error) {
+		return api, nil
+	}}
+
+	// When
+	result, err := action.Start(context.Background(), &VirtualMachineStateChangeState{
+		SubscriptionId:    "42",
+		VmName:            "my-vm",
+		ResourceGroupName: "rg-42",
+		Action:            "delete",
+		DryRun:            true,
+	})
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, *result.Messages, 1)
+	assert.Equal(t, "[dry run] Would delete virtual machine 'my-vm' in resource group 'rg-42'", (*result.Messages)[0].Message)
+	api.AssertNotCalled(t, "BeginDelete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAzureVirtualMachineStateAction_StatusWithoutWaiting(t *testing.T) {
+	// Given
+	api := new(azureClientApiMock)
+	action := virtualMachineStateWaitAction{virtualMachineStateAction{clientProvider: func(account string) (virtualMachineStateChangeApi, error) {
+		return api, nil
+	}}}
+
+	// When
+	result, err := action.Status(context.Background(), &VirtualMachineStateChangeState{
+		SubscriptionId:    "42",
+		VmName:            "my-vm",
+		ResourceGroupName: "rg-42",
+		Action:            "restart",
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.True(t, result.Completed)
+	api.AssertNotCalled(t, "BeginRestart", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAzureVirtualMachineStateWaitAction_DescribeAndPrepare(t *testing.T) {
+	// Given
+	action := NewVirtualMachineStateWaitAction()
+	state := action.NewEmptyState()
+
+	// When
+	_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"action": "restart"},
+		Target: new(action_kit_api.Target{Attributes: map[string][]string{
+			"azure-vm.vm.name":          {"my-vm"},
+			"azure.subscription.id":     {"42"},
+			"azure.resource-group.name": {"rg-42"},
+		}}),
+	})
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, state.CompletionTimeout)
+	assert.Equal(t, action_kit_api.TimeControlInternal, action.Describe().TimeControl)
+	assert.Equal(t, action_kit_api.TimeControlInstantaneous, NewVirtualMachineStateAction().Describe().TimeControl)
+}
+
+func TestAzureVirtualMachineStateAction_StatusForwardsResumeError(t *testing.T) {
+	// Given
+	api := new(azureClientApiMock)
+	api.On("InstanceView", mock.Anything, "rg-42", "my-vm").Return(armcompute.VirtualMachinesClientInstanceViewResponse{
+		VirtualMachineInstanceView: armcompute.VirtualMachineInstanceView{
+			Statuses: []*armcompute.InstanceViewStatus{{Code: new("PowerState/stopping")}},
+		},
+	}, nil)
+	api.On("BeginRestart", mock.Anything, "rg-42", "my-vm").Return(nil, errors.New("invalid resume token"))
+	action := virtualMachineStateWaitAction{virtualMachineStateAction{clientProvider: func(account string) (virtualMachineStateChangeApi, error) {
+		return api, nil
+	}}}
+	state := &VirtualMachineStateChangeState{
+		SubscriptionId:    "42",
+		VmName:            "my-vm",
+		ResourceGroupName: "rg-42",
+		Action:            "restart",
+	}
+	state.CompletionTimeout = time.Minute
+	state.ResumeToken = "token"
+
+	// When
+	_, err := action.Status(context.Background(), state)
+
+	// Then
+	assert.EqualError(t, err, extension_kit.ToError("Failed to resume state change attack 'restart' on vm 'my-vm'", errors.New("invalid resume token")).Error())
+	assert.Equal(t, "stopping", state.PowerState)
+	api.AssertExpectations(t)
+}
+
+func TestAzureVirtualMachineStateAction_PrepareValidatesStateChange(t *testing.T) {
+	ephemeral := armcompute.VirtualMachinesClientGetResponse{VirtualMachine: armcompute.VirtualMachine{
+		Properties: &armcompute.VirtualMachineProperties{StorageProfile: &armcompute.StorageProfile{OSDisk: &armcompute.OSDisk{
+			DiffDiskSettings: &armcompute.DiffDiskSettings{Option: new(armcompute.DiffDiskOptionsLocal)},
+		}}},
+	}}
+	managed := armcompute.VirtualMachinesClientGetResponse{VirtualMachine: armcompute.VirtualMachine{
+		Properties: &armcompute.VirtualMachineProperties{StorageProfile: &armcompute.StorageProfile{OSDisk: &armcompute.OSDisk{}}},
+	}}
+	maintenance := func(allowed bool) armcompute.VirtualMachinesClientInstanceViewResponse {
+		return armcompute.VirtualMachinesClientInstanceViewResponse{VirtualMachineInstanceView: armcompute.VirtualMachineInstanceView{
+			MaintenanceRedeployStatus: &armcompute.MaintenanceRedeployStatus{IsCustomerInitiatedMaintenanceAllowed: new(allowed)},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		action      string
+		setup       func(api *azureClientApiMock)
+		wantedError error
+	}{
+		{
+			name:   "reimage with ephemeral OS disk",
+			action: "reimage",
+			setup:  func(api *azureClientApiMock) { api.On("Get", mock.Anything, "rg-42", "my-vm").Return(ephemeral, nil) },
+		},
+		{
+			name:        "reimage without ephemeral OS disk",
+			action:      "reimage",
+			setup:       func(api *azureClientApiMock) { api.On("Get", mock.Anything, "rg-42", "my-vm").Return(managed, nil) },
+			wantedError: extension_kit.ToError("Virtual machine 'my-vm' has no ephemeral OS disk. Reimage is only supported for virtual machines with an ephemeral OS disk.", nil),
+		},
+		{
+			name:   "perform maintenance while allowed",
+			action: "perform-maintenance",
+			setup: func(api *azureClientApiMock) {
+				api.On("InstanceView", mock.Anything, "rg-42", "my-vm").Return(maintenance(true), nil)
+			},
+		},
+		{
+			name:   "perform maintenance without pending maintenance",
+			action: "perform-maintenance",
+			setup: func(api *azureClientApiMock) {
+				api.On("InstanceView", mock.Anything, "rg-42", "my-vm").Return(maintenance(false), nil)
+			},
+			wantedError: extension_kit.ToError("Virtual machine 'my-vm' has no pending maintenance that can be performed now.", nil),
+		},
+		{
+			name:   "redeploy needs no validation",
+			action: "redeploy",
+			setup:  func(api *azureClientApiMock) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			api := new(azureClientApiMock)
+			tt.setup(api)
+			action := virtualMachineStateAction{clientProvider: func(account string) (virtualMachineStateChangeApi, error) {
+				return api, nil
+			}}
+			state := action.NewEmptyState()
+
+			// When
+			_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+				Config: map[string]any{"action": tt.action},
+				Target: new(action_kit_api.Target{Attributes: map[string][]string{
+					"azure-vm.vm.name":          {"my-vm"},
+					"azure.subscription.id":     {"42"},
+					"azure.resource-group.name": {"rg-42"},
+				}}),
+			})
+
+			// Then
+			if tt.wantedError != nil {
+				assert.EqualError(t, err, tt.wantedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			api.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAzureVirtualMachineStateAction_Redeploy(t *testing.T) {
+	// Given
+	api := new(azureClientApiMock)
+	api.On("BeginRedeploy", mock.Anything, "rg-42", "my-vm").Return(nil, nil)
+	action := virtualMachineStateAction{clientProvider: func(account string) (virtualMachineStateChangeApi, error) {
+		return api, nil
+	}}
+
+	// When
+	_, err := action.Start(context.Background(), &VirtualMachineStateChangeState{
+		SubscriptionId:    "42",
+		VmName:            "my-vm",
+		ResourceGroupName: "rg-42",
+		Action:            "redeploy",
+	})
+
+	// Then
+	assert.NoError(t, err)
+	api.AssertExpectations(t)
+}
@@ -7,6 +7,7 @@ package extvm
 import (
 	"context"
 	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
 	"github.com/rs/zerolog/log"
@@ -18,6 +19,8 @@ import (
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -101,6 +104,125 @@ func (d *vmDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescript
 				Other: "Network IDs",
 			},
 		},
+		{
+			Attribute: "azure-vm.network.private-ip",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Private IP",
+				Other: "Private IPs",
+			},
+		},
+		{
+			Attribute: "azure-vm.network.public-ip",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Public IP",
+				Other: "Public IPs",
+			},
+		},
+		{
+			Attribute: "azure-vm.network.subnet.id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Subnet ID",
+				Other: "Subnet IDs",
+			},
+		},
+		{
+			Attribute: "azure-vm.network.vnet.name",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Virtual network name",
+				Other: "Virtual network names",
+			},
+		},
+		{
+			Attribute: "azure-vm.network.nsg.id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Network security group ID",
+				Other: "Network security group IDs",
+			},
+		},
+		{
+			Attribute: "azure-vm.network.nsg.name",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Network security group name",
+				Other: "Network security group names",
+			},
+		},
+		{
+			Attribute: "azure.zone",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Availability zone",
+				Other: "Availability zones",
+			},
+		},
+		{
+			Attribute: "azure-vm.availability-set.id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Availability set ID",
+				Other: "Availability set IDs",
+			},
+		},
+		{
+			Attribute: "azure-vm.priority",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Priority",
+				Other: "Priorities",
+			},
+		},
+		{
+			Attribute: "azure-vm.image.publisher",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Image publisher",
+				Other: "Image publishers",
+			},
+		},
+		{
+			Attribute: "azure-vm.image.offer",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Image offer",
+				Other: "Image offers",
+			},
+		},
+		{
+			Attribute: "azure-vm.image.sku",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Image SKU",
+				Other: "Image SKUs",
+			},
+		},
+		{
+			Attribute: "azure-vm.image.version",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Image version",
+				Other: "Image versions",
+			},
+		},
+		{
+			Attribute: "azure-vm.image.id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Image ID",
+				Other: "Image IDs",
+			},
+		},
+		{
+			Attribute: "azure-vm.identity.type",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Managed identity type",
+				Other: "Managed identity types",
+			},
+		},
+		{
+			Attribute: "azure-vm.identity.principal-id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Managed identity principal ID",
+				Other: "Managed identity principal IDs",
+			},
+		},
+		{
+			Attribute: "azure-vm.identity.user-assigned-id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "User-assigned identity ID",
+				Other: "User-assigned identity IDs",
+			},
+		},
 		{
 			Attribute: "azure-vm.os.name",
 			Label: discovery_kit_api.PluralLabel{
@@ -175,18 +297,22 @@ func (d *vmDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescript
 }
 
 func (d *vmDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDVM, d.discoverTargets)
+}
+
+func (d *vmDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
-	targets, err := getAllVirtualMachines(ctx, client)
+	targets, err := GetAllVirtualMachines(ctx, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all virtual machines: %w", err)
 	}
 	return targets, nil
 }
 
-func getAllVirtualMachines(ctx context.Context, client common.ArmResourceGraphApi) ([]discovery_kit_api.Target, error) {
+func GetAllVirtualMachines(ctx context.Context, client common.ArmResourceGraphApi) ([]discovery_kit_api.Target, error) {
 	subscriptionId := os.Getenv("AZURE_SUBSCRIPTION_ID")
 	var subscriptions []*string
 	if subscriptionId != "" {
@@ -194,7 +320,7 @@ func getAllVirtualMachines(ctx context.Context, client common.ArmResourceGraphAp
 	}
 	results, err := client.Resources(ctx,
 		armresourcegraph.QueryRequest{
-			Query: new("Resources | where type =~ 'Microsoft.Compute/virtualMachines' | project name, type, resourceGroup, location, tags, properties, subscriptionId"),
+			Query: new(virtualMachinesQuery),
 			Options: &armresourcegraph.QueryRequestOptions{
 				ResultFormat: to.Ptr(armresourcegraph.ResultFormatObjectArray),
 			},
@@ -215,8 +341,6 @@ func getAllVirtualMachines(ctx context.Context, client common.ArmResourceGraphAp
 
 				properties := common.GetMapValue(items, "properties")
 				extended := common.GetMapValue(properties, "extended")
-				networkProfile := common.GetMapValue(properties, "networkProfile")
-				networkInterfaces := common.GetMapValue(networkProfile, "networkInterfaces")
 				instanceView := common.GetMapValue(extended, "instanceView")
 				hardwareProfile := common.GetMapValue(properties, "hardwareProfile")
 				powerState := common.GetMapValue(instanceView, "powerState")
@@ -232,9 +356,10 @@ func getAllVirtualMachines(ctx context.Context, client common.ArmResourceGraphAp
 				attributes["azure-vm.os.version"] = []string{getPropertyValue(instanceView, "osVersion")}
 				attributes["azure-vm.os.type"] = []string{getPropertyValue(osDisk, "osType")}
 				attributes["azure-vm.power.state"] = []string{getPropertyValue(powerState, "code")}
-				attributes["azure-vm.network.id"] = []string{getPropertyValue(networkInterfaces, "id")}
 				attributes["azure.location"] = []string{getPropertyValue(items, "location")}
 				attributes["azure.resource-group.name"] = []string{getPropertyValue(items, "resourceGroup")}
+				addNetworkAttributes(attributes, items)
+				addPlacementAttributes(attributes, items, properties)
 
 				for k, v := range common.GetMapValue(items, "tags") {
 					attributes[fmt.Sprintf("azure-vm.label.%s", strings.ToLower(k))] = []string{extutil.ToString(v)}
@@ -252,6 +377,118 @@ func getAllVirtualMachines(ctx context.Context, client common.ArmResourceGraphAp
 	}
 }
 
+// virtualMachinesQuery joins the network interfaces of each virtual machine with their public IP addresses and the
+// subnets they are attached to, so that the network attributes need no further calls per virtual machine.
+const virtualMachinesQuery = `Resources
+| where type =~ 'Microsoft.Compute/virtualMachines'
+| extend vmKey = tolower(id)
+| join kind=leftouter (
+    Resources
+    | where type =~ 'Microsoft.Network/networkInterfaces'
+    | extend vmKey = tolower(tostring(properties.virtualMachine.id)), nicNsgId = tostring(properties.networkSecurityGroup.id)
+    | mv-expand ipConfiguration = properties.ipConfigurations
+    | extend privateIp = tostring(ipConfiguration.properties.privateIPAddress), subnetId = tostring(ipConfiguration.properties.subnet.id), publicIpKey = tolower(tostring(ipConfiguration.properties.publicIPAddress.id))
+    | extend subnetKey = tolower(subnetId)
+    | join kind=leftouter (
+        Resources
+        | where type =~ 'Microsoft.Network/publicIPAddresses'
+        | project publicIpKey = tolower(id), publicIp = tostring(properties.ipAddress)
+    ) on publicIpKey
+    | join kind=leftouter (
+        Resources
+        | where type =~ 'Microsoft.Network/virtualNetworks'
+        | mv-expand subnet = properties.subnets
+        | project subnetKey = tolower(tostring(subnet.id)), subnetNsgId = tostring(subnet.properties.networkSecurityGroup.id)
+    ) on subnetKey
+    | summarize nicIds = make_set(id), privateIps = make_set(privateIp), publicIps = make_set(publicIp), subnetIds = make_set(subnetId), nsgIds = make_set(nicNsgId), subnetNsgIds = make_set(subnetNsgId) by vmKey
+) on vmKey
+| project name, type, id, resourceGroup, location, tags, zones, identity, properties, subscriptionId, nicIds, privateIps, publicIps, subnetIds, nsgIds, subnetNsgIds`
+
+// addNetworkAttributes adds the joined network interfaces, IP addresses, subnets, virtual networks and network
+// security groups. Without a join result all network interfaces of the network profile are used.
+func addNetworkAttributes(attributes map[string][]string, items map[string]any) {
+	nicIds := common.StringSliceFromMap(items, "nicIds")
+	if len(nicIds) == 0 {
+		networkProfile := common.GetMapValue(common.GetMapValue(items, "properties"), "networkProfile")
+		if references, ok := networkProfile["networkInterfaces"].([]any); ok {
+			for _, reference := range references {
+				if reference, ok := reference.(map[string]any); ok && common.StringFromMap(reference, "id") != "" {
+					nicIds = append(nicIds, common.StringFromMap(reference, "id"))
+				}
+			}
+		}
+	}
+	setSortedAttribute(attributes, "azure-vm.network.id", nicIds)
+	setSortedAttribute(attributes, "azure-vm.network.private-ip", common.StringSliceFromMap(items, "privateIps"))
+	setSortedAttribute(attributes, "azure-vm.network.public-ip", common.StringSliceFromMap(items, "publicIps"))
+
+	subnetIds := common.StringSliceFromMap(items, "subnetIds")
+	setSortedAttribute(attributes, "azure-vm.network.subnet.id", subnetIds)
+	var vnetNames []string
+	for _, subnetId := range subnetIds {
+		if subnet, err := arm.ParseResourceID(subnetId); err == nil && subnet.Parent != nil {
+			vnetNames = append(vnetNames, subnet.Parent.Name)
+		}
+	}
+	setSortedAttribute(attributes, "azure-vm.network.vnet.name", vnetNames)
+
+	nsgIds := append(common.StringSliceFromMap(items, "nsgIds"), common.StringSliceFromMap(items, "subnetNsgIds")...)
+	setSortedAttribute(attributes, "azure-vm.network.nsg.id", nsgIds)
+	var nsgNames []string
+	for _, nsgId := range nsgIds {
+		if nsg, err := arm.ParseResourceID(nsgId); err == nil {
+			nsgNames = append(nsgNames, nsg.Name)
+		}
+	}
+	setSortedAttribute(attributes, "azure-vm.network.nsg.name", nsgNames)
+}
+
+// addPlacementAttributes adds the availability zone, availability set, priority, image and managed identity.
+func addPlacementAttributes(attributes map[string][]string, items map[string]any, properties map[string]any) {
+	setSortedAttribute(attributes, "azure.zone", common.StringSliceFromMap(items, "zones"))
+	if v := common.StringFromMap(common.GetMapValue(properties, "availabilitySet"), "id"); v != "" {
+		attributes["azure-vm.availability-set.id"] = []string{v}
+	}
+	if v := common.StringFromMap(properties, "priority"); v != "" {
+		attributes["azure-vm.priority"] = []string{v}
+	}
+
+	imageReference := common.GetMapValue(common.GetMapValue(properties, "storageProfile"), "imageReference")
+	for _, key := range []string{"publisher", "offer", "sku", "version", "id"} {
+		if v := common.StringFromMap(imageReference, key); v != "" {
+			attributes["azure-vm.image."+key] = []string{v}
+		}
+	}
+
+	identity := common.GetMapValue(items, "identity")
+	if v := common.StringFromMap(identity, "type"); v != "" {
+		attributes["azure-vm.identity.type"] = []string{v}
+	}
+	if v := common.StringFromMap(identity, "principalId"); v != "" {
+		attributes["azure-vm.identity.principal-id"] = []string{v}
+	}
+	userAssigned := make([]string, 0)
+	for id := range common.GetMapValue(identity, "userAssignedIdentities") {
+		userAssigned = append(userAssigned, id)
+	}
+	setSortedAttribute(attributes, "azure-vm.identity.user-assigned-id", userAssigned)
+}
+
+// setSortedAttribute sets the distinct values in a stable order, or nothing if there are none.
+func setSortedAttribute(attributes map[string][]string, key string, values []string) {
+	distinct := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" && !slices.Contains(distinct, value) {
+			distinct = append(distinct, value)
+		}
+	}
+	if len(distinct) == 0 {
+		return
+	}
+	sort.Strings(distinct)
+	attributes[key] = distinct
+}
+
 func (d *vmDiscovery) DescribeEnrichmentRules() []discovery_kit_api.TargetEnrichmentRule {
 	return []discovery_kit_api.TargetEnrichmentRule{
 		getToHostEnrichmentRule(),
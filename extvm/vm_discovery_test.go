@@ -36,11 +36,29 @@ func TestGetAllAzureVirtualMachines(t *testing.T) {
 					"location":       "westeurope",
 					"subscriptionId": "42",
 					"resourceGroup":  "rg-1",
+					"zones":          []any{"2"},
+					"identity": map[string]any{
+						"type":        "SystemAssigned, UserAssigned",
+						"principalId": "principal-1",
+						"userAssignedIdentities": map[string]any{
+							"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id-1": map[string]any{},
+						},
+					},
+					"nicIds":       []any{"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/networkInterfaces/nic-2", "/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/networkInterfaces/nic-1"},
+					"privateIps":   []any{"10.0.0.4", "10.0.1.4"},
+					"publicIps":    []any{"20.1.2.3", ""},
+					"subnetIds":    []any{"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/virtualNetworks/vnet-1/subnets/default"},
+					"nsgIds":       []any{""},
+					"subnetNsgIds": []any{"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/networkSecurityGroups/nsg-1"},
 					"tags": map[string]any{
 						"tag1": "Value1",
 						"tag2": "Value2",
 					},
 					"properties": map[string]any{
+						"priority": "Spot",
+						"availabilitySet": map[string]any{
+							"id": "/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Compute/availabilitySets/as-1",
+						},
 						"hardwareProfile": map[string]any{
 							"vmSize": "Standard_D2s_v3",
 						},
@@ -61,6 +79,12 @@ func TestGetAllAzureVirtualMachines(t *testing.T) {
 							"osDisk": map[string]any{
 								"osType": "Linux",
 							},
+							"imageReference": map[string]any{
+								"publisher": "Canonical",
+								"offer":     "UbuntuServer",
+								"sku":       "18.04-LTS",
+								"version":   "latest",
+							},
 						},
 						"osProfile": map[string]any{
 							"computerName":  "dev-demo-ngroup2",
@@ -85,7 +109,7 @@ func TestGetAllAzureVirtualMachines(t *testing.T) {
 	config.Config.DiscoveryAttributesExcludesVM = []string{"azure-vm.label.tag1"}
 
 	// When
-	targets, err := getAllVirtualMachines(context.Background(), mockedApi)
+	targets, err := GetAllVirtualMachines(context.Background(), mockedApi)
 
 	// Then
 	assert.Equal(t, nil, err)
@@ -103,7 +127,22 @@ func TestGetAllAzureVirtualMachines(t *testing.T) {
 	assert.Equal(t, []string{"18.04.5 LTS"}, target.Attributes["azure-vm.os.version"])
 	assert.Equal(t, []string{"Linux"}, target.Attributes["azure-vm.os.type"])
 	assert.Equal(t, []string{"PowerState/running"}, target.Attributes["azure-vm.power.state"])
-	assert.Equal(t, []string{"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/networkInterfaces/i-0ef9adc9fbd3b19c5"}, target.Attributes["azure-vm.network.id"])
+	assert.Equal(t, []string{
+		"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/networkInterfaces/nic-1",
+		"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/networkInterfaces/nic-2",
+	}, target.Attributes["azure-vm.network.id"])
+	assert.Equal(t, []string{"10.0.0.4", "10.0.1.4"}, target.Attributes["azure-vm.network.private-ip"])
+	assert.Equal(t, []string{"20.1.2.3"}, target.Attributes["azure-vm.network.public-ip"])
+	assert.Equal(t, []string{"vnet-1"}, target.Attributes["azure-vm.network.vnet.name"])
+	assert.Equal(t, []string{"nsg-1"}, target.Attributes["azure-vm.network.nsg.name"])
+	assert.Equal(t, []string{"2"}, target.Attributes["azure.zone"])
+	assert.Equal(t, []string{"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Compute/availabilitySets/as-1"}, target.Attributes["azure-vm.availability-set.id"])
+	assert.Equal(t, []string{"Spot"}, target.Attributes["azure-vm.priority"])
+	assert.Equal(t, []string{"Canonical"}, target.Attributes["azure-vm.image.publisher"])
+	assert.Equal(t, []string{"18.04-LTS"}, target.Attributes["azure-vm.image.sku"])
+	assert.NotContains(t, target.Attributes, "azure-vm.image.id")
+	assert.Equal(t, []string{"SystemAssigned, UserAssigned"}, target.Attributes["azure-vm.identity.type"])
+	assert.Equal(t, []string{"/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id-1"}, target.Attributes["azure-vm.identity.user-assigned-id"])
 	assert.Equal(t, []string{"westeurope"}, target.Attributes["azure.location"])
 	assert.Equal(t, []string{"rg-1"}, target.Attributes["azure.resource-group.name"])
 	assert.Equal(t, []string{"Value2"}, target.Attributes["azure-vm.label.tag2"])
@@ -112,6 +151,41 @@ func TestGetAllAzureVirtualMachines(t *testing.T) {
 	assert.False(t, present)
 }
 
+func TestGetAllAzureVirtualMachines_UsesNetworkProfileWithoutJoinResult(t *testing.T) {
+	// Given
+	mockedApi := new(azureResourceGraphClientMock)
+	mockedApi.On("Resources", mock.Anything, mock.Anything, mock.Anything).Return(&armresourcegraph.ClientResourcesResponse{
+		QueryResponse: armresourcegraph.QueryResponse{
+			TotalRecords: new(int64(1)),
+			Data: []any{
+				map[string]any{
+					"name":           "myVm",
+					"subscriptionId": "42",
+					"nicIds":         []any{},
+					"properties": map[string]any{
+						"vmId": "vm-1",
+						"networkProfile": map[string]any{
+							"networkInterfaces": []any{
+								map[string]any{"id": "/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/networkInterfaces/nic-1"},
+								map[string]any{"id": "/subscriptions/42/resourceGroups/rg-1/providers/Microsoft.Network/networkInterfaces/nic-2"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil)
+
+	// When
+	targets, err := GetAllVirtualMachines(context.Background(), mockedApi)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, targets[0].Attributes["azure-vm.network.id"], 2)
+	assert.NotContains(t, targets[0].Attributes, "azure.zone")
+	assert.NotContains(t, targets[0].Attributes, "azure-vm.network.public-ip")
+}
+
 func TestGetAllError(t *testing.T) {
 	// Given
 	mockedApi := new(azureResourceGraphClientMock)
@@ -119,7 +193,7 @@ func TestGetAllError(t *testing.T) {
 	mockedApi.On("Resources", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("expected"))
 
 	// When
-	_, err := getAllVirtualMachines(context.Background(), mockedApi)
+	_, err := GetAllVirtualMachines(context.Background(), mockedApi)
 
 	// Then
 	assert.Equal(t, err.Error(), "expected")
@@ -0,0 +1,346 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type virtualMachineResizeAction struct {
+	clientProvider func(subscriptionId string) (virtualMachineResizeApi, error)
+	sizesProvider  func(subscriptionId string) (virtualMachineSizesApi, error)
+}
+
+var _ action_kit_sdk.Action[VirtualMachineResizeState] = (*virtualMachineResizeAction)(nil)
+var _ action_kit_sdk.ActionWithStop[VirtualMachineResizeState] = (*virtualMachineResizeAction)(nil)
+var _ common.ActionWithRequiredPermissions[VirtualMachineResizeState] = (*virtualMachineResizeAction)(nil)
+
+type VirtualMachineResizeState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	VmName            string
+	ResourceGroupName string
+	OriginalSize      string
+	TargetSize        string
+	DryRun            bool
+	// ResizeResumeToken resumes the resize, which has to finish before the original size is restored.
+	ResizeResumeToken string
+}
+
+type virtualMachineResizeApi interface {
+	Get(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientGetOptions) (armcompute.VirtualMachinesClientGetResponse, error)
+	BeginUpdate(ctx context.Context, resourceGroupName string, vmName string, parameters armcompute.VirtualMachineUpdate, options *armcompute.VirtualMachinesClientBeginUpdateOptions) (*runtime.Poller[armcompute.VirtualMachinesClientUpdateResponse], error)
+}
+
+type virtualMachineSizesApi interface {
+	NewListPager(location string, options *armcompute.VirtualMachineSizesClientListOptions) *runtime.Pager[armcompute.VirtualMachineSizesClientListResponse]
+}
+
+// vmSizePattern splits a size like Standard_D4s_v3 into its family prefix, vCPU count and feature suffix.
+var vmSizePattern = regexp.MustCompile(`^([A-Za-z]+_[A-Za-z]+)(\d+)(.*)$`)
+
+func NewVirtualMachineResizeAction() action_kit_sdk.ActionWithStop[VirtualMachineResizeState] {
+	return &virtualMachineResizeAction{
+		clientProvider: func(subscriptionId string) (virtualMachineResizeApi, error) {
+			return common.GetVirtualMachinesClient(subscriptionId)
+		},
+		sizesProvider: func(subscriptionId string) (virtualMachineSizesApi, error) {
+			cred, err := common.ConnectionAzure()
+			if err != nil {
+				return nil, err
+			}
+			return armcompute.NewVirtualMachineSizesClient(subscriptionId, cred, common.ArmClientOptions())
+		},
+	}
+}
+
+func (e *virtualMachineResizeAction) NewEmptyState() VirtualMachineResizeState {
+	return VirtualMachineResizeState{}
+}
+
+func (e *virtualMachineResizeAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    VirtualMachineResizeActionId,
+		Label: "Resize Virtual Machine",
+		Description: "Resize Azure virtual machines to a smaller size for a given duration and restore the original size afterwards. " +
+			"Azure restarts the virtual machine for both resizes.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDVM,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "vm-name",
+					Description: new("Find azure virtual machine by name"),
+					Query:       "azure-vm.vm.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Virtual Machines"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the virtual machines keep the smaller size."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("60s"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:        "size",
+				Label:       "Size",
+				Description: new("The size to resize to, e.g. 'Standard_D2s_v3'. Leave empty for the next smaller size of the same family."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(2),
+				Required:    new(false),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (e *virtualMachineResizeAction) Prepare(ctx context.Context, state *VirtualMachineResizeState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	vmName := request.Target.Attributes["azure-vm.vm.name"]
+	if len(vmName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-vm.vm.name' attribute.", nil)
+	}
+
+	subscriptionId := request.Target.Attributes["azure.subscription.id"]
+	if len(subscriptionId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.subscription.id' attribute.", nil)
+	}
+
+	resourceGroupName := request.Target.Attributes["azure.resource-group.name"]
+	if len(resourceGroupName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.resource-group.name' attribute.", nil)
+	}
+
+	state.SubscriptionId = subscriptionId[0]
+	state.VmName = vmName[0]
+	state.ResourceGroupName = resourceGroupName[0]
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	vm, err := client.Get(ctx, state.ResourceGroupName, state.VmName, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get vm '%s'", state.VmName), err)
+	}
+	if vm.Properties != nil && vm.Properties.HardwareProfile != nil && vm.Properties.HardwareProfile.VMSize != nil {
+		state.OriginalSize = string(*vm.Properties.HardwareProfile.VMSize)
+	} else if size := request.Target.Attributes["azure-vm.vm.size"]; len(size) > 0 {
+		state.OriginalSize = size[0]
+	}
+	if state.OriginalSize == "" || vm.Location == nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to determine the size and location of vm '%s'", state.VmName), nil)
+	}
+
+	available, err := e.listSizes(ctx, state.SubscriptionId, *vm.Location)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to list the virtual machine sizes available in %s", *vm.Location), err)
+	}
+
+	size := strings.TrimSpace(extutil.ToString(request.Config["size"]))
+	if size == "" {
+		if size = nextSmallerSize(state.OriginalSize, available); size == "" {
+			return nil, extension_kit.ToError(fmt.Sprintf("There is no smaller size of the family of '%s' available in %s. Please choose a size explicitly.", state.OriginalSize, *vm.Location), nil)
+		}
+	} else if !containsFold(available, size) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Size '%s' is not available in %s.", size, *vm.Location), nil)
+	}
+	if strings.EqualFold(size, state.OriginalSize) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Virtual machine '%s' already has size '%s'.", state.VmName, size), nil)
+	}
+	state.TargetSize = size
+
+	return &action_kit_api.PrepareResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Virtual machine '%s' will be resized from '%s' to '%s'.", state.VmName, state.OriginalSize, state.TargetSize),
+		}}),
+	}, nil
+}
+
+func (e *virtualMachineResizeAction) listSizes(ctx context.Context, subscriptionId string, location string) ([]string, error) {
+	client, err := e.sizesProvider(subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make([]string, 0)
+	pager := client.NewListPager(location, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, size := range page.Value {
+			if size != nil && size.Name != nil {
+				sizes = append(sizes, *size.Name)
+			}
+		}
+	}
+	return sizes, nil
+}
+
+// nextSmallerSize returns the available size of the same family and feature suffix with the most vCPUs below the
+// current size, e.g. Standard_D4s_v3 for Standard_D8s_v3. It returns "" if there is none.
+func nextSmallerSize(current string, available []string) string {
+	match := vmSizePattern.FindStringSubmatch(current)
+	if match == nil {
+		return ""
+	}
+	currentCpus, _ := strconv.Atoi(match[2])
+
+	best, bestCpus := "", 0
+	for _, size := range available {
+		candidate := vmSizePattern.FindStringSubmatch(size)
+		if candidate == nil || !strings.EqualFold(candidate[1], match[1]) || !strings.EqualFold(candidate[3], match[3]) {
+			continue
+		}
+		cpus, _ := strconv.Atoi(candidate[2])
+		if cpus < currentCpus && cpus > bestCpus {
+			best, bestCpus = size, cpus
+		}
+	}
+	return best
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *virtualMachineResizeAction) Start(ctx context.Context, state *VirtualMachineResizeState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("resize virtual machine '%s' in resource group '%s' from '%s' to '%s'", state.VmName, state.ResourceGroupName, state.OriginalSize, state.TargetSize)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+
+	operation, err := beginResize(ctx, client, state, state.TargetSize, "")
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to resize vm '%s' to '%s'", state.VmName, state.TargetSize), err)
+	}
+	if operation != nil {
+		if state.ResizeResumeToken, err = operation.ResumeToken(); err != nil {
+			log.Warn().Err(err).Msgf("Failed to get the resume token of the resize of vm '%s'.", state.VmName)
+		}
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Started to resize virtual machine '%s' from '%s' to '%s'.", state.VmName, state.OriginalSize, state.TargetSize),
+		}}),
+	}, nil
+}
+
+func (e *virtualMachineResizeAction) Stop(ctx context.Context, state *VirtualMachineResizeState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("resize virtual machine '%s' in resource group '%s' back to '%s'", state.VmName, state.ResourceGroupName, state.OriginalSize)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+
+	ctx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+
+	if state.ResizeResumeToken != "" {
+		operation, err := beginResize(ctx, client, state, state.TargetSize, state.ResizeResumeToken)
+		if err == nil && operation != nil {
+			err = common.WaitForPrevious(ctx, operation)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("The resize of vm '%s' to '%s' did not complete, restoring the original size anyway.", state.VmName, state.TargetSize)
+		}
+	}
+	if common.IsStopTimeout(ctx) {
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{common.StillInProgress(fmt.Sprintf("resize of virtual machine '%s' to '%s'", state.VmName, state.TargetSize))}),
+		}, nil
+	}
+
+	operation, err := beginResize(ctx, client, state, state.OriginalSize, "")
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to resize vm '%s' back to '%s'", state.VmName, state.OriginalSize), err)
+	}
+	if operation != nil {
+		err = operation.Wait(ctx)
+	}
+	if common.IsStopTimeout(ctx) {
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{{
+				Level:   extutil.Ptr(action_kit_api.Warn),
+				Message: fmt.Sprintf("Virtual machine '%s' is still being resized back to '%s' after %s. Azure keeps resizing it.", state.VmName, state.OriginalSize, common.StopTimeout),
+			}}),
+		}, nil
+	}
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to resize vm '%s' back to '%s'", state.VmName, state.OriginalSize), err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Virtual machine '%s' has its original size '%s' again.", state.VmName, state.OriginalSize),
+		}}),
+	}, nil
+}
+
+// beginResize resizes the virtual machine, or resumes that operation if a resume token is given.
+func beginResize(ctx context.Context, client virtualMachineResizeApi, state *VirtualMachineResizeState, size string, resumeToken string) (common.Operation, error) {
+	update := armcompute.VirtualMachineUpdate{
+		Properties: &armcompute.VirtualMachineProperties{
+			HardwareProfile: &armcompute.HardwareProfile{VMSize: new(armcompute.VirtualMachineSizeTypes(size))},
+		},
+	}
+	return common.NewOperation(client.BeginUpdate(ctx, state.ResourceGroupName, state.VmName, update, &armcompute.VirtualMachinesClientBeginUpdateOptions{ResumeToken: resumeToken}))
+}
+
+func (e *virtualMachineResizeAction) RequiredPermissions(state *VirtualMachineResizeState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s", state.SubscriptionId, state.ResourceGroupName, state.VmName),
+		Operations: []string{
+			"Microsoft.Compute/virtualMachines/read",
+			"Microsoft.Compute/virtualMachines/write",
+		},
+	}}
+}
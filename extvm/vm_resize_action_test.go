@@ -0,0 +1,138 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type virtualMachineResizeApiMock struct {
+	mock.Mock
+}
+
+func (m *virtualMachineResizeApiMock) Get(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientGetOptions) (armcompute.VirtualMachinesClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return args.Get(0).(armcompute.VirtualMachinesClientGetResponse), args.Error(1)
+}
+
+func (m *virtualMachineResizeApiMock) BeginUpdate(ctx context.Context, resourceGroupName string, vmName string, parameters armcompute.VirtualMachineUpdate, _ *armcompute.VirtualMachinesClientBeginUpdateOptions) (*runtime.Poller[armcompute.VirtualMachinesClientUpdateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName, string(*parameters.Properties.HardwareProfile.VMSize))
+	return nil, args.Error(1)
+}
+
+type virtualMachineSizesApiMock struct {
+	sizes []string
+}
+
+func (m *virtualMachineSizesApiMock) NewListPager(string, *armcompute.VirtualMachineSizesClientListOptions) *runtime.Pager[armcompute.VirtualMachineSizesClientListResponse] {
+	return runtime.NewPager(runtime.PagingHandler[armcompute.VirtualMachineSizesClientListResponse]{
+		More: func(armcompute.VirtualMachineSizesClientListResponse) bool { return false },
+		Fetcher: func(context.Context, *armcompute.VirtualMachineSizesClientListResponse) (armcompute.VirtualMachineSizesClientListResponse, error) {
+			sizes := make([]*armcompute.VirtualMachineSize, 0, len(m.sizes))
+			for _, size := range m.sizes {
+				sizes = append(sizes, &armcompute.VirtualMachineSize{Name: new(size)})
+			}
+			return armcompute.VirtualMachineSizesClientListResponse{VirtualMachineSizeListResult: armcompute.VirtualMachineSizeListResult{Value: sizes}}, nil
+		},
+	})
+}
+
+func TestNextSmallerSize(t *testing.T) {
+	available := []string{"Standard_D2s_v3", "Standard_D4s_v3", "Standard_D4_v3", "Standard_D8s_v3", "Standard_E4s_v3", "Standard_D16s_v3"}
+
+	assert.Equal(t, "Standard_D4s_v3", nextSmallerSize("Standard_D8s_v3", available))
+	assert.Equal(t, "Standard_D2s_v3", nextSmallerSize("Standard_D4s_v3", available))
+	assert.Equal(t, "", nextSmallerSize("Standard_D2s_v3", available))
+	assert.Equal(t, "", nextSmallerSize("Basic", available))
+}
+
+func TestVirtualMachineResizeAction_PrepareAndRestore(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          string
+		expectedSize  string
+		expectedError string
+	}{
+		{name: "next smaller size of the family", expectedSize: "Standard_D4s_v3"},
+		{name: "explicit size", size: "Standard_E4s_v3", expectedSize: "Standard_E4s_v3"},
+		{name: "size not available in the location", size: "Standard_M128s", expectedError: "Size 'Standard_M128s' is not available in westeurope."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			api := new(virtualMachineResizeApiMock)
+			api.On("Get", mock.Anything, "rg-42", "vm-1").Return(armcompute.VirtualMachinesClientGetResponse{VirtualMachine: armcompute.VirtualMachine{
+				Location: new("westeurope"),
+				Properties: &armcompute.VirtualMachineProperties{
+					HardwareProfile: &armcompute.HardwareProfile{VMSize: new(armcompute.VirtualMachineSizeTypesStandardD8SV3)},
+				},
+			}}, nil)
+			api.On("BeginUpdate", mock.Anything, "rg-42", "vm-1", tt.expectedSize).Return(nil, nil)
+			api.On("BeginUpdate", mock.Anything, "rg-42", "vm-1", "Standard_D8s_v3").Return(nil, nil)
+			action := &virtualMachineResizeAction{
+				clientProvider: func(string) (virtualMachineResizeApi, error) { return api, nil },
+				sizesProvider: func(string) (virtualMachineSizesApi, error) {
+					return &virtualMachineSizesApiMock{sizes: []string{"Standard_D2s_v3", "Standard_D4s_v3", "Standard_D8s_v3", "Standard_E4s_v3"}}, nil
+				},
+			}
+			state := action.NewEmptyState()
+			request := action_kit_api.PrepareActionRequestBody{
+				Config: map[string]any{"size": tt.size},
+				Target: &action_kit_api.Target{Attributes: map[string][]string{
+					"azure-vm.vm.name":          {"vm-1"},
+					"azure.subscription.id":     {"42"},
+					"azure.resource-group.name": {"rg-42"},
+				}},
+			}
+
+			// When
+			_, err := action.Prepare(context.Background(), &state, request)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			_, err = action.Start(context.Background(), &state)
+			require.NoError(t, err)
+			_, err = action.Stop(context.Background(), &state)
+			require.NoError(t, err)
+
+			// Then
+			assert.Equal(t, "Standard_D8s_v3", state.OriginalSize)
+			assert.Equal(t, tt.expectedSize, state.TargetSize)
+			api.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVirtualMachineResizeAction_StopReportsResizeStillInProgressAfterStopTimeout(t *testing.T) {
+	// Given
+	previous := common.StopTimeout
+	common.StopTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { common.StopTimeout = previous })
+	api := new(virtualMachineResizeApiMock)
+	api.On("BeginUpdate", mock.Anything, "rg-42", "vm-1", "Standard_D4s_v3").Run(func(mock.Arguments) { time.Sleep(100 * time.Millisecond) }).Return(nil, nil)
+	action := &virtualMachineResizeAction{clientProvider: func(string) (virtualMachineResizeApi, error) { return api, nil }}
+	state := &VirtualMachineResizeState{SubscriptionId: "42", VmName: "vm-1", ResourceGroupName: "rg-42", OriginalSize: "Standard_D8s_v3", TargetSize: "Standard_D4s_v3", ResizeResumeToken: "token"}
+
+	// When
+	result, err := action.Stop(context.Background(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, action_kit_api.Warn, *(*result.Messages)[0].Level)
+	assert.Equal(t, "The resize of virtual machine 'vm-1' to 'Standard_D4s_v3' is still in progress after 50ms.", (*result.Messages)[0].Message)
+	api.AssertNotCalled(t, "BeginUpdate", mock.Anything, "rg-42", "vm-1", "Standard_D8s_v3")
+}
@@ -0,0 +1,393 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	osTypeLinux   = "Linux"
+	osTypeWindows = "Windows"
+)
+
+// guestScripts start a fault inside the guest and undo it. The start script must return right away and leave the
+// fault running in the background, bounded by the attack duration in case the stop script never runs.
+type guestScripts struct {
+	Start string
+	Stop  string
+}
+
+// guestFault is an in-guest fault that is injected through the Run Command API of the virtual machine agent, so
+// it works without the Steadybit agent being installed.
+type guestFault struct {
+	description action_kit_api.ActionDescription
+	// linux and windows are the scripts for the respective OS type, nil if the fault is not supported on it.
+	linux   *guestScripts
+	windows *guestScripts
+	// parameters returns the fault specific script parameters. Linux scripts receive them as environment
+	// variables, Windows scripts as PowerShell parameters.
+	parameters func(request action_kit_api.PrepareActionRequestBody, osType string) (map[string]string, error)
+}
+
+type runCommandAction struct {
+	fault          guestFault
+	clientProvider func(subscriptionId string) (virtualMachineRunCommandApi, error)
+}
+
+var _ action_kit_sdk.Action[VirtualMachineRunCommandState] = (*runCommandAction)(nil)
+var _ action_kit_sdk.ActionWithStop[VirtualMachineRunCommandState] = (*runCommandAction)(nil)
+var _ common.ActionWithRequiredPermissions[VirtualMachineRunCommandState] = (*runCommandAction)(nil)
+
+type VirtualMachineRunCommandState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	VmName            string
+	ResourceGroupName string
+	CommandId         string
+	StartScript       string
+	StopScript        string
+	Parameters        map[string]string
+	DryRun            bool
+}
+
+type virtualMachineRunCommandApi interface {
+	BeginRunCommand(ctx context.Context, resourceGroupName string, vmName string, parameters armcompute.RunCommandInput, options *armcompute.VirtualMachinesClientBeginRunCommandOptions) (*runtime.Poller[armcompute.VirtualMachinesClientRunCommandResponse], error)
+}
+
+var guestNamePattern = regexp.MustCompile(`^[A-Za-z0-9@._:+-]+$`)
+
+func newRunCommandAction(fault guestFault) action_kit_sdk.ActionWithStop[VirtualMachineRunCommandState] {
+	return &runCommandAction{
+		fault: fault,
+		clientProvider: func(subscriptionId string) (virtualMachineRunCommandApi, error) {
+			return common.GetVirtualMachinesClient(subscriptionId)
+		},
+	}
+}
+
+// guestFaultDescription describes a Run Command attack on virtual machines. The duration parameter comes first,
+// the dry-run parameter last.
+func guestFaultDescription(id string, label string, description string, parameters ...action_kit_api.ActionParameter) action_kit_api.ActionDescription {
+	allParameters := []action_kit_api.ActionParameter{{
+		Label:        "Duration",
+		Name:         "duration",
+		Type:         action_kit_api.ActionParameterTypeDuration,
+		Description:  new("The duration of the attack."),
+		Required:     new(true),
+		DefaultValue: new("60s"),
+		Order:        new(0),
+	}}
+	allParameters = append(allParameters, parameters...)
+	allParameters = append(allParameters, common.DryRunParameter())
+
+	return action_kit_api.ActionDescription{
+		Id:          id,
+		Label:       label,
+		Description: description + " The fault is injected through the Run Command API of the Azure VM agent and does not require the Steadybit agent.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDVM,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "vm-name",
+					Description: new("Find azure virtual machine by name"),
+					Query:       "azure-vm.vm.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Virtual Machines"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters:  allParameters,
+		Stop:        new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *runCommandAction) NewEmptyState() VirtualMachineRunCommandState {
+	return VirtualMachineRunCommandState{}
+}
+
+func (a *runCommandAction) Describe() action_kit_api.ActionDescription {
+	return a.fault.description
+}
+
+func (a *runCommandAction) Prepare(_ context.Context, state *VirtualMachineRunCommandState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	vmName := request.Target.Attributes["azure-vm.vm.name"]
+	if len(vmName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-vm.vm.name' attribute.", nil)
+	}
+
+	subscriptionId := request.Target.Attributes["azure.subscription.id"]
+	if len(subscriptionId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.subscription.id' attribute.", nil)
+	}
+
+	resourceGroupName := request.Target.Attributes["azure.resource-group.name"]
+	if len(resourceGroupName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.resource-group.name' attribute.", nil)
+	}
+
+	osType := request.Target.Attributes["azure-vm.os.type"]
+	if len(osType) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-vm.os.type' attribute.", nil)
+	}
+
+	var scripts *guestScripts
+	var commandId string
+	switch {
+	case strings.EqualFold(osType[0], osTypeLinux):
+		scripts, commandId = a.fault.linux, "RunShellScript"
+	case strings.EqualFold(osType[0], osTypeWindows):
+		scripts, commandId = a.fault.windows, "RunPowerShellScript"
+	}
+	if scripts == nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("%s is not supported on virtual machine '%s' with OS type '%s'.", a.fault.description.Label, vmName[0], osType[0]), nil)
+	}
+
+	duration := time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
+	if duration < time.Second {
+		return nil, extension_kit.ToError("duration must be at least 1s.", nil)
+	}
+	parameters := map[string]string{}
+	if a.fault.parameters != nil {
+		var err error
+		if parameters, err = a.fault.parameters(request, osType[0]); err != nil {
+			return nil, err
+		}
+	}
+	parameters["duration"] = fmt.Sprintf("%d", int64(duration.Seconds()))
+	parameters["marker"] = fmt.Sprintf("steadybit-%s", vmName[0])
+	if request.ExecutionContext != nil && request.ExecutionContext.ExecutionId != nil {
+		parameters["marker"] = fmt.Sprintf("steadybit-%d", *request.ExecutionContext.ExecutionId)
+	}
+
+	state.SubscriptionId = subscriptionId[0]
+	state.VmName = vmName[0]
+	state.ResourceGroupName = resourceGroupName[0]
+	state.CommandId = commandId
+	state.StartScript = scripts.Start
+	state.StopScript = scripts.Stop
+	state.Parameters = parameters
+	state.DryRun = common.IsDryRun(request)
+	return nil, nil
+}
+
+func (a *runCommandAction) RequiredPermissions(state *VirtualMachineRunCommandState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope:      fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s", state.SubscriptionId, state.ResourceGroupName, state.VmName),
+		Operations: []string{"Microsoft.Compute/virtualMachines/runCommand/action"},
+	}}
+}
+
+func (a *runCommandAction) Start(ctx context.Context, state *VirtualMachineRunCommandState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("run the %s script of '%s' on virtual machine '%s' with %v", state.CommandId, a.fault.description.Label, state.VmName, state.Parameters)),
+		}, nil
+	}
+	output, err := a.runCommand(ctx, state, state.StartScript)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to start '%s' on vm '%s'", a.fault.description.Label, state.VmName), err)
+	}
+	return &action_kit_api.StartResult{Messages: output.messages(state)}, nil
+}
+
+func (a *runCommandAction) Stop(ctx context.Context, state *VirtualMachineRunCommandState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("run the %s script undoing '%s' on virtual machine '%s'", state.CommandId, a.fault.description.Label, state.VmName)),
+		}, nil
+	}
+	output, err := a.runCommand(ctx, state, state.StopScript)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to undo '%s' on vm '%s'", a.fault.description.Label, state.VmName), err)
+	}
+	return &action_kit_api.StopResult{Messages: output.messages(state)}, nil
+}
+
+// runCommandOutput is what a script wrote to stdout and stderr.
+type runCommandOutput struct {
+	stdout []string
+	stderr []string
+}
+
+// exitCodePattern matches the line with the exit code of the script, which runCommandInput appends as Run Command
+// does not report it.
+var exitCodePattern = regexp.MustCompile(`(?m)^` + exitCodeMarker + `(-?\d+)\s*$`)
+
+const exitCodeMarker = "steadybit-exit-code="
+
+// runCommand runs the script and returns its output. It fails if Run Command reports an error or the script exited
+// with a non-zero exit code. Output on stderr alone does not fail the script, as many tools log to stderr.
+func (a *runCommandAction) runCommand(ctx context.Context, state *VirtualMachineRunCommandState, script string) (runCommandOutput, error) {
+	client, err := a.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return runCommandOutput{}, fmt.Errorf("failed to initialize azure client for subscription %s: %w", state.SubscriptionId, err)
+	}
+	poller, err := client.BeginRunCommand(ctx, state.ResourceGroupName, state.VmName, runCommandInput(state.CommandId, script, state.Parameters), nil)
+	if err != nil {
+		return runCommandOutput{}, err
+	}
+	if poller == nil {
+		return runCommandOutput{}, errors.New("azure returned no operation for the run command")
+	}
+	result, err := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: 5 * time.Second})
+	if err != nil {
+		return runCommandOutput{}, err
+	}
+	return parseRunCommandResult(result.Value)
+}
+
+// parseRunCommandResult splits the statuses of a Run Command into stdout and stderr and checks the exit code.
+func parseRunCommandResult(statuses []*armcompute.InstanceViewStatus) (runCommandOutput, error) {
+	var output runCommandOutput
+	var failure string
+	exitCode := 0
+	for _, status := range statuses {
+		if status == nil || status.Code == nil {
+			continue
+		}
+		message := ""
+		if status.Message != nil {
+			message = strings.TrimSpace(*status.Message)
+		}
+		if match := exitCodePattern.FindStringSubmatch(message); match != nil {
+			exitCode, _ = strconv.Atoi(match[1])
+			message = strings.TrimSpace(exitCodePattern.ReplaceAllString(message, ""))
+		}
+		if (status.Level != nil && *status.Level == armcompute.StatusLevelTypesError) || strings.HasSuffix(strings.ToLower(*status.Code), "/failed") {
+			failure = fmt.Sprintf("%s %s", *status.Code, message)
+		}
+		if message == "" {
+			continue
+		}
+		if strings.Contains(*status.Code, "StdErr") {
+			output.stderr = append(output.stderr, message)
+		} else {
+			output.stdout = append(output.stdout, message)
+		}
+	}
+	if failure != "" {
+		return output, fmt.Errorf("run command failed: %s", strings.TrimSpace(failure))
+	}
+	if exitCode != 0 {
+		return output, fmt.Errorf("script failed with exit code %d: %s", exitCode, strings.Join(append(output.stderr, output.stdout...), "\n"))
+	}
+	return output, nil
+}
+
+// runCommandInput passes the parameters in a stable order and wraps the script to print its exit code. PowerShell
+// scripts get a param block declaring the parameters, as Run Command passes them as named arguments.
+func runCommandInput(commandId string, script string, parameters map[string]string) armcompute.RunCommandInput {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	input := armcompute.RunCommandInput{CommandID: new(commandId)}
+	declarations := make([]string, 0, len(names))
+	for _, name := range names {
+		input.Parameters = append(input.Parameters, &armcompute.RunCommandInputParameter{Name: new(name), Value: new(parameters[name])})
+		declarations = append(declarations, fmt.Sprintf("[string]$%s", name))
+	}
+	if commandId == "RunPowerShellScript" {
+		script = fmt.Sprintf(powerShellScriptTemplate, strings.Join(declarations, ", "), script)
+	} else {
+		script = fmt.Sprintf(shellScriptTemplate, script)
+	}
+	for _, line := range strings.Split(script, "\n") {
+		input.Script = append(input.Script, new(line))
+	}
+	return input
+}
+
+func (o runCommandOutput) messages(state *VirtualMachineRunCommandState) *action_kit_api.Messages {
+	var messages []action_kit_api.Message
+	if len(o.stdout) > 0 {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Virtual machine '%s': %s", state.VmName, strings.Join(o.stdout, "\n")),
+		})
+	}
+	if len(o.stderr) > 0 {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Warn),
+			Message: fmt.Sprintf("Virtual machine '%s' reported on stderr: %s", state.VmName, strings.Join(o.stderr, "\n")),
+		})
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return new(messages)
+}
+
+// guestName validates a process or service name, as Windows scripts embed it into the command of a background
+// process.
+func guestName(request action_kit_api.PrepareActionRequestBody, parameter string) (string, error) {
+	name := strings.TrimSpace(extutil.ToString(request.Config[parameter]))
+	if !guestNamePattern.MatchString(name) {
+		return "", extension_kit.ToError(fmt.Sprintf("Invalid %s '%s'. Only letters, digits and the characters @ . _ : + - are allowed.", parameter, name), nil)
+	}
+	return name, nil
+}
+
+const (
+	// shellScriptTemplate runs the script in a subshell that exits on the first failing command and prints its exit
+	// code. Commands that may fail have to be guarded with || in the scripts.
+	shellScriptTemplate = `(
+set -e
+%s
+)
+echo "` + exitCodeMarker + `$?"`
+	// powerShellScriptTemplate declares the parameters, runs the script and prints exit code 1 if it threw.
+	powerShellScriptTemplate = `param(%s)
+$exitCode = 0
+try {
+%s
+} catch {
+  Write-Error $_ -ErrorAction Continue
+  $exitCode = 1
+}
+Write-Output "` + exitCodeMarker + `$exitCode"`
+	// linuxKillBackgroundProcesses stops the background processes the start script recorded.
+	linuxKillBackgroundProcesses = `if [ -f "/tmp/$marker.pids" ]; then
+  xargs kill < "/tmp/$marker.pids" 2>/dev/null || true
+  rm -f "/tmp/$marker.pids"
+fi`
+	// windowsKillBackgroundProcesses stops the background processes the start script recorded.
+	windowsKillBackgroundProcesses = `$pids = "$env:SystemRoot\Temp\$marker.pids"
+if (Test-Path $pids) {
+  Get-Content $pids | ForEach-Object { Stop-Process -Id $_ -Force -ErrorAction SilentlyContinue }
+  Remove-Item $pids -Force
+}`
+	// windowsStartBackgroundProcess defines Start-Background, which starts a hidden PowerShell running the given
+	// command and records it for windowsKillBackgroundProcesses. The command is passed encoded to keep its quotes.
+	windowsStartBackgroundProcess = `function Start-Background([string]$command) {
+  $encoded = [Convert]::ToBase64String([Text.Encoding]::Unicode.GetBytes($command))
+  $process = Start-Process powershell -WindowStyle Hidden -PassThru -ArgumentList "-NoProfile -EncodedCommand $encoded"
+  Add-Content -Path "$env:SystemRoot\Temp\$marker.pids" -Value $process.Id
+}`
+)
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+var (
+	linuxPathPattern   = regexp.MustCompile(`^/[A-Za-z0-9._/-]*$`)
+	windowsPathPattern = regexp.MustCompile(`^[A-Za-z]:\\[A-Za-z0-9._\\ -]*$`)
+)
+
+func NewVirtualMachineFillDiskAction() action_kit_sdk.ActionWithStop[VirtualMachineRunCommandState] {
+	return newRunCommandAction(guestFault{
+		description: guestFaultDescription(VirtualMachineFillDiskActionId, "Fill Disk (Run Command)",
+			"Writes a file of the given size into a directory of virtual machines and deletes it when the attack ends.",
+			action_kit_api.ActionParameter{
+				Name:        "path",
+				Label:       "Path",
+				Description: new("Directory to write the file to. Defaults to '/tmp' on Linux and 'C:\\Windows\\Temp' on Windows."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(1),
+				Required:    new(false),
+			},
+			action_kit_api.ActionParameter{
+				Name:         "megabytes",
+				Label:        "Megabytes",
+				Description:  new("Size of the file in megabytes. Writing stops early when the disk is full."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("1024"),
+				MinValue:     new(1),
+				Order:        new(2),
+				Required:     new(true),
+			},
+		),
+		linux: &guestScripts{
+			Start: `file="$path/$marker.fill"
+fallocate -l "${megabytes}M" "$file" 2>/dev/null || dd if=/dev/zero of="$file" bs=1M count="$megabytes" 2>/dev/null || { rm -f "$file"; echo "Failed to write ${megabytes}MB to $file" >&2; exit 1; }
+file="$file" setsid nohup sh -c 'sleep "$0"; rm -f "$file"' "$duration" >/dev/null 2>&1 &
+echo $! >> "/tmp/$marker.pids"
+echo "Wrote $(du -m "$file" | cut -f1)MB to $file"`,
+			Stop: linuxKillBackgroundProcesses + `
+rm -f "$path/$marker.fill"
+echo "Deleted $path/$marker.fill"`,
+		},
+		windows: &guestScripts{
+			Start: windowsStartBackgroundProcess + `
+$file = Join-Path $path "$marker.fill"
+fsutil file createnew $file ([int64]$megabytes * 1MB) | Out-Null
+if (-not (Test-Path $file)) { throw "Failed to write $file" }
+Start-Background ('Start-Sleep -Seconds {0}; Remove-Item -Path ''{1}'' -Force' -f $duration, $file)
+Write-Output "Wrote $($megabytes)MB to $file"`,
+			Stop: windowsKillBackgroundProcesses + `
+$file = Join-Path $path "$marker.fill"
+Remove-Item -Path $file -Force -ErrorAction SilentlyContinue
+Write-Output "Deleted $file"`,
+		},
+		parameters: func(request action_kit_api.PrepareActionRequestBody, osType string) (map[string]string, error) {
+			megabytes := extutil.ToInt(request.Config["megabytes"])
+			if megabytes < 1 {
+				return nil, extension_kit.ToError("megabytes must be at least 1.", nil)
+			}
+			path, pattern := strings.TrimSpace(extutil.ToString(request.Config["path"])), linuxPathPattern
+			if strings.EqualFold(osType, osTypeWindows) {
+				pattern = windowsPathPattern
+				if path == "" {
+					path = `C:\Windows\Temp`
+				}
+			} else if path == "" {
+				path = "/tmp"
+			}
+			if !pattern.MatchString(path) {
+				return nil, extension_kit.ToError(fmt.Sprintf("Invalid path '%s'. Please provide an absolute directory path.", path), nil)
+			}
+			return map[string]string{"path": path, "megabytes": fmt.Sprintf("%d", megabytes)}, nil
+		},
+	})
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// NewVirtualMachineNetworkDelayAction delays traffic with tc netem. Windows has no built-in equivalent, so the
+// attack is only available for Linux virtual machines.
+func NewVirtualMachineNetworkDelayAction() action_kit_sdk.ActionWithStop[VirtualMachineRunCommandState] {
+	return newRunCommandAction(guestFault{
+		description: guestFaultDescription(VirtualMachineNetworkDelayActionId, "Network Delay (Run Command)",
+			"Delays all outgoing traffic of a network interface using tc. Only available for Linux virtual machines, Windows has no built-in equivalent.",
+			action_kit_api.ActionParameter{
+				Name:         "delay",
+				Label:        "Delay",
+				Description:  new("Delay in milliseconds added to every outgoing packet."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("500"),
+				MinValue:     new(1),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			action_kit_api.ActionParameter{
+				Name:         "jitter",
+				Label:        "Jitter",
+				Description:  new("Random variation of the delay in milliseconds."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("0"),
+				MinValue:     new(0),
+				Order:        new(2),
+				Required:     new(true),
+			},
+			action_kit_api.ActionParameter{
+				Name:        "interface",
+				Label:       "Network Interface",
+				Description: new("Network interface to delay, defaults to the interface of the default route."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(3),
+				Required:    new(false),
+				Advanced:    new(true),
+			},
+		),
+		linux: &guestScripts{
+			Start: `iface="$interface"
+[ -n "$iface" ] || iface=$(ip route show default | awk '{print $5; exit}')
+tc qdisc add dev "$iface" root netem delay "${delay}ms" "${jitter}ms"
+echo "$iface" > "/tmp/$marker.iface"
+iface="$iface" setsid nohup sh -c 'sleep "$0"; tc qdisc del dev "$iface" root netem' "$duration" >/dev/null 2>&1 &
+echo $! >> "/tmp/$marker.pids"
+echo "Delaying traffic of $iface by ${delay}ms for ${duration}s"`,
+			Stop: linuxKillBackgroundProcesses + `
+if [ -f "/tmp/$marker.iface" ]; then
+  iface=$(cat "/tmp/$marker.iface")
+  tc qdisc del dev "$iface" root netem 2>/dev/null || true
+  rm -f "/tmp/$marker.iface"
+  echo "Removed the delay from $iface"
+fi`,
+		},
+		parameters: func(request action_kit_api.PrepareActionRequestBody, _ string) (map[string]string, error) {
+			delay := extutil.ToInt(request.Config["delay"])
+			jitter := extutil.ToInt(request.Config["jitter"])
+			if delay < 1 || jitter < 0 {
+				return nil, extension_kit.ToError("delay must be at least 1ms and jitter must not be negative.", nil)
+			}
+			parameters := map[string]string{"delay": fmt.Sprintf("%d", delay), "jitter": fmt.Sprintf("%d", jitter), "interface": ""}
+			if strings.TrimSpace(extutil.ToString(request.Config["interface"])) != "" {
+				iface, err := guestName(request, "interface")
+				if err != nil {
+					return nil, err
+				}
+				parameters["interface"] = iface
+			}
+			return parameters, nil
+		},
+	})
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"fmt"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+func NewVirtualMachineKillProcessAction() action_kit_sdk.ActionWithStop[VirtualMachineRunCommandState] {
+	return newRunCommandAction(guestFault{
+		description: guestFaultDescription(VirtualMachineKillProcessActionId, "Kill Process (Run Command)",
+			"Repeatedly kills the processes with the given name on virtual machines for the attack duration.",
+			action_kit_api.ActionParameter{
+				Name:        "process",
+				Label:       "Process",
+				Description: new("Name of the processes to kill, e.g. 'nginx' or 'w3wp'."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(1),
+				Required:    new(true),
+			},
+			action_kit_api.ActionParameter{
+				Name:         "interval",
+				Label:        "Interval",
+				Description:  new("Seconds between two kills."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("5"),
+				MinValue:     new(1),
+				Order:        new(2),
+				Required:     new(true),
+			},
+		),
+		linux: &guestScripts{
+			Start: `process="$process" interval="$interval" setsid nohup timeout "$duration" sh -c 'while :; do pkill -KILL -x "$process"; sleep "$interval"; done' >/dev/null 2>&1 &
+echo $! >> "/tmp/$marker.pids"
+echo "Killing '$process' every ${interval}s for ${duration}s"`,
+			Stop: linuxKillBackgroundProcesses,
+		},
+		windows: &guestScripts{
+			Start: windowsStartBackgroundProcess + `
+Start-Background ('$end = (Get-Date).AddSeconds({0}); while ((Get-Date) -lt $end) {{ Stop-Process -Name ''{1}'' -Force -ErrorAction SilentlyContinue; Start-Sleep -Seconds {2} }}' -f $duration, $process, $interval)
+Write-Output "Killing '$process' every $($interval)s for $($duration)s"`,
+			Stop: windowsKillBackgroundProcesses,
+		},
+		parameters: func(request action_kit_api.PrepareActionRequestBody, _ string) (map[string]string, error) {
+			process, err := guestName(request, "process")
+			if err != nil {
+				return nil, err
+			}
+			interval := extutil.ToInt(request.Config["interval"])
+			if interval < 1 {
+				return nil, extension_kit.ToError("interval must be at least 1 second.", nil)
+			}
+			return map[string]string{"process": process, "interval": fmt.Sprintf("%d", interval)}, nil
+		},
+	})
+}
+
+func NewVirtualMachineStopServiceAction() action_kit_sdk.ActionWithStop[VirtualMachineRunCommandState] {
+	return newRunCommandAction(guestFault{
+		description: guestFaultDescription(VirtualMachineStopServiceActionId, "Stop Service (Run Command)",
+			"Stops a systemd service or Windows service on virtual machines and starts it again when the attack ends.",
+			action_kit_api.ActionParameter{
+				Name:        "service",
+				Label:       "Service",
+				Description: new("Name of the systemd unit or Windows service, e.g. 'nginx' or 'W3SVC'."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(1),
+				Required:    new(true),
+			},
+		),
+		linux: &guestScripts{
+			Start: `systemctl stop "$service"
+service="$service" setsid nohup sh -c 'sleep "$0"; systemctl start "$service"' "$duration" >/dev/null 2>&1 &
+echo $! >> "/tmp/$marker.pids"
+echo "Stopped '$service' for ${duration}s"`,
+			Stop: linuxKillBackgroundProcesses + `
+systemctl start "$service"
+echo "Started '$service'"`,
+		},
+		windows: &guestScripts{
+			Start: windowsStartBackgroundProcess + `
+Stop-Service -Name $service -Force -ErrorAction Stop
+Start-Background ('Start-Sleep -Seconds {0}; Start-Service -Name ''{1}''' -f $duration, $service)
+Write-Output "Stopped '$service' for $($duration)s"`,
+			Stop: windowsKillBackgroundProcesses + `
+Start-Service -Name $service -ErrorAction Stop
+Write-Output "Started '$service'"`,
+		},
+		parameters: func(request action_kit_api.PrepareActionRequestBody, _ string) (map[string]string, error) {
+			service, err := guestName(request, "service")
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{"service": service}, nil
+		},
+	})
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"fmt"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+func NewVirtualMachineStressCpuAction() action_kit_sdk.ActionWithStop[VirtualMachineRunCommandState] {
+	return newRunCommandAction(guestFault{
+		description: guestFaultDescription(VirtualMachineStressCpuActionId, "Stress CPU (Run Command)",
+			"Keeps CPU cores of virtual machines busy.",
+			action_kit_api.ActionParameter{
+				Name:         "workers",
+				Label:        "Workers",
+				Description:  new("Number of busy-looping workers, 0 for one per CPU core."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("0"),
+				MinValue:     new(0),
+				Order:        new(1),
+				Required:     new(true),
+			},
+		),
+		linux: &guestScripts{
+			Start: `n="$workers"
+[ "$n" -gt 0 ] || n=$(nproc)
+i=0
+while [ "$i" -lt "$n" ]; do
+  setsid nohup timeout "$duration" sh -c 'while :; do :; done' >/dev/null 2>&1 &
+  echo $! >> "/tmp/$marker.pids"
+  i=$((i + 1))
+done
+echo "Started $n CPU worker(s) for ${duration}s"`,
+			Stop: linuxKillBackgroundProcesses,
+		},
+		windows: &guestScripts{
+			Start: windowsStartBackgroundProcess + `
+$n = [int]$workers
+if ($n -le 0) { $n = [Environment]::ProcessorCount }
+for ($i = 0; $i -lt $n; $i++) {
+  Start-Background ('$end = (Get-Date).AddSeconds({0}); while ((Get-Date) -lt $end) {{ }}' -f $duration)
+}
+Write-Output "Started $n CPU worker(s) for $($duration)s"`,
+			Stop: windowsKillBackgroundProcesses,
+		},
+		parameters: func(request action_kit_api.PrepareActionRequestBody, _ string) (map[string]string, error) {
+			workers := extutil.ToInt(request.Config["workers"])
+			if workers < 0 {
+				return nil, extension_kit.ToError("workers must not be negative.", nil)
+			}
+			return map[string]string{"workers": fmt.Sprintf("%d", workers)}, nil
+		},
+	})
+}
+
+func NewVirtualMachineStressMemoryAction() action_kit_sdk.ActionWithStop[VirtualMachineRunCommandState] {
+	return newRunCommandAction(guestFault{
+		description: guestFaultDescription(VirtualMachineStressMemoryActionId, "Stress Memory (Run Command)",
+			"Allocates memory on virtual machines.",
+			action_kit_api.ActionParameter{
+				Name:         "megabytes",
+				Label:        "Megabytes",
+				Description:  new("Amount of memory to allocate in megabytes."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("512"),
+				MinValue:     new(1),
+				Order:        new(1),
+				Required:     new(true),
+			},
+		),
+		linux: &guestScripts{
+			// tail buffers its whole input when it contains no newline
+			Start: `setsid nohup timeout "$duration" sh -c "head -c ${megabytes}M /dev/zero | tail" >/dev/null 2>&1 &
+echo $! >> "/tmp/$marker.pids"
+echo "Allocating ${megabytes}MB for ${duration}s"`,
+			Stop: linuxKillBackgroundProcesses,
+		},
+		windows: &guestScripts{
+			// every page of the allocated chunks is touched so that it is committed
+			Start: windowsStartBackgroundProcess + `
+Start-Background ('$chunks = foreach ($n in 1..{0}) {{ $b = [byte[]]::new(1MB); for ($i = 0; $i -lt 1MB; $i += 4096) {{ $b[$i] = 1 }}; ,$b }}; Start-Sleep -Seconds {1}' -f $megabytes, $duration)
+Write-Output "Allocating $($megabytes)MB for $($duration)s"`,
+			Stop: windowsKillBackgroundProcesses,
+		},
+		parameters: func(request action_kit_api.PrepareActionRequestBody, _ string) (map[string]string, error) {
+			megabytes := extutil.ToInt(request.Config["megabytes"])
+			if megabytes < 1 {
+				return nil, extension_kit.ToError("megabytes must be at least 1.", nil)
+			}
+			return map[string]string{"megabytes": fmt.Sprintf("%d", megabytes)}, nil
+		},
+	})
+}
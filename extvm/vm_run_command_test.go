@@ -0,0 +1,262 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type virtualMachineRunCommandApiMock struct {
+	mock.Mock
+}
+
+func (m *virtualMachineRunCommandApiMock) BeginRunCommand(ctx context.Context, resourceGroupName string, vmName string, parameters armcompute.RunCommandInput, _ *armcompute.VirtualMachinesClientBeginRunCommandOptions) (*runtime.Poller[armcompute.VirtualMachinesClientRunCommandResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName, parameters)
+	poller, _ := args.Get(0).(*runtime.Poller[armcompute.VirtualMachinesClientRunCommandResponse])
+	return poller, args.Error(1)
+}
+
+// completedRunCommand returns a poller of a Run Command that completed with the given result.
+func completedRunCommand(t *testing.T, result string) *runtime.Poller[armcompute.VirtualMachinesClientRunCommandResponse] {
+	request, err := http.NewRequest(http.MethodPost, "https://management.azure.com/subscriptions/42/resourceGroups/rg-42/providers/Microsoft.Compute/virtualMachines/vm-1/runCommand", nil)
+	require.NoError(t, err)
+	ok := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(result)),
+		Request:    request,
+	}
+	pipeline := runtime.NewPipeline("test", "v1.0.0", runtime.PipelineOptions{}, &policy.ClientOptions{Retry: policy.RetryOptions{MaxRetries: -1}})
+	poller, err := runtime.NewPoller[armcompute.VirtualMachinesClientRunCommandResponse](ok, pipeline, nil)
+	require.NoError(t, err)
+	return poller
+}
+
+func runCommandRequest(osType string, config map[string]any) action_kit_api.PrepareActionRequestBody {
+	config["duration"] = 90000
+	return action_kit_api.PrepareActionRequestBody{
+		Config: config,
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure-vm.vm.name":          {"vm-1"},
+			"azure-vm.os.type":          {osType},
+			"azure.subscription.id":     {"42"},
+			"azure.resource-group.name": {"rg-42"},
+		}},
+		ExecutionContext: &action_kit_api.ExecutionContext{ExecutionId: new(7)},
+	}
+}
+
+func TestRunCommandAction_Prepare(t *testing.T) {
+	tests := []struct {
+		name               string
+		action             func() *runCommandAction
+		osType             string
+		config             map[string]any
+		expectedCommandId  string
+		expectedParameters map[string]string
+		expectedError      string
+	}{
+		{
+			name:               "linux cpu stress",
+			action:             func() *runCommandAction { return NewVirtualMachineStressCpuAction().(*runCommandAction) },
+			osType:             "Linux",
+			config:             map[string]any{"workers": 2},
+			expectedCommandId:  "RunShellScript",
+			expectedParameters: map[string]string{"duration": "90", "marker": "steadybit-7", "workers": "2"},
+		},
+		{
+			name:               "windows stop service",
+			action:             func() *runCommandAction { return NewVirtualMachineStopServiceAction().(*runCommandAction) },
+			osType:             "Windows",
+			config:             map[string]any{"service": "W3SVC"},
+			expectedCommandId:  "RunPowerShellScript",
+			expectedParameters: map[string]string{"duration": "90", "marker": "steadybit-7", "service": "W3SVC"},
+		},
+		{
+			name:               "windows fill disk defaults the path",
+			action:             func() *runCommandAction { return NewVirtualMachineFillDiskAction().(*runCommandAction) },
+			osType:             "Windows",
+			config:             map[string]any{"megabytes": 10},
+			expectedCommandId:  "RunPowerShellScript",
+			expectedParameters: map[string]string{"duration": "90", "marker": "steadybit-7", "megabytes": "10", "path": `C:\Windows\Temp`},
+		},
+		{
+			name:          "rejects process names that could break out of the script",
+			action:        func() *runCommandAction { return NewVirtualMachineKillProcessAction().(*runCommandAction) },
+			osType:        "Windows",
+			config:        map[string]any{"process": "nginx'; Restart-Computer; '", "interval": 5},
+			expectedError: "Invalid process",
+		},
+		{
+			name:          "network delay is not supported on windows",
+			action:        func() *runCommandAction { return NewVirtualMachineNetworkDelayAction().(*runCommandAction) },
+			osType:        "Windows",
+			config:        map[string]any{"delay": 100, "jitter": 0},
+			expectedError: "Network Delay (Run Command) is not supported on virtual machine 'vm-1' with OS type 'Windows'.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := VirtualMachineRunCommandState{}
+
+			_, err := tt.action().Prepare(context.Background(), &state, runCommandRequest(tt.osType, tt.config))
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCommandId, state.CommandId)
+			assert.Equal(t, tt.expectedParameters, state.Parameters)
+		})
+	}
+}
+
+func TestRunCommandInput_DeclaresPowerShellParameters(t *testing.T) {
+	input := runCommandInput("RunPowerShellScript", "Write-Output $service\nWrite-Output $duration", map[string]string{"service": "W3SVC", "duration": "90"})
+
+	require.Len(t, input.Script, 10)
+	assert.Equal(t, "param([string]$duration, [string]$service)", *input.Script[0])
+	assert.Equal(t, "Write-Output $service", *input.Script[3])
+	assert.Equal(t, `Write-Output "steadybit-exit-code=$exitCode"`, *input.Script[9])
+	assert.Equal(t, "duration", *input.Parameters[0].Name)
+	assert.Equal(t, "W3SVC", *input.Parameters[1].Value)
+}
+
+func TestRunCommandAction_StartAndStopRunScripts(t *testing.T) {
+	// Given
+	api := new(virtualMachineRunCommandApiMock)
+	action := &runCommandAction{
+		fault: guestFault{linux: &guestScripts{Start: "start", Stop: "stop"}},
+		clientProvider: func(string) (virtualMachineRunCommandApi, error) {
+			return api, nil
+		},
+	}
+	state := &VirtualMachineRunCommandState{SubscriptionId: "42", VmName: "vm-1", ResourceGroupName: "rg-42", CommandId: "RunShellScript", StartScript: "start", StopScript: "stop", Parameters: map[string]string{"marker": "steadybit-7"}}
+	scriptIs := func(script string) any {
+		return mock.MatchedBy(func(input armcompute.RunCommandInput) bool {
+			return *input.CommandID == "RunShellScript" && len(input.Script) == 5 && *input.Script[2] == script
+		})
+	}
+	api.On("BeginRunCommand", mock.Anything, "rg-42", "vm-1", scriptIs("start")).Return(completedRunCommand(t, `{"value":[{"code":"ProvisioningState/succeeded","message":"Enable succeeded: \n[stdout]\nstarted\nsteadybit-exit-code=0\n\n[stderr]\n"}]}`), nil)
+	api.On("BeginRunCommand", mock.Anything, "rg-42", "vm-1", scriptIs("stop")).Return(completedRunCommand(t, `{"value":[]}`), nil)
+
+	// When
+	startResult, err := action.Start(context.Background(), state)
+	require.NoError(t, err)
+	stopResult, err := action.Stop(context.Background(), state)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, "Virtual machine 'vm-1': Enable succeeded: \n[stdout]\nstarted\n\n[stderr]", (*startResult.Messages)[0].Message)
+	assert.Nil(t, stopResult.Messages)
+	api.AssertExpectations(t)
+}
+
+func TestRunCommandAction_StartFailsWhenALinuxCommandFails(t *testing.T) {
+	// Given
+	api := new(virtualMachineRunCommandApiMock)
+	action := &runCommandAction{clientProvider: func(string) (virtualMachineRunCommandApi, error) { return api, nil }}
+	state := &VirtualMachineRunCommandState{SubscriptionId: "42", VmName: "vm-1", ResourceGroupName: "rg-42", CommandId: "RunShellScript", StartScript: "ls /steadybit-does-not-exist\necho started"}
+	input := runCommandInput(state.CommandId, state.StartScript, nil)
+	script := make([]string, 0, len(input.Script))
+	for _, line := range input.Script {
+		script = append(script, *line)
+	}
+	stdout, err := exec.Command("sh", "-c", strings.Join(script, "\n")).Output()
+	require.NoError(t, err)
+	result, err := json.Marshal(armcompute.RunCommandResult{Value: []*armcompute.InstanceViewStatus{{
+		Code:    new("ProvisioningState/succeeded"),
+		Message: new("Enable succeeded: \n[stdout]\n" + string(stdout) + "\n[stderr]\nls: /steadybit-does-not-exist: No such file or directory\n"),
+	}}})
+	require.NoError(t, err)
+	api.On("BeginRunCommand", mock.Anything, "rg-42", "vm-1", mock.Anything).Return(completedRunCommand(t, string(result)), nil)
+
+	// When
+	_, err = action.Start(context.Background(), state)
+
+	// Then
+	assert.NotContains(t, string(stdout), "started")
+	assert.ErrorContains(t, err, "script failed with exit code")
+}
+
+func TestRunCommandAction_FailsWithoutOperation(t *testing.T) {
+	// Given
+	api := new(virtualMachineRunCommandApiMock)
+	api.On("BeginRunCommand", mock.Anything, "rg-42", "vm-1", mock.Anything).Return(nil, nil)
+	action := &runCommandAction{clientProvider: func(string) (virtualMachineRunCommandApi, error) { return api, nil }}
+	state := &VirtualMachineRunCommandState{SubscriptionId: "42", VmName: "vm-1", ResourceGroupName: "rg-42", CommandId: "RunShellScript"}
+
+	// When
+	_, err := action.Start(context.Background(), state)
+
+	// Then
+	assert.ErrorContains(t, err, "azure returned no operation for the run command")
+}
+
+func TestParseRunCommandResult(t *testing.T) {
+	status := func(code string, level armcompute.StatusLevelTypes, message string) *armcompute.InstanceViewStatus {
+		return &armcompute.InstanceViewStatus{Code: new(code), Level: new(level), Message: new(message)}
+	}
+	tests := []struct {
+		name           string
+		statuses       []*armcompute.InstanceViewStatus
+		expectedStdout []string
+		expectedStderr []string
+		expectedError  string
+	}{
+		{
+			name: "output on stderr does not fail the script",
+			statuses: []*armcompute.InstanceViewStatus{
+				status("ComponentStatus/StdOut/succeeded", armcompute.StatusLevelTypesInfo, "Stopped W3SVC\nsteadybit-exit-code=0"),
+				status("ComponentStatus/StdErr/succeeded", armcompute.StatusLevelTypesInfo, "WARNING: slow"),
+			},
+			expectedStdout: []string{"Stopped W3SVC"},
+			expectedStderr: []string{"WARNING: slow"},
+		},
+		{
+			name: "non-zero exit code fails the script",
+			statuses: []*armcompute.InstanceViewStatus{
+				status("ComponentStatus/StdOut/succeeded", armcompute.StatusLevelTypesInfo, "steadybit-exit-code=1"),
+				status("ComponentStatus/StdErr/succeeded", armcompute.StatusLevelTypesInfo, "Failed to write C:\\big.file"),
+			},
+			expectedError: "script failed with exit code 1: Failed to write C:\\big.file",
+		},
+		{
+			name: "error status fails the script",
+			statuses: []*armcompute.InstanceViewStatus{
+				status("ProvisioningState/failed", armcompute.StatusLevelTypesError, "Enable failed: agent not ready"),
+			},
+			expectedError: "run command failed: ProvisioningState/failed Enable failed: agent not ready",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := parseRunCommandResult(tt.statuses)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStdout, output.stdout)
+			assert.Equal(t, tt.expectedStderr, output.stderr)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type virtualMachineSpotEvictionAction struct {
+	clientProvider func(subscriptionId string) (virtualMachineSpotEvictionApi, error)
+}
+
+var _ action_kit_sdk.Action[VirtualMachineSpotEvictionState] = (*virtualMachineSpotEvictionAction)(nil)
+var _ common.ActionWithRequiredPermissions[VirtualMachineSpotEvictionState] = (*virtualMachineSpotEvictionAction)(nil)
+
+type VirtualMachineSpotEvictionState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	VmName            string
+	ResourceGroupName string
+	DryRun            bool
+}
+
+type virtualMachineSpotEvictionApi interface {
+	Get(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientGetOptions) (armcompute.VirtualMachinesClientGetResponse, error)
+	SimulateEviction(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientSimulateEvictionOptions) (armcompute.VirtualMachinesClientSimulateEvictionResponse, error)
+}
+
+func NewVirtualMachineSpotEvictionAction() action_kit_sdk.Action[VirtualMachineSpotEvictionState] {
+	return &virtualMachineSpotEvictionAction{
+		clientProvider: func(subscriptionId string) (virtualMachineSpotEvictionApi, error) {
+			return common.GetVirtualMachinesClient(subscriptionId)
+		},
+	}
+}
+
+func (e *virtualMachineSpotEvictionAction) NewEmptyState() VirtualMachineSpotEvictionState {
+	return VirtualMachineSpotEvictionState{}
+}
+
+func (e *virtualMachineSpotEvictionAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    VirtualMachineSpotEvictionActionId,
+		Label: "Simulate Spot Eviction",
+		Description: "Simulates the eviction of Azure Spot virtual machines. Azure sends the eviction notice through the Scheduled Events API " +
+			"and evicts the virtual machine about 30 seconds later, according to its eviction policy.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDVM,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "vm-name",
+					Description: new("Find azure virtual machine by name"),
+					Query:       "azure-vm.vm.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Virtual Machines"),
+		TimeControl: action_kit_api.TimeControlInstantaneous,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			common.DryRunParameter(),
+		},
+	}
+}
+
+func (e *virtualMachineSpotEvictionAction) Prepare(ctx context.Context, state *VirtualMachineSpotEvictionState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	vmName := request.Target.Attributes["azure-vm.vm.name"]
+	if len(vmName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-vm.vm.name' attribute.", nil)
+	}
+
+	subscriptionId := request.Target.Attributes["azure.subscription.id"]
+	if len(subscriptionId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.subscription.id' attribute.", nil)
+	}
+
+	resourceGroupName := request.Target.Attributes["azure.resource-group.name"]
+	if len(resourceGroupName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.resource-group.name' attribute.", nil)
+	}
+
+	state.SubscriptionId = subscriptionId[0]
+	state.VmName = vmName[0]
+	state.ResourceGroupName = resourceGroupName[0]
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	vm, err := client.Get(ctx, state.ResourceGroupName, state.VmName, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get vm '%s'", state.VmName), err)
+	}
+	if vm.Properties == nil || vm.Properties.Priority == nil || *vm.Properties.Priority != armcompute.VirtualMachinePriorityTypesSpot {
+		return nil, extension_kit.ToError(fmt.Sprintf("Virtual machine '%s' is not a Spot virtual machine. Only Spot virtual machines can be evicted.", state.VmName), nil)
+	}
+	return nil, nil
+}
+
+func (e *virtualMachineSpotEvictionAction) RequiredPermissions(state *VirtualMachineSpotEvictionState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope:      fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s", state.SubscriptionId, state.ResourceGroupName, state.VmName),
+		Operations: []string{"Microsoft.Compute/virtualMachines/simulateEviction/action"},
+	}}
+}
+
+func (e *virtualMachineSpotEvictionAction) Start(ctx context.Context, state *VirtualMachineSpotEvictionState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("simulate the eviction of spot virtual machine '%s' in resource group '%s'", state.VmName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	if _, err := client.SimulateEviction(ctx, state.ResourceGroupName, state.VmName, nil); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to simulate the eviction of vm '%s'", state.VmName), err)
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Simulated the eviction of spot virtual machine '%s'. Azure evicts it in about 30 seconds.", state.VmName),
+		}}),
+	}, nil
+}
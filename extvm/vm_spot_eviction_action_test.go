@@ -0,0 +1,87 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type virtualMachineSpotEvictionApiMock struct {
+	mock.Mock
+}
+
+func (m *virtualMachineSpotEvictionApiMock) Get(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientGetOptions) (armcompute.VirtualMachinesClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return args.Get(0).(armcompute.VirtualMachinesClientGetResponse), args.Error(1)
+}
+
+func (m *virtualMachineSpotEvictionApiMock) SimulateEviction(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientSimulateEvictionOptions) (armcompute.VirtualMachinesClientSimulateEvictionResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return armcompute.VirtualMachinesClientSimulateEvictionResponse{}, args.Error(1)
+}
+
+func vmWithPriority(priority armcompute.VirtualMachinePriorityTypes) armcompute.VirtualMachinesClientGetResponse {
+	return armcompute.VirtualMachinesClientGetResponse{VirtualMachine: armcompute.VirtualMachine{
+		Properties: &armcompute.VirtualMachineProperties{Priority: new(priority)},
+	}}
+}
+
+func TestVirtualMachineSpotEvictionAction(t *testing.T) {
+	tests := []struct {
+		name        string
+		priority    armcompute.VirtualMachinePriorityTypes
+		wantedError error
+	}{
+		{
+			name:     "evicts spot vm",
+			priority: armcompute.VirtualMachinePriorityTypesSpot,
+		},
+		{
+			name:        "refuses regular vm",
+			priority:    armcompute.VirtualMachinePriorityTypesRegular,
+			wantedError: extension_kit.ToError("Virtual machine 'my-vm' is not a Spot virtual machine. Only Spot virtual machines can be evicted.", nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			api := new(virtualMachineSpotEvictionApiMock)
+			api.On("Get", mock.Anything, "rg-42", "my-vm").Return(vmWithPriority(tt.priority), nil)
+			api.On("SimulateEviction", mock.Anything, "rg-42", "my-vm").Return(nil, nil)
+			action := virtualMachineSpotEvictionAction{clientProvider: func(string) (virtualMachineSpotEvictionApi, error) {
+				return api, nil
+			}}
+			state := action.NewEmptyState()
+
+			// When
+			_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{
+				Target: new(action_kit_api.Target{Attributes: map[string][]string{
+					"azure-vm.vm.name":          {"my-vm"},
+					"azure.subscription.id":     {"42"},
+					"azure.resource-group.name": {"rg-42"},
+				}}),
+			})
+
+			// Then
+			if tt.wantedError != nil {
+				assert.EqualError(t, err, tt.wantedError.Error())
+				return
+			}
+			require.NoError(t, err)
+			_, err = action.Start(context.Background(), &state)
+			require.NoError(t, err)
+			api.AssertCalled(t, "SimulateEviction", mock.Anything, "rg-42", "my-vm")
+		})
+	}
+}
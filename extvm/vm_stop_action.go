@@ -0,0 +1,216 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type virtualMachineStopAction struct {
+	clientProvider func(subscriptionId string) (virtualMachineStopApi, error)
+}
+
+var _ action_kit_sdk.Action[VirtualMachineStopState] = (*virtualMachineStopAction)(nil)
+var _ action_kit_sdk.ActionWithStop[VirtualMachineStopState] = (*virtualMachineStopAction)(nil)
+var _ common.ActionWithRequiredPermissions[VirtualMachineStopState] = (*virtualMachineStopAction)(nil)
+
+type VirtualMachineStopState struct {
+	common.ExecutionContextState
+	common.InstanceStopState
+
+	SubscriptionId    string
+	VmName            string
+	ResourceGroupName string
+}
+
+type virtualMachineStopApi interface {
+	BeginPowerOff(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse], error)
+	BeginDeallocate(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachinesClientDeallocateResponse], error)
+	BeginStart(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachinesClientStartResponse], error)
+	InstanceView(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientInstanceViewOptions) (armcompute.VirtualMachinesClientInstanceViewResponse, error)
+}
+
+func NewVirtualMachineStopAction() action_kit_sdk.ActionWithStop[VirtualMachineStopState] {
+	return &virtualMachineStopAction{
+		clientProvider: func(subscriptionId string) (virtualMachineStopApi, error) {
+			return common.GetVirtualMachinesClient(subscriptionId)
+		},
+	}
+}
+
+func (e *virtualMachineStopAction) NewEmptyState() VirtualMachineStopState {
+	return VirtualMachineStopState{}
+}
+
+func (e *virtualMachineStopAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          VirtualMachineStopActionId,
+		Label:       "Stop Virtual Machine",
+		Description: "Power off or deallocate Azure virtual machines for a given duration and start them again afterwards",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDVM,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "vm-name",
+					Description: new("Find azure virtual machine by name"),
+					Query:       "azure-vm.vm.name=\"\"",
+				},
+				{
+					Label:       "vm-id",
+					Description: new("Find azure virtual machine by vm-id"),
+					Query:       "azure-vm.vm.id=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Virtual Machines"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the virtual machines stay stopped. They are started again on stop."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("60s"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  new("Power off keeps the compute resources allocated, deallocate releases them."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("power-off"),
+				Order:        new(2),
+				Required:     new(true),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Power Off",
+						Value: "power-off",
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Deallocate",
+						Value: "deallocate",
+					},
+				}),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (e *virtualMachineStopAction) Prepare(_ context.Context, state *VirtualMachineStopState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	vmName := request.Target.Attributes["azure-vm.vm.name"]
+	if len(vmName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-vm.vm.name' attribute.", nil)
+	}
+
+	subscriptionId := request.Target.Attributes["azure.subscription.id"]
+	if len(subscriptionId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.subscription.id' attribute.", nil)
+	}
+
+	resourceGroupName := request.Target.Attributes["azure.resource-group.name"]
+	if len(resourceGroupName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.resource-group.name' attribute.", nil)
+	}
+
+	mode := extutil.ToString(request.Config["mode"])
+	if _, ok := virtualMachineStopOperations[mode]; !ok {
+		return nil, extension_kit.ToError(fmt.Sprintf("Unknown stop mode '%s'", mode), nil)
+	}
+
+	state.SubscriptionId = subscriptionId[0]
+	state.VmName = vmName[0]
+	state.ResourceGroupName = resourceGroupName[0]
+	state.Mode = mode
+	state.DryRun = common.IsDryRun(request)
+	return nil, nil
+}
+
+func (e *virtualMachineStopAction) Start(ctx context.Context, state *VirtualMachineStopState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("%s virtual machine '%s' in resource group '%s'", state.Mode, state.VmName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	return common.StopInstance(ctx, &stoppableVirtualMachine{client, state}, &state.InstanceStopState)
+}
+
+func (e *virtualMachineStopAction) Stop(ctx context.Context, state *VirtualMachineStopState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("start virtual machine '%s' in resource group '%s'", state.VmName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	return common.RestartInstance(ctx, &stoppableVirtualMachine{client, state}, &state.InstanceStopState)
+}
+
+// stoppableVirtualMachine is the virtual machine of a stop attack.
+type stoppableVirtualMachine struct {
+	client virtualMachineStopApi
+	state  *VirtualMachineStopState
+}
+
+func (m *stoppableVirtualMachine) Name() string {
+	return fmt.Sprintf("virtual machine '%s'", m.state.VmName)
+}
+
+func (m *stoppableVirtualMachine) PowerState(ctx context.Context) (string, error) {
+	instanceView, err := m.client.InstanceView(ctx, m.state.ResourceGroupName, m.state.VmName, nil)
+	return common.PowerStateOf(instanceView.Statuses), err
+}
+
+func (m *stoppableVirtualMachine) BeginStop(ctx context.Context, mode string, resumeToken string) (common.Operation, error) {
+	if mode == "deallocate" {
+		return common.NewOperation(m.client.BeginDeallocate(ctx, m.state.ResourceGroupName, m.state.VmName, &armcompute.VirtualMachinesClientBeginDeallocateOptions{ResumeToken: resumeToken}))
+	}
+	return common.NewOperation(m.client.BeginPowerOff(ctx, m.state.ResourceGroupName, m.state.VmName, &armcompute.VirtualMachinesClientBeginPowerOffOptions{ResumeToken: resumeToken}))
+}
+
+func (m *stoppableVirtualMachine) BeginStart(ctx context.Context) (common.Operation, error) {
+	return common.NewOperation(m.client.BeginStart(ctx, m.state.ResourceGroupName, m.state.VmName, nil))
+}
+
+// virtualMachineStopOperations maps each stop mode to the Azure operation it performs.
+var virtualMachineStopOperations = map[string]string{
+	"power-off":  "Microsoft.Compute/virtualMachines/powerOff/action",
+	"deallocate": "Microsoft.Compute/virtualMachines/deallocate/action",
+}
+
+func (e *virtualMachineStopAction) RequiredPermissions(state *VirtualMachineStopState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s", state.SubscriptionId, state.ResourceGroupName, state.VmName),
+		Operations: []string{
+			"Microsoft.Compute/virtualMachines/instanceView/read",
+			virtualMachineStopOperations[state.Mode],
+			"Microsoft.Compute/virtualMachines/start/action",
+		},
+	}}
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type virtualMachineStopApiMock struct {
+	mock.Mock
+}
+
+func (m *virtualMachineStopApiMock) BeginPowerOff(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *virtualMachineStopApiMock) BeginDeallocate(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachinesClientDeallocateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *virtualMachineStopApiMock) BeginStart(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachinesClientStartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *virtualMachineStopApiMock) InstanceView(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientInstanceViewOptions) (armcompute.VirtualMachinesClientInstanceViewResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return args.Get(0).(armcompute.VirtualMachinesClientInstanceViewResponse), args.Error(1)
+}
+
+func instanceViewWithPowerState(powerState string) armcompute.VirtualMachinesClientInstanceViewResponse {
+	return armcompute.VirtualMachinesClientInstanceViewResponse{
+		VirtualMachineInstanceView: armcompute.VirtualMachineInstanceView{
+			Statuses: []*armcompute.InstanceViewStatus{
+				{Code: new("ProvisioningState/succeeded")},
+				{Code: new("PowerState/" + powerState)},
+			},
+		},
+	}
+}
+
+func newStopActionWith(api *virtualMachineStopApiMock) *virtualMachineStopAction {
+	return &virtualMachineStopAction{clientProvider: func(string) (virtualMachineStopApi, error) {
+		return api, nil
+	}}
+}
+
+func TestVirtualMachineStopAction_Prepare(t *testing.T) {
+	action := NewVirtualMachineStopAction()
+	state := action.NewEmptyState()
+	target := &action_kit_api.Target{Attributes: map[string][]string{
+		"azure-vm.vm.name":          {"my-vm"},
+		"azure.subscription.id":     {"42"},
+		"azure.resource-group.name": {"rg-42"},
+	}}
+
+	_, err := action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{Config: map[string]any{"mode": "deallocate"}, Target: target})
+	require.NoError(t, err)
+	assert.Equal(t, "my-vm", state.VmName)
+	assert.Equal(t, "deallocate", state.Mode)
+
+	_, err = action.Prepare(context.Background(), &state, action_kit_api.PrepareActionRequestBody{Config: map[string]any{"mode": "delete"}, Target: target})
+	assert.EqualError(t, err, extension_kit.ToError("Unknown stop mode 'delete'", nil).Error())
+}
+
+func TestVirtualMachineStopAction_StartPowersOffRunningVm(t *testing.T) {
+	// Given
+	api := new(virtualMachineStopApiMock)
+	api.On("InstanceView", mock.Anything, "rg-42", "my-vm").Return(instanceViewWithPowerState("running"), nil)
+	api.On("BeginPowerOff", mock.Anything, "rg-42", "my-vm").Return(nil, nil)
+	state := &VirtualMachineStopState{SubscriptionId: "42", VmName: "my-vm", ResourceGroupName: "rg-42", InstanceStopState: common.InstanceStopState{Mode: "power-off"}}
+
+	// When
+	_, err := newStopActionWith(api).Start(context.Background(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.False(t, state.AlreadyStopped)
+	api.AssertExpectations(t)
+}
+
+func TestVirtualMachineStopAction_SkipsAlreadyStoppedVm(t *testing.T) {
+	// Given
+	api := new(virtualMachineStopApiMock)
+	api.On("InstanceView", mock.Anything, "rg-42", "my-vm").Return(instanceViewWithPowerState("deallocated"), nil)
+	action := newStopActionWith(api)
+	state := &VirtualMachineStopState{SubscriptionId: "42", VmName: "my-vm", ResourceGroupName: "rg-42", InstanceStopState: common.InstanceStopState{Mode: "deallocate"}}
+
+	// When
+	startResult, err := action.Start(context.Background(), state)
+	require.NoError(t, err)
+	stopResult, err := action.Stop(context.Background(), state)
+	require.NoError(t, err)
+
+	// Then
+	assert.True(t, state.AlreadyStopped)
+	assert.Equal(t, "The virtual machine 'my-vm' is already deallocated. It is skipped and will not be started on stop.", (*startResult.Messages)[0].Message)
+	assert.Equal(t, "The virtual machine 'my-vm' was already stopped before the attack and is not started.", (*stopResult.Messages)[0].Message)
+	api.AssertNotCalled(t, "BeginDeallocate", mock.Anything, mock.Anything, mock.Anything)
+	api.AssertNotCalled(t, "BeginStart", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVirtualMachineStopAction_StopStartsVmAndWaitsUntilRunning(t *testing.T) {
+	// Given
+	api := new(virtualMachineStopApiMock)
+	api.On("BeginStart", mock.Anything, "rg-42", "my-vm").Return(nil, nil)
+	api.On("InstanceView", mock.Anything, "rg-42", "my-vm").Return(instanceViewWithPowerState("running"), nil)
+	state := &VirtualMachineStopState{SubscriptionId: "42", VmName: "my-vm", ResourceGroupName: "rg-42", InstanceStopState: common.InstanceStopState{Mode: "power-off"}}
+
+	// When
+	result, err := newStopActionWith(api).Stop(context.Background(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "The virtual machine 'my-vm' is running again.", (*result.Messages)[0].Message)
+	api.AssertExpectations(t)
+}
+
+func TestVirtualMachineStopAction_StopForwardsStartError(t *testing.T) {
+	// Given
+	api := new(virtualMachineStopApiMock)
+	api.On("BeginStart", mock.Anything, "rg-42", "my-vm").Return(nil, errors.New("quota exceeded"))
+	state := &VirtualMachineStopState{SubscriptionId: "42", VmName: "my-vm", ResourceGroupName: "rg-42", InstanceStopState: common.InstanceStopState{Mode: "deallocate"}}
+
+	// When
+	_, err := newStopActionWith(api).Stop(context.Background(), state)
+
+	// Then
+	assert.EqualError(t, err, extension_kit.ToError("Failed to start the virtual machine 'my-vm'", errors.New("quota exceeded")).Error())
+}
+
+func TestVirtualMachineStopAction_StopFailsIfVmIsNotRunningAfterStopTimeout(t *testing.T) {
+	// Given
+	previous := common.StopTimeout
+	common.StopTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { common.StopTimeout = previous })
+	api := new(virtualMachineStopApiMock)
+	api.On("BeginStart", mock.Anything, "rg-42", "my-vm").Return(nil, nil)
+	api.On("InstanceView", mock.Anything, "rg-42", "my-vm").Return(instanceViewWithPowerState("starting"), nil)
+	state := &VirtualMachineStopState{SubscriptionId: "42", VmName: "my-vm", ResourceGroupName: "rg-42", InstanceStopState: common.InstanceStopState{Mode: "power-off"}}
+
+	// When
+	result, err := newStopActionWith(api).Stop(context.Background(), state)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "The virtual machine 'my-vm' was started but is not running after 50ms. Azure keeps starting it.", result.Error.Title)
+}
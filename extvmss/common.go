@@ -8,3 +8,8 @@ const (
 	TargetIDScaleSet = "com.steadybit.extension_azure.vmss"
 	targetIcon       = "data:image/svg+xml;base64,PHN2ZyB3aWR0aD0iMjQiIGhlaWdodD0iMjQiIHZpZXdCb3g9IjAgMCAyNCAyNCIgZmlsbD0ibm9uZSIgeG1sbnM9Imh0dHA6Ly93d3cudzMub3JnLzIwMDAvc3ZnIj4KPHBhdGggZD0iTTE1LjM5MDggMTUuMjA1MVYxNy4wMDg3TDEzLjgxNSAxNi4wOTI0QzEzLjczOTggMTYuMDQ4NiAxMy42OTQxIDE1Ljk3MDYgMTMuNjk0IDE1Ljg4N1YxNC4yMjdMMTUuMzkwOCAxNS4yMDUxWiIgZmlsbD0iY3VycmVudENvbG9yIi8+CjxwYXRoIGQ9Ik0xNy40MzM1IDE1Ljg4N0MxNy40MzM0IDE1Ljk3MDYgMTcuMzg3OCAxNi4wNDg2IDE3LjMxMjUgMTYuMDkyNEwxNS43MzY3IDE3LjAwODdWMTUuMjA1MUwxNy40MzM1IDE0LjIyN1YxNS44ODdaIiBmaWxsPSJjdXJyZW50Q29sb3IiLz4KPHBhdGggZD0iTTE1LjQzNzUgMTIuOTg4NkMxNS41MTcyIDEyLjk0NDQgMTUuNjE2MiAxMi45NDQyIDE1LjY5NTcgMTIuOTg4NkwxNy4zMzczIDEzLjkwNTlMMTUuNTY2MSAxNC44OTQ1TDEzLjc4NTQgMTMuOTA1OUwxNS40Mzc1IDEyLjk4ODZaIiBmaWxsPSJjdXJyZW50Q29sb3IiLz4KPHBhdGggZmlsbC1ydWxlPSJldmVub2RkIiBjbGlwLXJ1bGU9ImV2ZW5vZGQiIGQ9Ik0yMS4zMzk3IDEwLjg3MTVDMjEuNjg4OCAxMC44NzE3IDIxLjk5OTggMTEuMTQzNyAyMiAxMS41MTI5VjE4LjU2MjVDMjEuOTk5OSAxOC45MzIgMjEuNjg4NyAxOS4yMDM4IDIxLjMzOTcgMTkuMjAzOUgxNi44MThDMTYuODIwOCAyMC4zOTY2IDE2Ljg3NzUgMjEuMTYxNSAxOC4wNDIzIDIxLjMzODNDMTguMjEgMjEuMzYyOSAxOC4zNjM4IDIxLjQ0MjIgMTguNDc1OCAyMS41NjMxQzE4LjU4NzggMjEuNjg0MiAxOC42NTExIDIxLjgzOTEgMTguNjU0OSAyMkgxMi41Mjc4QzEyLjUzMTcgMjEuODM5IDEyLjU5NDkgMjEuNjg0MSAxMi43MDY5IDIxLjU2MzFDMTIuODE5IDIxLjQ0MjEgMTIuOTcyNyAyMS4zNjMgMTMuMTQwNCAyMS4zMzgzQzE0LjMwNzcgMjEuMjA1NiAxNC4zNjMgMjAuNDQwNCAxNC4zNjU3IDE5LjIwMzlIOS43ODI5N0M5LjQzMzgxIDE5LjIwMzggOS4xMjI4MiAxOC45MzE4IDkuMTIyNzEgMTguNTYyNVYxMS41MTI5QzkuMTIyOTUgMTEuMTQzNiA5LjQzMzkzIDEwLjg3MTcgOS43ODI5NyAxMC44NzE1SDIxLjMzOTdaTTEwLjI1ODQgMTEuODQ3NkMxMC4yMDk2IDExLjg0NzYgMTAuMTcxMiAxMS44NjU4IDEwLjE0NzkgMTEuODg2MkMxMC4xMjUxIDExLjkwNjIgMTAuMTE5MyAxMS45MjQ0IDEwLjExOTMgMTEuOTM2M1YxNy45MjRDMTAuMTE5MyAxNy45MzU5IDEwLjEyNTEgMTcuOTU0MiAxMC4xNDc5IDE3Ljk3NDJDMTAuMTcxMyAxNy45OTQ2IDEwLjIwOTcgMTguMDEyNyAxMC4yNTg0IDE4LjAxMjdIMjAuODY1M0MyMC45MTM5IDE4LjAxMjYgMjAuOTUyNSAxNy45OTQ2IDIwLjk3NTggMTcuOTc0MkMyMC45OTgzIDE3Ljk1NDMgMjEuMDAzNCAxNy45MzU5IDIxLjAwMzQgMTcuOTI0VjExLjkzNjNDMjEuMDAzNCAxMS45MjQ1IDIwLjk5ODMgMTEuOTA2MSAyMC45NzU4IDExLjg4NjJDMjAuOTUyNSAxMS44NjU3IDIwLjkxMzkgMTEuODQ3NyAyMC44NjUzIDExLjg0NzZIMTAuMjU4NFoiIGZpbGw9ImN1cnJlbnRDb2xvciIvPgo8cGF0aCBkPSJNMTcuNzc2NSA2LjUzMDI5QzE4LjEyNTYgNi41MzA0NyAxOC40MzY1IDYuODAyNTEgMTguNDM2NyA3LjE3MTY4VjkuOTgzMjJIMTcuNDQwMlY3LjU5NTFDMTcuNDQwMiA3LjU4MzIyIDE3LjQzNTMgNy41NjM5OCAxNy40MTI1IDcuNTQzOThDMTcuMzg5MyA3LjUyMzU5IDE3LjM1MDUgNy41MDY1NCAxNy4zMDIgNy41MDYzN0g2LjY5NTEyQzYuNjQ2MzMgNy41MDYzNyA2LjYwNzA0IDcuNTIzNTggNi41ODM2NSA3LjU0Mzk4QzYuNTYwODYgNy41NjM5OSA2LjU1NjA0IDcuNTgzMTMgNi41NTYwMiA3LjU5NTFWMTMuNTgyOEM2LjU1NjEgMTMuNTk0NiA2LjU2MTE3IDEzLjYxMzEgNi41ODM2NSAxMy42MzI5QzYuNjA3MDcgMTMuNjUzNCA2LjY0NjM3IDEzLjY3MDUgNi42OTUxMiAxMy42NzA1SDguMTQ3MVYxNC44NjI3SDYuMjE5N0M1Ljg3MDU1IDE0Ljg2MjUgNS41NTk1NiAxNC41OTA1IDUuNTU5NDUgMTQuMjIxM1Y3LjE3MTY4QzUuNTU5NjYgNi44MDIzMiA1Ljg3MDY1IDYuNTMwNDIgNi4yMTk3IDYuNTMwMjlIMTcuNzc2NVoiIGZpbGw9ImN1cnJlbnRDb2xvciIvPgo8cGF0aCBkPSJNMTQuMjE3IDJDMTQuNTY2MyAyIDE0Ljg3NzEgMi4yNzIwNyAxNC44NzczIDIuNjQxNFY1LjYzNzE1SDEzLjg4MDdWMy4wNjQ4MUMxMy44ODA3IDMuMDUyOSAxMy44NzYgMy4wMzM3OCAxMy44NTMxIDMuMDEzN0MxMy44Mjk3IDIuOTkzMjUgMTMuNzkxMiAyLjk3NjE3IDEzLjc0MjYgMi45NzYwOEgzLjEzNTY3QzMuMDg2NzEgMi45NzYwOCAzLjA0NzU2IDIuOTkzMjEgMy4wMjQyIDMuMDEzN0MzLjAwMTM3IDMuMDMzNzMgMi45OTY1NyAzLjA1Mjg1IDIuOTk2NTcgMy4wNjQ4MVY5LjA1MjQ3QzIuOTk2NjUgOS4wNjQzNCAzLjAwMTcyIDkuMDgyOCAzLjAyNDIgOS4xMDI2MkMzLjA0NzYgOS4xMjMxNCAzLjA4NjggOS4xNDAyNCAzLjEzNTY3IDkuMTQwMjRINC42MzUyOVYxMC4zMzI0SDIuNjYwMjVDMi4zMTEwOSAxMC4zMzIyIDIuMDAwMTEgMTAuMDYwMyAyIDkuNjkwOTdWMi42NDE0QzIuMDAwMTcgMi4yNzIgMi4zMTExOCAyLjAwMDEzIDIuNjYwMjUgMkgxNC4yMTdaIiBmaWxsPSJjdXJyZW50Q29sb3IiLz4KPHBhdGggZD0iTTExLjg3NDIgOC43NDU3NkMxMS45NTM4IDguNzAxNzMgMTIuMDUyMSA4LjcwMjQyIDEyLjEzMTUgOC43NDY3MkwxMy43NzQgOS42NjNMMTMuMjAwNSA5Ljk4MzIySDEwLjc5NzZMMTAuMjIxMiA5LjY2M0wxMS44NzQyIDguNzQ1NzZaIiBmaWxsPSJjdXJyZW50Q29sb3IiLz4KPHBhdGggZD0iTTguMzE0NzkgNC40MTMxOUM4LjM5NDQ0IDQuMzY4OTcgOC40OTM0NSA0LjM2OTcgOC41NzI5OCA0LjQxNDE2TDEwLjIxNDYgNS4zMzA0NEw5LjY2Mzg3IDUuNjM4MTJINy4yMTYyN0w2LjY2MjczIDUuMzMwNDRMOC4zMTQ3OSA0LjQxMzE5WiIgZmlsbD0iY3VycmVudENvbG9yIi8+Cjwvc3ZnPgo="
 )
+
+const (
+	ScaleSetCapacityActionId  = "com.steadybit.extension_azure.vmss.capacity"
+	ScaleSetInstancesActionId = "com.steadybit.extension_azure.vmss.instances"
+)
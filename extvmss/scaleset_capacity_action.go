@@ -0,0 +1,418 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvmss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type scaleSetCapacityAction struct {
+	clientProvider    func(subscriptionId string) (scaleSetCapacityApi, error)
+	autoscaleProvider func(subscriptionId string) (autoscaleSettingsApi, error)
+}
+
+var _ action_kit_sdk.Action[ScaleSetCapacityState] = (*scaleSetCapacityAction)(nil)
+var _ action_kit_sdk.ActionWithStop[ScaleSetCapacityState] = (*scaleSetCapacityAction)(nil)
+var _ common.ActionWithRequiredPermissions[ScaleSetCapacityState] = (*scaleSetCapacityAction)(nil)
+
+type ScaleSetCapacityState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string
+	ScaleSetName      string
+	ResourceGroupName string
+	ScaleSetId        string
+	OriginalCapacity  int64
+	TargetCapacity    int64
+	DisableAutoscale  bool
+	DryRun            bool
+	// ScaleResumeToken resumes the scaling, which has to finish before the original capacity is restored.
+	ScaleResumeToken string
+	// DisabledAutoscaleSettings are the resource ids of the autoscale settings disabled by Start.
+	DisabledAutoscaleSettings []string
+}
+
+type scaleSetCapacityApi interface {
+	Get(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientGetOptions) (armcompute.VirtualMachineScaleSetsClientGetResponse, error)
+	BeginUpdate(ctx context.Context, resourceGroupName string, vmScaleSetName string, parameters armcompute.VirtualMachineScaleSetUpdate, options *armcompute.VirtualMachineScaleSetsClientBeginUpdateOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientUpdateResponse], error)
+}
+
+type autoscaleSettingsApi interface {
+	NewListBySubscriptionPager(options *armmonitor.AutoscaleSettingsClientListBySubscriptionOptions) *runtime.Pager[armmonitor.AutoscaleSettingsClientListBySubscriptionResponse]
+	Get(ctx context.Context, resourceGroupName string, autoscaleSettingName string, options *armmonitor.AutoscaleSettingsClientGetOptions) (armmonitor.AutoscaleSettingsClientGetResponse, error)
+	CreateOrUpdate(ctx context.Context, resourceGroupName string, autoscaleSettingName string, parameters armmonitor.AutoscaleSettingResource, options *armmonitor.AutoscaleSettingsClientCreateOrUpdateOptions) (armmonitor.AutoscaleSettingsClientCreateOrUpdateResponse, error)
+}
+
+func NewScaleSetCapacityAction() action_kit_sdk.ActionWithStop[ScaleSetCapacityState] {
+	return &scaleSetCapacityAction{
+		clientProvider: func(subscriptionId string) (scaleSetCapacityApi, error) {
+			return common.GetVirtualMachineScaleSetsClient(subscriptionId)
+		},
+		autoscaleProvider: func(subscriptionId string) (autoscaleSettingsApi, error) {
+			cred, err := common.ConnectionAzure()
+			if err != nil {
+				return nil, err
+			}
+			return armmonitor.NewAutoscaleSettingsClient(subscriptionId, cred, common.ArmClientOptions())
+		},
+	}
+}
+
+func (e *scaleSetCapacityAction) NewEmptyState() ScaleSetCapacityState {
+	return ScaleSetCapacityState{}
+}
+
+func (e *scaleSetCapacityAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    ScaleSetCapacityActionId,
+		Label: "Scale Set Capacity",
+		Description: "Scale Azure virtual machine scale sets to a given number of instances or by a percentage for a given duration " +
+			"and restore the original capacity afterwards.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDScaleSet,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "vmss-name",
+					Description: new("Find azure virtual machine scale set by name"),
+					Query:       "azure.vmss.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Virtual Machine Scale Sets"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the scale set keeps the changed capacity."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("60s"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  new("Whether to scale to an absolute number of instances or to reduce the capacity by a percentage."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(common.ScaleModePercent),
+				Order:        new(2),
+				Required:     new(true),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "Reduce by percentage", Value: common.ScaleModePercent},
+					action_kit_api.ExplicitParameterOption{Label: "Absolute instance count", Value: common.ScaleModeAbsolute},
+				}),
+			},
+			{
+				Name:         "capacity",
+				Label:        "Capacity",
+				Description:  new("The number of instances to scale to, or the percentage of instances to remove."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("50"),
+				MinValue:     new(0),
+				Order:        new(3),
+				Required:     new(true),
+			},
+			{
+				Name:         "disableAutoscale",
+				Label:        "Disable Autoscale",
+				Description:  new("Temporarily disable the autoscale settings of the scale set, so autoscale doesn't undo the change."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("true"),
+				Order:        new(4),
+				Required:     new(false),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (e *scaleSetCapacityAction) Prepare(ctx context.Context, state *ScaleSetCapacityState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	scaleSetName := request.Target.Attributes["azure.vmss.name"]
+	if len(scaleSetName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.vmss.name' attribute.", nil)
+	}
+
+	subscriptionId := request.Target.Attributes["azure.subscription.id"]
+	if len(subscriptionId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.subscription.id' attribute.", nil)
+	}
+
+	resourceGroupName := request.Target.Attributes["azure.resource-group.name"]
+	if len(resourceGroupName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.resource-group.name' attribute.", nil)
+	}
+
+	state.SubscriptionId = subscriptionId[0]
+	state.ScaleSetName = scaleSetName[0]
+	state.ResourceGroupName = resourceGroupName[0]
+	state.DisableAutoscale = extutil.ToBool(request.Config["disableAutoscale"])
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	scaleSet, err := client.Get(ctx, state.ResourceGroupName, state.ScaleSetName, nil)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get scale set '%s'", state.ScaleSetName), err)
+	}
+	if scaleSet.SKU == nil || scaleSet.SKU.Capacity == nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to determine the capacity of scale set '%s'", state.ScaleSetName), nil)
+	}
+	state.OriginalCapacity = *scaleSet.SKU.Capacity
+	state.ScaleSetId = common.GetStringValue(scaleSet.ID)
+	if state.ScaleSetId == "" {
+		state.ScaleSetId = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s", state.SubscriptionId, state.ResourceGroupName, state.ScaleSetName)
+	}
+
+	targetCapacity, err := common.TargetCount(state.OriginalCapacity, extutil.ToString(request.Config["mode"]), int64(extutil.ToInt(request.Config["capacity"])), "capacity")
+	if err != nil {
+		return nil, err
+	}
+	if targetCapacity == state.OriginalCapacity {
+		return nil, extension_kit.ToError(fmt.Sprintf("Scale set '%s' already has a capacity of %d.", state.ScaleSetName, targetCapacity), nil)
+	}
+	state.TargetCapacity = targetCapacity
+
+	return &action_kit_api.PrepareResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Scale set '%s' will be scaled from %d to %d instances.", state.ScaleSetName, state.OriginalCapacity, state.TargetCapacity),
+		}}),
+	}, nil
+}
+
+func (e *scaleSetCapacityAction) Start(ctx context.Context, state *ScaleSetCapacityState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		mutations := []string{fmt.Sprintf("scale scale set '%s' in resource group '%s' from %d to %d instances", state.ScaleSetName, state.ResourceGroupName, state.OriginalCapacity, state.TargetCapacity)}
+		if state.DisableAutoscale {
+			mutations = append([]string{fmt.Sprintf("disable the autoscale settings of scale set '%s'", state.ScaleSetName)}, mutations...)
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+
+	messages := make([]action_kit_api.Message, 0)
+	if state.DisableAutoscale {
+		disabled, err := e.disableAutoscale(ctx, state)
+		state.DisabledAutoscaleSettings = disabled
+		if err != nil {
+			e.enableAutoscale(ctx, state)
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to disable the autoscale settings of scale set '%s'", state.ScaleSetName), err)
+		}
+		for _, id := range disabled {
+			messages = append(messages, action_kit_api.Message{
+				Level:   extutil.Ptr(action_kit_api.Info),
+				Message: fmt.Sprintf("Disabled autoscale setting '%s'.", id),
+			})
+		}
+		if len(disabled) == 0 {
+			messages = append(messages, action_kit_api.Message{
+				Level:   extutil.Ptr(action_kit_api.Warn),
+				Message: fmt.Sprintf("No enabled autoscale setting of scale set '%s' was found in subscription %s. Nothing was disabled.", state.ScaleSetName, state.SubscriptionId),
+			})
+		}
+	}
+
+	operation, err := beginScale(ctx, client, state, state.TargetCapacity, "")
+	if err != nil {
+		e.enableAutoscale(ctx, state)
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to scale scale set '%s' to %d instances", state.ScaleSetName, state.TargetCapacity), err)
+	}
+	if operation != nil {
+		if state.ScaleResumeToken, err = operation.ResumeToken(); err != nil {
+			log.Warn().Err(err).Msgf("Failed to get the resume token of the scaling of scale set '%s'.", state.ScaleSetName)
+		}
+	}
+
+	messages = append(messages, action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Started to scale scale set '%s' from %d to %d instances.", state.ScaleSetName, state.OriginalCapacity, state.TargetCapacity),
+	})
+	return &action_kit_api.StartResult{Messages: &messages}, nil
+}
+
+func (e *scaleSetCapacityAction) Stop(ctx context.Context, state *ScaleSetCapacityState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StopResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("scale scale set '%s' in resource group '%s' back to %d instances", state.ScaleSetName, state.ResourceGroupName, state.OriginalCapacity)),
+		}, nil
+	}
+
+	// The autoscale settings are enabled again even if restoring the capacity failed or is still in progress, so that
+	// autoscale can take over. Only restoring the capacity is bounded by the stop timeout.
+	restoreCtx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+	scaleErr := e.restoreCapacity(restoreCtx, state)
+	stillInProgress := common.IsStopTimeout(restoreCtx)
+	if stillInProgress {
+		scaleErr = nil
+	}
+	var autoscaleErr error
+	if failed := e.enableAutoscale(ctx, state); len(failed) > 0 {
+		autoscaleErr = fmt.Errorf("failed to enable the autoscale settings %s again", strings.Join(failed, ", "))
+	}
+	if err := errors.Join(scaleErr, autoscaleErr); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to restore scale set '%s'", state.ScaleSetName), err)
+	}
+	if stillInProgress {
+		return &action_kit_api.StopResult{
+			Messages: new([]action_kit_api.Message{common.StillInProgress(fmt.Sprintf("scaling of scale set '%s' back to %d instances", state.ScaleSetName, state.OriginalCapacity))}),
+		}, nil
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Scale set '%s' has its original capacity of %d instances again.", state.ScaleSetName, state.OriginalCapacity),
+		}}),
+	}, nil
+}
+
+// restoreCapacity scales the scale set back to its original capacity, after the scaling of Start completed.
+func (e *scaleSetCapacityAction) restoreCapacity(ctx context.Context, state *ScaleSetCapacityState) error {
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return fmt.Errorf("failed to initialize azure client for subscription %s: %w", state.SubscriptionId, err)
+	}
+
+	if state.ScaleResumeToken != "" {
+		operation, err := beginScale(ctx, client, state, state.TargetCapacity, state.ScaleResumeToken)
+		if err == nil && operation != nil {
+			err = common.WaitForPrevious(ctx, operation)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("The scaling of scale set '%s' to %d instances did not complete, restoring the original capacity anyway.", state.ScaleSetName, state.TargetCapacity)
+		}
+	}
+
+	operation, err := beginScale(ctx, client, state, state.OriginalCapacity, "")
+	if err == nil && operation != nil {
+		err = operation.Wait(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scale scale set '%s' back to %d instances: %w", state.ScaleSetName, state.OriginalCapacity, err)
+	}
+	return nil
+}
+
+// disableAutoscale disables the enabled autoscale settings that target the scale set and returns their ids. The
+// settings of the whole subscription are listed, as a setting may live in another resource group than its target.
+func (e *scaleSetCapacityAction) disableAutoscale(ctx context.Context, state *ScaleSetCapacityState) ([]string, error) {
+	client, err := e.autoscaleProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	disabled := make([]string, 0)
+	pager := client.NewListBySubscriptionPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return disabled, err
+		}
+		for _, setting := range page.Value {
+			if setting == nil || setting.ID == nil || setting.Properties == nil || !strings.EqualFold(common.GetStringValue(setting.Properties.TargetResourceURI), state.ScaleSetId) {
+				continue
+			}
+			if setting.Properties.Enabled != nil && !*setting.Properties.Enabled {
+				continue
+			}
+			if err := setAutoscaleEnabled(ctx, client, *setting, false); err != nil {
+				return disabled, err
+			}
+			disabled = append(disabled, *setting.ID)
+		}
+	}
+	return disabled, nil
+}
+
+// enableAutoscale enables the autoscale settings disabled by Start again and returns the ids of those that failed.
+func (e *scaleSetCapacityAction) enableAutoscale(ctx context.Context, state *ScaleSetCapacityState) []string {
+	if len(state.DisabledAutoscaleSettings) == 0 {
+		return nil
+	}
+	client, err := e.autoscaleProvider(state.SubscriptionId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to initialize azure client for subscription %s", state.SubscriptionId)
+		return state.DisabledAutoscaleSettings
+	}
+	failed := make([]string, 0)
+	for _, id := range state.DisabledAutoscaleSettings {
+		resourceId, err := arm.ParseResourceID(id)
+		if err == nil {
+			var setting armmonitor.AutoscaleSettingsClientGetResponse
+			if setting, err = client.Get(ctx, resourceId.ResourceGroupName, resourceId.Name, nil); err == nil {
+				err = setAutoscaleEnabled(ctx, client, setting.AutoscaleSettingResource, true)
+			}
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to enable autoscale setting '%s' again.", id)
+			failed = append(failed, id)
+		}
+	}
+	return failed
+}
+
+// setAutoscaleEnabled writes the complete setting back, as the service rejects patches without the profiles.
+func setAutoscaleEnabled(ctx context.Context, client autoscaleSettingsApi, setting armmonitor.AutoscaleSettingResource, enabled bool) error {
+	resourceId, err := arm.ParseResourceID(common.GetStringValue(setting.ID))
+	if err != nil {
+		return err
+	}
+	setting.Properties.Enabled = new(enabled)
+	_, err = client.CreateOrUpdate(ctx, resourceId.ResourceGroupName, resourceId.Name, setting, nil)
+	return err
+}
+
+// beginScale scales the scale set, or resumes that operation if a resume token is given.
+func beginScale(ctx context.Context, client scaleSetCapacityApi, state *ScaleSetCapacityState, capacity int64, resumeToken string) (common.Operation, error) {
+	update := armcompute.VirtualMachineScaleSetUpdate{SKU: &armcompute.SKU{Capacity: new(capacity)}}
+	return common.NewOperation(client.BeginUpdate(ctx, state.ResourceGroupName, state.ScaleSetName, update, &armcompute.VirtualMachineScaleSetsClientBeginUpdateOptions{ResumeToken: resumeToken}))
+}
+
+func (e *scaleSetCapacityAction) RequiredPermissions(state *ScaleSetCapacityState) []common.PermissionRequirement {
+	requirements := []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s", state.SubscriptionId, state.ResourceGroupName, state.ScaleSetName),
+		Operations: []string{
+			"Microsoft.Compute/virtualMachineScaleSets/read",
+			"Microsoft.Compute/virtualMachineScaleSets/write",
+		},
+	}}
+	if state.DisableAutoscale {
+		requirements = append(requirements, common.PermissionRequirement{
+			Scope: fmt.Sprintf("/subscriptions/%s", state.SubscriptionId),
+			Operations: []string{
+				"microsoft.insights/autoscalesettings/read",
+				"microsoft.insights/autoscalesettings/write",
+			},
+		})
+	}
+	return requirements
+}
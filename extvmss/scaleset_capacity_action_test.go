@@ -0,0 +1,184 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvmss
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const scaleSetId = "/subscriptions/42/resourceGroups/rg-42/providers/Microsoft.Compute/virtualMachineScaleSets/vmss-1"
+
+type scaleSetCapacityApiMock struct {
+	mock.Mock
+}
+
+func (m *scaleSetCapacityApiMock) Get(ctx context.Context, resourceGroupName string, vmScaleSetName string, _ *armcompute.VirtualMachineScaleSetsClientGetOptions) (armcompute.VirtualMachineScaleSetsClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName)
+	return args.Get(0).(armcompute.VirtualMachineScaleSetsClientGetResponse), args.Error(1)
+}
+
+func (m *scaleSetCapacityApiMock) BeginUpdate(ctx context.Context, resourceGroupName string, vmScaleSetName string, parameters armcompute.VirtualMachineScaleSetUpdate, _ *armcompute.VirtualMachineScaleSetsClientBeginUpdateOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientUpdateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, *parameters.SKU.Capacity)
+	return nil, args.Error(1)
+}
+
+type autoscaleSettingsApiMock struct {
+	mock.Mock
+	settings []*armmonitor.AutoscaleSettingResource
+}
+
+func (m *autoscaleSettingsApiMock) NewListBySubscriptionPager(_ *armmonitor.AutoscaleSettingsClientListBySubscriptionOptions) *runtime.Pager[armmonitor.AutoscaleSettingsClientListBySubscriptionResponse] {
+	return runtime.NewPager(runtime.PagingHandler[armmonitor.AutoscaleSettingsClientListBySubscriptionResponse]{
+		More: func(armmonitor.AutoscaleSettingsClientListBySubscriptionResponse) bool { return false },
+		Fetcher: func(context.Context, *armmonitor.AutoscaleSettingsClientListBySubscriptionResponse) (armmonitor.AutoscaleSettingsClientListBySubscriptionResponse, error) {
+			return armmonitor.AutoscaleSettingsClientListBySubscriptionResponse{AutoscaleSettingResourceCollection: armmonitor.AutoscaleSettingResourceCollection{Value: m.settings}}, nil
+		},
+	})
+}
+
+func (m *autoscaleSettingsApiMock) Get(ctx context.Context, resourceGroupName string, autoscaleSettingName string, _ *armmonitor.AutoscaleSettingsClientGetOptions) (armmonitor.AutoscaleSettingsClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, autoscaleSettingName)
+	return args.Get(0).(armmonitor.AutoscaleSettingsClientGetResponse), args.Error(1)
+}
+
+func (m *autoscaleSettingsApiMock) CreateOrUpdate(ctx context.Context, resourceGroupName string, autoscaleSettingName string, parameters armmonitor.AutoscaleSettingResource, _ *armmonitor.AutoscaleSettingsClientCreateOrUpdateOptions) (armmonitor.AutoscaleSettingsClientCreateOrUpdateResponse, error) {
+	args := m.Called(ctx, resourceGroupName, autoscaleSettingName, *parameters.Properties.Enabled)
+	return armmonitor.AutoscaleSettingsClientCreateOrUpdateResponse{}, args.Error(1)
+}
+
+func autoscaleSetting(resourceGroup string, name string, target string, enabled bool) *armmonitor.AutoscaleSettingResource {
+	return &armmonitor.AutoscaleSettingResource{
+		ID:   new("/subscriptions/42/resourceGroups/" + resourceGroup + "/providers/microsoft.insights/autoscalesettings/" + name),
+		Name: new(name),
+		Properties: &armmonitor.AutoscaleSetting{
+			Enabled:           new(enabled),
+			TargetResourceURI: new(target),
+		},
+	}
+}
+
+func TestScaleSetCapacityAction_ScalesAndRestores(t *testing.T) {
+	// Given
+	api := new(scaleSetCapacityApiMock)
+	api.On("Get", mock.Anything, "rg-42", "vmss-1").Return(armcompute.VirtualMachineScaleSetsClientGetResponse{VirtualMachineScaleSet: armcompute.VirtualMachineScaleSet{
+		ID:  new(scaleSetId),
+		SKU: &armcompute.SKU{Capacity: new(int64(4))},
+	}}, nil)
+	api.On("BeginUpdate", mock.Anything, "rg-42", "vmss-1", int64(2)).Return(nil, nil)
+	api.On("BeginUpdate", mock.Anything, "rg-42", "vmss-1", int64(4)).Return(nil, nil)
+
+	autoscale := &autoscaleSettingsApiMock{settings: []*armmonitor.AutoscaleSettingResource{
+		autoscaleSetting("rg-autoscale", "vmss-1-autoscale", "/subscriptions/42/resourceGroups/RG-42/providers/Microsoft.Compute/virtualMachineScaleSets/VMSS-1", true),
+		autoscaleSetting("rg-42", "already-disabled", scaleSetId, false),
+		autoscaleSetting("rg-42", "other", scaleSetId+"-other", true),
+	}}
+	autoscale.On("CreateOrUpdate", mock.Anything, "rg-autoscale", "vmss-1-autoscale", false).Return(nil, nil).Once()
+	autoscale.On("Get", mock.Anything, "rg-autoscale", "vmss-1-autoscale").Return(armmonitor.AutoscaleSettingsClientGetResponse{
+		AutoscaleSettingResource: *autoscaleSetting("rg-autoscale", "vmss-1-autoscale", scaleSetId, false),
+	}, nil)
+	autoscale.On("CreateOrUpdate", mock.Anything, "rg-autoscale", "vmss-1-autoscale", true).Return(nil, nil).Once()
+
+	action := &scaleSetCapacityAction{
+		clientProvider:    func(string) (scaleSetCapacityApi, error) { return api, nil },
+		autoscaleProvider: func(string) (autoscaleSettingsApi, error) { return autoscale, nil },
+	}
+	state := action.NewEmptyState()
+	request := action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"mode": common.ScaleModePercent, "capacity": 50, "disableAutoscale": true},
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure.vmss.name":           {"vmss-1"},
+			"azure.subscription.id":     {"42"},
+			"azure.resource-group.name": {"rg-42"},
+		}},
+	}
+
+	// When
+	_, err := action.Prepare(context.Background(), &state, request)
+	require.NoError(t, err)
+	_, err = action.Start(context.Background(), &state)
+	require.NoError(t, err)
+	_, err = action.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, int64(4), state.OriginalCapacity)
+	assert.Equal(t, int64(2), state.TargetCapacity)
+	assert.Equal(t, []string{"/subscriptions/42/resourceGroups/rg-autoscale/providers/microsoft.insights/autoscalesettings/vmss-1-autoscale"}, state.DisabledAutoscaleSettings)
+	api.AssertExpectations(t)
+	autoscale.AssertExpectations(t)
+}
+
+func TestScaleSetCapacityAction_WarnsWithoutAutoscaleSetting(t *testing.T) {
+	// Given
+	api := new(scaleSetCapacityApiMock)
+	api.On("BeginUpdate", mock.Anything, "rg-42", "vmss-1", int64(2)).Return(nil, nil)
+	autoscale := &autoscaleSettingsApiMock{settings: []*armmonitor.AutoscaleSettingResource{
+		autoscaleSetting("rg-42", "other", scaleSetId+"-other", true),
+	}}
+	action := &scaleSetCapacityAction{
+		clientProvider:    func(string) (scaleSetCapacityApi, error) { return api, nil },
+		autoscaleProvider: func(string) (autoscaleSettingsApi, error) { return autoscale, nil },
+	}
+	state := ScaleSetCapacityState{
+		SubscriptionId:    "42",
+		ResourceGroupName: "rg-42",
+		ScaleSetName:      "vmss-1",
+		ScaleSetId:        scaleSetId,
+		OriginalCapacity:  4,
+		TargetCapacity:    2,
+		DisableAutoscale:  true,
+	}
+
+	// When
+	result, err := action.Start(context.Background(), &state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, state.DisabledAutoscaleSettings)
+	assert.Equal(t, action_kit_api.Warn, *(*result.Messages)[0].Level)
+	assert.Equal(t, "No enabled autoscale setting of scale set 'vmss-1' was found in subscription 42. Nothing was disabled.", (*result.Messages)[0].Message)
+	autoscale.AssertNotCalled(t, "CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestScaleSetCapacityAction_StopEnablesAutoscaleEvenIfScalingBackFails(t *testing.T) {
+	// Given
+	api := new(scaleSetCapacityApiMock)
+	api.On("BeginUpdate", mock.Anything, "rg-42", "vmss-1", int64(4)).Return(nil, errors.New("conflict"))
+	autoscale := &autoscaleSettingsApiMock{}
+	autoscale.On("Get", mock.Anything, "rg-42", "vmss-1-autoscale").Return(armmonitor.AutoscaleSettingsClientGetResponse{
+		AutoscaleSettingResource: *autoscaleSetting("rg-42", "vmss-1-autoscale", scaleSetId, false),
+	}, nil)
+	autoscale.On("CreateOrUpdate", mock.Anything, "rg-42", "vmss-1-autoscale", true).Return(nil, nil).Once()
+	action := &scaleSetCapacityAction{
+		clientProvider:    func(string) (scaleSetCapacityApi, error) { return api, nil },
+		autoscaleProvider: func(string) (autoscaleSettingsApi, error) { return autoscale, nil },
+	}
+	state := ScaleSetCapacityState{
+		SubscriptionId:            "42",
+		ResourceGroupName:         "rg-42",
+		ScaleSetName:              "vmss-1",
+		OriginalCapacity:          4,
+		DisabledAutoscaleSettings: []string{"/subscriptions/42/resourceGroups/rg-42/providers/microsoft.insights/autoscalesettings/vmss-1-autoscale"},
+	}
+
+	// When
+	_, err := action.Stop(context.Background(), &state)
+
+	// Then
+	assert.ErrorContains(t, err, "Failed to restore scale set 'vmss-1'")
+	assert.ErrorContains(t, err, "failed to scale scale set 'vmss-1' back to 4 instances: conflict")
+	autoscale.AssertExpectations(t)
+}
@@ -81,6 +81,10 @@ func (d *scaleSetDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDe
 }
 
 func (d *scaleSetDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDScaleSet, d.discoverTargets)
+}
+
+func (d *scaleSetDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
@@ -0,0 +1,322 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvmss
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+type scaleSetInstancesAction struct {
+	clientProvider    func(subscriptionId string) (scaleSetInstancesApi, error)
+	instancesProvider func(subscriptionId string) (scaleSetVMsApi, error)
+	rng               func(n int) []int
+}
+
+var _ action_kit_sdk.Action[ScaleSetInstancesState] = (*scaleSetInstancesAction)(nil)
+var _ action_kit_sdk.ActionWithStatus[ScaleSetInstancesState] = (*scaleSetInstancesAction)(nil)
+var _ common.ActionWithRequiredPermissions[ScaleSetInstancesState] = (*scaleSetInstancesAction)(nil)
+
+type ScaleSetInstancesState struct {
+	common.ExecutionContextState
+	common.OperationTracking
+
+	SubscriptionId    string
+	ScaleSetName      string
+	ResourceGroupName string
+	Action            string
+	InstanceIDs       []string
+	DryRun            bool
+}
+
+type scaleSetInstancesApi interface {
+	BeginRestart(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginRestartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientRestartResponse], error)
+	BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientPowerOffResponse], error)
+	BeginReimage(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginReimageOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientReimageResponse], error)
+	BeginDeleteInstances(ctx context.Context, resourceGroupName string, vmScaleSetName string, vmInstanceIDs armcompute.VirtualMachineScaleSetVMInstanceRequiredIDs, options *armcompute.VirtualMachineScaleSetsClientBeginDeleteInstancesOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientDeleteInstancesResponse], error)
+}
+
+type scaleSetVMsApi interface {
+	NewListPager(resourceGroupName string, virtualMachineScaleSetName string, options *armcompute.VirtualMachineScaleSetVMsClientListOptions) *runtime.Pager[armcompute.VirtualMachineScaleSetVMsClientListResponse]
+}
+
+// scaleSetInstancesOperations maps each action to the Azure operation it performs.
+var scaleSetInstancesOperations = map[string]string{
+	"restart":   "Microsoft.Compute/virtualMachineScaleSets/restart/action",
+	"power-off": "Microsoft.Compute/virtualMachineScaleSets/powerOff/action",
+	"reimage":   "Microsoft.Compute/virtualMachineScaleSets/reimage/action",
+	"delete":    "Microsoft.Compute/virtualMachineScaleSets/delete/action",
+}
+
+func NewScaleSetInstancesAction() action_kit_sdk.ActionWithStatus[ScaleSetInstancesState] {
+	return &scaleSetInstancesAction{
+		clientProvider: func(subscriptionId string) (scaleSetInstancesApi, error) {
+			return common.GetVirtualMachineScaleSetsClient(subscriptionId)
+		},
+		instancesProvider: func(subscriptionId string) (scaleSetVMsApi, error) {
+			return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
+		},
+		rng: rand.Perm,
+	}
+}
+
+func (e *scaleSetInstancesAction) NewEmptyState() ScaleSetInstancesState {
+	return ScaleSetInstancesState{}
+}
+
+func (e *scaleSetInstancesAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    ScaleSetInstancesActionId,
+		Label: "Change Scale Set Instances State",
+		Description: "Restart, power off, reimage or delete a random percentage of the instances of Azure virtual machine scale sets " +
+			"in a single operation, optionally only instances of one availability zone.",
+		Version: extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:    new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDScaleSet,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "vmss-name",
+					Description: new("Find azure virtual machine scale set by name"),
+					Query:       "azure.vmss.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Virtual Machine Scale Sets"),
+		TimeControl: action_kit_api.TimeControlInternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "action",
+				Label:        "Action",
+				Description:  new("The operation to execute for the selected instances"),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("restart"),
+				Order:        new(1),
+				Required:     new(true),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "Restart", Value: "restart"},
+					action_kit_api.ExplicitParameterOption{Label: "Power Off", Value: "power-off"},
+					action_kit_api.ExplicitParameterOption{Label: "Reimage", Value: "reimage"},
+					action_kit_api.ExplicitParameterOption{Label: "Delete", Value: "delete"},
+				}),
+			},
+			{
+				Name:         "percentage",
+				Label:        "Percentage of instances",
+				Description:  new("Percentage (1-100) of the instances to pick at random. At least one instance is picked."),
+				Type:         action_kit_api.ActionParameterTypePercentage,
+				DefaultValue: new("33"),
+				MinValue:     new(1),
+				MaxValue:     new(100),
+				Order:        new(2),
+				Required:     new(true),
+			},
+			{
+				Name:        "zone",
+				Label:       "Zone",
+				Description: new("Only pick instances of this availability zone, e.g. '1'. Leave empty to pick from all instances."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(3),
+				Required:    new(false),
+			},
+			common.DryRunParameter(),
+			common.CompletionTimeoutParameter(),
+		},
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("10s"),
+		}),
+	}
+}
+
+func (e *scaleSetInstancesAction) Prepare(ctx context.Context, state *ScaleSetInstancesState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	scaleSetName := request.Target.Attributes["azure.vmss.name"]
+	if len(scaleSetName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.vmss.name' attribute.", nil)
+	}
+
+	subscriptionId := request.Target.Attributes["azure.subscription.id"]
+	if len(subscriptionId) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.subscription.id' attribute.", nil)
+	}
+
+	resourceGroupName := request.Target.Attributes["azure.resource-group.name"]
+	if len(resourceGroupName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure.resource-group.name' attribute.", nil)
+	}
+
+	action := extutil.ToString(request.Config["action"])
+	if _, ok := scaleSetInstancesOperations[action]; !ok {
+		return nil, extension_kit.ToError(fmt.Sprintf("Unknown action '%s'.", action), nil)
+	}
+
+	percentage := extutil.ToInt(request.Config["percentage"])
+	if percentage < 1 || percentage > 100 {
+		return nil, extension_kit.ToError("The percentage must be between 1 and 100.", nil)
+	}
+
+	state.SubscriptionId = subscriptionId[0]
+	state.ScaleSetName = scaleSetName[0]
+	state.ResourceGroupName = resourceGroupName[0]
+	state.Action = action
+	state.DryRun = common.IsDryRun(request)
+	// Progress is always reported, so only the timeout of the tracking is configurable.
+	state.PrepareTracking(request)
+
+	client, err := e.instancesProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+	zone := strings.TrimSpace(extutil.ToString(request.Config["zone"]))
+	candidates, err := listInstanceIds(ctx, client, state.ResourceGroupName, state.ScaleSetName, zone)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to list the instances of scale set '%s'", state.ScaleSetName), err)
+	}
+	if len(candidates) == 0 {
+		if zone != "" {
+			return nil, extension_kit.ToError(fmt.Sprintf("Scale set '%s' has no instances in zone '%s'.", state.ScaleSetName, zone), nil)
+		}
+		return nil, extension_kit.ToError(fmt.Sprintf("Scale set '%s' has no instances.", state.ScaleSetName), nil)
+	}
+
+	sampleSize := min(max(int(math.Ceil(float64(len(candidates))*float64(percentage)/100.0)), 1), len(candidates))
+	perm := e.rng(len(candidates))
+	state.InstanceIDs = make([]string, 0, sampleSize)
+	for i := 0; i < sampleSize; i++ {
+		state.InstanceIDs = append(state.InstanceIDs, candidates[perm[i]])
+	}
+	slices.Sort(state.InstanceIDs)
+
+	return &action_kit_api.PrepareResult{
+		Messages: new([]action_kit_api.Message{{
+			Level: extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Selected %d of %d instance(s) (%d%%) of scale set '%s' to %s: %v",
+				sampleSize, len(candidates), percentage, state.ScaleSetName, state.Action, state.InstanceIDs),
+		}}),
+	}, nil
+}
+
+// listInstanceIds returns the instance ids of the scale set, restricted to the zone if one is given.
+func listInstanceIds(ctx context.Context, client scaleSetVMsApi, resourceGroupName string, scaleSetName string, zone string) ([]string, error) {
+	ids := make([]string, 0)
+	pager := client.NewListPager(resourceGroupName, scaleSetName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range page.Value {
+			if instance == nil || instance.InstanceID == nil {
+				continue
+			}
+			if zone != "" && !slices.ContainsFunc(instance.Zones, func(z *string) bool { return common.GetStringValue(z) == zone }) {
+				continue
+			}
+			ids = append(ids, *instance.InstanceID)
+		}
+	}
+	return ids, nil
+}
+
+func (e *scaleSetInstancesAction) Start(ctx context.Context, state *ScaleSetInstancesState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		return &action_kit_api.StartResult{
+			Messages: common.DryRunMessages(fmt.Sprintf("%s instances %v of scale set '%s' in resource group '%s'", state.Action, state.InstanceIDs, state.ScaleSetName, state.ResourceGroupName)),
+		}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+
+	operation, err := beginInstancesChange(ctx, client, state, "")
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to %s instances %v of scale set '%s'", state.Action, state.InstanceIDs, state.ScaleSetName), err)
+	}
+	if err := state.Track(ctx, operation); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to track the %s of instances of scale set '%s'", state.Action, state.ScaleSetName), err)
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Started to %s instances %v of scale set '%s'.", state.Action, state.InstanceIDs, state.ScaleSetName),
+		}}),
+	}, nil
+}
+
+func (e *scaleSetInstancesAction) Status(ctx context.Context, state *ScaleSetInstancesState) (*action_kit_api.StatusResult, error) {
+	if !state.IsTracking() {
+		return &action_kit_api.StatusResult{Completed: true}, nil
+	}
+
+	client, err := e.clientProvider(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", state.SubscriptionId), err)
+	}
+
+	operation, err := beginInstancesChange(ctx, client, state, state.ResumeToken)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to resume the %s of instances of scale set '%s'", state.Action, state.ScaleSetName), err)
+	}
+	return state.PollTracked(ctx, operation, fmt.Sprintf("%s of %d instance(s) of scale set '%s'", state.Action, len(state.InstanceIDs), state.ScaleSetName), nil), nil
+}
+
+// beginInstancesChange changes the selected instances, or resumes that operation if a resume token is given.
+func beginInstancesChange(ctx context.Context, client scaleSetInstancesApi, state *ScaleSetInstancesState, resumeToken string) (common.Operation, error) {
+	instanceIds := make([]*string, 0, len(state.InstanceIDs))
+	for _, id := range state.InstanceIDs {
+		instanceIds = append(instanceIds, new(id))
+	}
+	switch state.Action {
+	case "restart":
+		return common.NewOperation(client.BeginRestart(ctx, state.ResourceGroupName, state.ScaleSetName, &armcompute.VirtualMachineScaleSetsClientBeginRestartOptions{
+			ResumeToken:   resumeToken,
+			VMInstanceIDs: &armcompute.VirtualMachineScaleSetVMInstanceIDs{InstanceIDs: instanceIds},
+		}))
+	case "power-off":
+		return common.NewOperation(client.BeginPowerOff(ctx, state.ResourceGroupName, state.ScaleSetName, &armcompute.VirtualMachineScaleSetsClientBeginPowerOffOptions{
+			ResumeToken:   resumeToken,
+			VMInstanceIDs: &armcompute.VirtualMachineScaleSetVMInstanceIDs{InstanceIDs: instanceIds},
+		}))
+	case "reimage":
+		return common.NewOperation(client.BeginReimage(ctx, state.ResourceGroupName, state.ScaleSetName, &armcompute.VirtualMachineScaleSetsClientBeginReimageOptions{
+			ResumeToken:            resumeToken,
+			VMScaleSetReimageInput: &armcompute.VirtualMachineScaleSetReimageParameters{InstanceIDs: instanceIds},
+		}))
+	case "delete":
+		return common.NewOperation(client.BeginDeleteInstances(ctx, state.ResourceGroupName, state.ScaleSetName, armcompute.VirtualMachineScaleSetVMInstanceRequiredIDs{InstanceIDs: instanceIds}, &armcompute.VirtualMachineScaleSetsClientBeginDeleteInstancesOptions{
+			ResumeToken: resumeToken,
+		}))
+	default:
+		return nil, fmt.Errorf("unknown action '%s'", state.Action)
+	}
+}
+
+func (e *scaleSetInstancesAction) RequiredPermissions(state *ScaleSetInstancesState) []common.PermissionRequirement {
+	operation, ok := scaleSetInstancesOperations[state.Action]
+	if !ok {
+		return nil
+	}
+	return []common.PermissionRequirement{{
+		Scope:      fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s", state.SubscriptionId, state.ResourceGroupName, state.ScaleSetName),
+		Operations: []string{"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/read", operation},
+	}}
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extvmss
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type scaleSetInstancesApiMock struct {
+	mock.Mock
+}
+
+func instanceIdsOf(ids []*string) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, *id)
+	}
+	return result
+}
+
+func (m *scaleSetInstancesApiMock) BeginRestart(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginRestartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientRestartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceIdsOf(options.VMInstanceIDs.InstanceIDs))
+	return nil, args.Error(1)
+}
+
+func (m *scaleSetInstancesApiMock) BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientPowerOffResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceIdsOf(options.VMInstanceIDs.InstanceIDs))
+	return nil, args.Error(1)
+}
+
+func (m *scaleSetInstancesApiMock) BeginReimage(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginReimageOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientReimageResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceIdsOf(options.VMScaleSetReimageInput.InstanceIDs))
+	return nil, args.Error(1)
+}
+
+func (m *scaleSetInstancesApiMock) BeginDeleteInstances(ctx context.Context, resourceGroupName string, vmScaleSetName string, vmInstanceIDs armcompute.VirtualMachineScaleSetVMInstanceRequiredIDs, _ *armcompute.VirtualMachineScaleSetsClientBeginDeleteInstancesOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientDeleteInstancesResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceIdsOf(vmInstanceIDs.InstanceIDs))
+	return nil, args.Error(1)
+}
+
+type scaleSetVMsApiMock struct {
+	instances []*armcompute.VirtualMachineScaleSetVM
+}
+
+func (m *scaleSetVMsApiMock) NewListPager(string, string, *armcompute.VirtualMachineScaleSetVMsClientListOptions) *runtime.Pager[armcompute.VirtualMachineScaleSetVMsClientListResponse] {
+	return runtime.NewPager(runtime.PagingHandler[armcompute.VirtualMachineScaleSetVMsClientListResponse]{
+		More: func(armcompute.VirtualMachineScaleSetVMsClientListResponse) bool { return false },
+		Fetcher: func(context.Context, *armcompute.VirtualMachineScaleSetVMsClientListResponse) (armcompute.VirtualMachineScaleSetVMsClientListResponse, error) {
+			return armcompute.VirtualMachineScaleSetVMsClientListResponse{VirtualMachineScaleSetVMListResult: armcompute.VirtualMachineScaleSetVMListResult{Value: m.instances}}, nil
+		},
+	})
+}
+
+func scaleSetInstance(id string, zone string) *armcompute.VirtualMachineScaleSetVM {
+	return &armcompute.VirtualMachineScaleSetVM{InstanceID: new(id), Zones: []*string{new(zone)}}
+}
+
+func TestScaleSetInstancesAction_PrepareAndStart(t *testing.T) {
+	instances := []*armcompute.VirtualMachineScaleSetVM{
+		scaleSetInstance("0", "1"), scaleSetInstance("1", "2"), scaleSetInstance("2", "1"), scaleSetInstance("3", "3"), scaleSetInstance("4", "1"),
+	}
+	tests := []struct {
+		name          string
+		config        map[string]any
+		expectedIds   []string
+		expectedError string
+	}{
+		{name: "restart a percentage of all instances", config: map[string]any{"action": "restart", "percentage": 40}, expectedIds: []string{"0", "1"}},
+		{name: "power off instances of a zone", config: map[string]any{"action": "power-off", "percentage": 50, "zone": "1"}, expectedIds: []string{"0", "2"}},
+		{name: "reimage at least one instance", config: map[string]any{"action": "reimage", "percentage": 1}, expectedIds: []string{"0"}},
+		{name: "delete all instances of a zone", config: map[string]any{"action": "delete", "percentage": 100, "zone": "2"}, expectedIds: []string{"1"}},
+		{name: "zone without instances", config: map[string]any{"action": "restart", "percentage": 50, "zone": "4"}, expectedError: "Scale set 'vmss-1' has no instances in zone '4'."},
+		{name: "unknown action", config: map[string]any{"action": "explode", "percentage": 50}, expectedError: "Unknown action 'explode'."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			api := new(scaleSetInstancesApiMock)
+			for _, method := range []string{"BeginRestart", "BeginPowerOff", "BeginReimage", "BeginDeleteInstances"} {
+				api.On(method, mock.Anything, "rg-42", "vmss-1", tt.expectedIds).Return(nil, nil).Maybe()
+			}
+			action := &scaleSetInstancesAction{
+				clientProvider:    func(string) (scaleSetInstancesApi, error) { return api, nil },
+				instancesProvider: func(string) (scaleSetVMsApi, error) { return &scaleSetVMsApiMock{instances: instances}, nil },
+				rng: func(n int) []int {
+					perm := make([]int, n)
+					for i := range perm {
+						perm[i] = i
+					}
+					return perm
+				},
+			}
+			state := action.NewEmptyState()
+			request := action_kit_api.PrepareActionRequestBody{
+				Config: tt.config,
+				Target: &action_kit_api.Target{Attributes: map[string][]string{
+					"azure.vmss.name":           {"vmss-1"},
+					"azure.subscription.id":     {"42"},
+					"azure.resource-group.name": {"rg-42"},
+				}},
+			}
+
+			// When
+			_, err := action.Prepare(context.Background(), &state, request)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			_, err = action.Start(context.Background(), &state)
+			require.NoError(t, err)
+
+			// Then
+			assert.Equal(t, tt.expectedIds, state.InstanceIDs)
+			assert.Equal(t, 15*time.Minute, state.CompletionTimeout)
+			require.Len(t, api.Calls, 1)
+		})
+	}
+}
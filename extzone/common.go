@@ -0,0 +1,10 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extzone
+
+const (
+	ZoneOutageActionId = "com.steadybit.extension_azure.zone.outage"
+	actionIcon         = "data:image/svg+xml;base64,PHN2ZyB3aWR0aD0iMjQiIGhlaWdodD0iMjQiIHZpZXdCb3g9IjAgMCAyNCAyNCIgZmlsbD0ibm9uZSIgeG1sbnM9Imh0dHA6Ly93d3cudzMub3JnLzIwMDAvc3ZnIj48cGF0aCBkPSJNMTIgMkM2LjQ4IDIgMiA2LjQ4IDIgMTJzNC40OCAxMCAxMCAxMCAxMC00LjQ4IDEwLTEwUzE3LjUyIDIgMTIgMlptMCAxLjVjMS4xNCAwIDIuNDMgMS43MyAzLjEyIDQuNUg4Ljg4QzkuNTcgNS4yMyAxMC44NiAzLjUgMTIgMy41Wm0tMy40Mi43QzguMDUgNS4yIDcuNjYgNi41IDcuNCA4SDQuNjRhOC41MyA4LjUzIDAgMCAxIDMuOTQtMy44Wm02Ljg0IDBBOC41MyA4LjUzIDAgMCAxIDE5LjM2IDhIMTYuNmMtLjI2LTEuNS0uNjUtMi44LTEuMTgtMy44Wk0zLjUgMTJjMC0uNy4wOS0xLjM3LjI1LTIuNWgzLjQxYTIwIDIwIDAgMCAwIDAgNUgzLjc1QTguNTIgOC41MiAwIDAgMSAzLjUgMTJabTUuMTUgMi41YTE4LjQgMTguNCAwIDAgMSAwLTVoNi43YTE4LjQgMTguNCAwIDAgMSAwIDVoLTYuN1ptOC4xOSAwYTIwIDIwIDAgMCAwIDAtNWgzLjQxYy4xNiAxLjEzLjI1IDEuOC4yNSAyLjVzLS4wOSAxLjM3LS4yNSAyLjVoLTMuNDFaTTQuNjQgMTZINy40Yy4yNiAxLjUuNjUgMi44IDEuMTggMy44QTguNTMgOC41MyAwIDAgMSA0LjY0IDE2Wm00LjI0IDBoNi4yNGMtLjY5IDIuNzctMS45OCA0LjUtMy4xMiA0LjVzLTIuNDMtMS43My0zLjEyLTQuNVptNi41NCAzLjhjLjUzLTEgLjkyLTIuMyAxLjE4LTMuOGgyLjc2YTguNTMgOC41MyAwIDAgMS0zLjk0IDMuOFoiIGZpbGw9ImN1cnJlbnRDb2xvciIvPjxwYXRoIGQ9Ik00IDRsMTYgMTYiIHN0cm9rZT0iY3VycmVudENvbG9yIiBzdHJva2Utd2lkdGg9IjEuNSIgc3Ryb2tlLWxpbmVjYXA9InJvdW5kIi8+PC9zdmc+"
+)
@@ -0,0 +1,608 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extzone
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/steadybit/extension-azure/extscalesetinstance"
+	"github.com/steadybit/extension-azure/extvm"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	outageModeDeallocate = "deallocate"
+	outageModePowerOff   = "power-off"
+)
+
+var (
+	locationPattern      = regexp.MustCompile(`^[a-z0-9]+$`)
+	zonePattern          = regexp.MustCompile(`^[0-9]+$`)
+	resourceGroupPattern = regexp.MustCompile(`^[-\w.()]+$`)
+)
+
+// aksNodePoolAttribute is the tag AKS puts on the scale sets backing its node pools, as discovered by the scale set
+// instance discovery.
+const aksNodePoolAttribute = "azure-scale-set.label.aks-managed-poolname"
+
+type zoneOutageAction struct {
+	resourceGraphProvider   func() (common.ArmResourceGraphApi, error)
+	virtualMachinesProvider func(subscriptionId string) (zoneVirtualMachinesApi, error)
+	scaleSetsProvider       func(subscriptionId string) (zoneScaleSetsApi, error)
+	scaleSetVMsProvider     func(subscriptionId string) (zoneScaleSetVMsApi, error)
+}
+
+var _ action_kit_sdk.Action[ZoneOutageState] = (*zoneOutageAction)(nil)
+var _ action_kit_sdk.ActionWithStop[ZoneOutageState] = (*zoneOutageAction)(nil)
+var _ common.ActionWithRequiredPermissions[ZoneOutageState] = (*zoneOutageAction)(nil)
+
+type ZoneOutageState struct {
+	common.ExecutionContextState
+
+	Location        string
+	Zone            string
+	Mode            string
+	DryRun          bool
+	VirtualMachines []ZoneOutageVirtualMachine
+	ScaleSets       []ZoneOutageScaleSet
+}
+
+// ZoneOutageVirtualMachine is a virtual machine taken down by the outage. Error is set if Start failed to stop it,
+// so Stop leaves it alone.
+type ZoneOutageVirtualMachine struct {
+	SubscriptionId    string
+	ResourceGroupName string
+	Name              string
+	ResumeToken       string
+	Error             string
+}
+
+// ZoneOutageScaleSet holds the instances of a scale set in the zone, which are stopped and started in one operation.
+// NodePool is the AKS node pool the scale set backs, if any.
+type ZoneOutageScaleSet struct {
+	SubscriptionId    string
+	ResourceGroupName string
+	Name              string
+	NodePool          string
+	InstanceIDs       []string
+	ResumeToken       string
+	Error             string
+}
+
+type zoneVirtualMachinesApi interface {
+	InstanceView(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientInstanceViewOptions) (armcompute.VirtualMachinesClientInstanceViewResponse, error)
+	BeginDeallocate(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachinesClientDeallocateResponse], error)
+	BeginPowerOff(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse], error)
+	BeginStart(ctx context.Context, resourceGroupName string, vmName string, options *armcompute.VirtualMachinesClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachinesClientStartResponse], error)
+}
+
+type zoneScaleSetsApi interface {
+	BeginDeallocate(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientDeallocateResponse], error)
+	BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientPowerOffResponse], error)
+	BeginStart(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientStartResponse], error)
+}
+
+type zoneScaleSetVMsApi interface {
+	extscalesetinstance.AzureVirtualMachineScaleSetVMsClient
+	GetInstanceView(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewOptions) (armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse, error)
+}
+
+func NewZoneOutageAction() action_kit_sdk.ActionWithStop[ZoneOutageState] {
+	return &zoneOutageAction{
+		resourceGraphProvider: func() (common.ArmResourceGraphApi, error) {
+			return common.GetClientByCredentials()
+		},
+		virtualMachinesProvider: func(subscriptionId string) (zoneVirtualMachinesApi, error) {
+			return common.GetVirtualMachinesClient(subscriptionId)
+		},
+		scaleSetsProvider: func(subscriptionId string) (zoneScaleSetsApi, error) {
+			return common.GetVirtualMachineScaleSetsClient(subscriptionId)
+		},
+		scaleSetVMsProvider: func(subscriptionId string) (zoneScaleSetVMsApi, error) {
+			return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
+		},
+	}
+}
+
+func (e *zoneOutageAction) NewEmptyState() ZoneOutageState {
+	return ZoneOutageState{}
+}
+
+func (e *zoneOutageAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:    ZoneOutageActionId,
+		Label: "Availability Zone Outage",
+		Description: "Simulates the outage of an availability zone by deallocating or powering off every running virtual machine and scale set " +
+			"instance in the zone for a given duration and starting them again afterwards. The resources are selected by the zone, location " +
+			"and, optionally, resource groups of the virtual machine and scale set instance discoveries.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(actionIcon),
+		Technology:  new("Azure"),
+		Category:    new("Availability Zones"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Attack,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("How long the zone stays down."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("5m"),
+				Order:        new(1),
+				Required:     new(true),
+			},
+			{
+				Name:        "location",
+				Label:       "Location",
+				Description: new("The Azure location of the zone, e.g. 'westeurope'."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Order:       new(2),
+				Required:    new(true),
+			},
+			{
+				Name:         "zone",
+				Label:        "Zone",
+				Description:  new("The availability zone to take down, e.g. '1'."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("1"),
+				Order:        new(3),
+				Required:     new(true),
+			},
+			{
+				Name:        "resourceGroups",
+				Label:       "Resource Groups",
+				Description: new("Only resources of these resource groups are taken down, all of the location if empty. AKS node pools live in the node resource group of their cluster."),
+				Type:        action_kit_api.ActionParameterTypeStringArray,
+				Order:       new(4),
+				Required:    new(false),
+			},
+			{
+				Name:         "mode",
+				Label:        "Mode",
+				Description:  new("Deallocate releases the compute resources, power off keeps them allocated and billed."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(outageModeDeallocate),
+				Order:        new(5),
+				Required:     new(true),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "Deallocate", Value: outageModeDeallocate},
+					action_kit_api.ExplicitParameterOption{Label: "Power Off", Value: outageModePowerOff},
+				}),
+			},
+			{
+				Name:         "includeAksNodePools",
+				Label:        "Include AKS node pools",
+				Description:  new("Also take down the machines of AKS node pools in the zone."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("false"),
+				Order:        new(6),
+				Required:     new(false),
+			},
+			{
+				Name:         "maxInstances",
+				Label:        "Max instances",
+				Description:  new("Safety cap: the attack fails without changing anything if more instances are in the zone."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("10"),
+				MinValue:     new(1),
+				Order:        new(7),
+				Required:     new(true),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (e *zoneOutageAction) Prepare(ctx context.Context, state *ZoneOutageState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	state.Location = strings.ToLower(strings.TrimSpace(extutil.ToString(request.Config["location"])))
+	if !locationPattern.MatchString(state.Location) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Invalid location '%s'.", state.Location), nil)
+	}
+	state.Zone = strings.TrimSpace(extutil.ToString(request.Config["zone"]))
+	if !zonePattern.MatchString(state.Zone) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Invalid zone '%s'.", state.Zone), nil)
+	}
+	resourceGroups := make([]string, 0)
+	for _, resourceGroup := range extutil.ToStringArray(request.Config["resourceGroups"]) {
+		resourceGroup = strings.TrimSpace(resourceGroup)
+		if resourceGroup == "" {
+			continue
+		}
+		if !resourceGroupPattern.MatchString(resourceGroup) {
+			return nil, extension_kit.ToError(fmt.Sprintf("Invalid resource group '%s'.", resourceGroup), nil)
+		}
+		resourceGroups = append(resourceGroups, resourceGroup)
+	}
+	state.Mode = extutil.ToString(request.Config["mode"])
+	if state.Mode != outageModeDeallocate && state.Mode != outageModePowerOff {
+		return nil, extension_kit.ToError(fmt.Sprintf("Unknown mode '%s'.", state.Mode), nil)
+	}
+	maxInstances := extutil.ToInt(request.Config["maxInstances"])
+	if maxInstances < 1 {
+		return nil, extension_kit.ToError("maxInstances must be at least 1.", nil)
+	}
+	state.DryRun = common.IsDryRun(request)
+
+	client, err := e.resourceGraphProvider()
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to initialize azure resource graph client", err)
+	}
+	// Resources which are not running are left alone, so that Start does not stop them and Stop does not start them.
+	skipped := make([]string, 0)
+	vms, err := extvm.GetAllVirtualMachines(ctx, client)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find the virtual machines in zone %s of %s", state.Zone, state.Location), err)
+	}
+	state.VirtualMachines = make([]ZoneOutageVirtualMachine, 0)
+	for _, target := range vms {
+		if !inScope(attribute(target.Attributes, "azure.location"), attribute(target.Attributes, "azure.resource-group.name"), state.Location, resourceGroups) ||
+			!slices.Contains(target.Attributes["azure.zone"], state.Zone) {
+			continue
+		}
+		vm := ZoneOutageVirtualMachine{
+			SubscriptionId:    attribute(target.Attributes, "azure.subscription.id"),
+			ResourceGroupName: attribute(target.Attributes, "azure.resource-group.name"),
+			Name:              target.Label,
+		}
+		powerState, err := e.virtualMachinePowerState(ctx, vm)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to get the instance view of virtual machine %s", vm.Name), err)
+		}
+		if powerState != "running" {
+			skipped = append(skipped, fmt.Sprintf("virtual machine '%s' (%s)", vm.Name, powerState))
+			continue
+		}
+		state.VirtualMachines = append(state.VirtualMachines, vm)
+	}
+
+	scaleSets, err := extscalesetinstance.GetAllScaleSets(ctx, client)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find the scale sets in zone %s of %s", state.Zone, state.Location), err)
+	}
+	includeAksNodePools := extutil.ToBool(request.Config["includeAksNodePools"])
+	state.ScaleSets = make([]ZoneOutageScaleSet, 0)
+	for _, item := range scaleSets {
+		if !inScope(item.Location, item.ResourceGroupName, state.Location, resourceGroups) {
+			continue
+		}
+		scaleSet := ZoneOutageScaleSet{
+			SubscriptionId:    item.SubscriptionId,
+			ResourceGroupName: item.ResourceGroupName,
+			Name:              item.Name,
+			NodePool:          attribute(item.Attributes, aksNodePoolAttribute),
+		}
+		if scaleSet.NodePool != "" && !includeAksNodePools {
+			continue
+		}
+		stopped, err := e.selectInstancesInZone(ctx, item, &scaleSet, state.Zone)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to list the instances of scale set '%s'", scaleSet.Name), err)
+		}
+		skipped = append(skipped, stopped...)
+		if len(scaleSet.InstanceIDs) > 0 {
+			state.ScaleSets = append(state.ScaleSets, scaleSet)
+		}
+	}
+
+	total := state.instanceCount()
+	if total == 0 {
+		return nil, extension_kit.ToError(fmt.Sprintf("There are no running virtual machines or scale set instances in zone %s of %s.", state.Zone, state.Location), nil)
+	}
+	if total > maxInstances {
+		return nil, extension_kit.ToError(fmt.Sprintf("Zone %s of %s has %d instances, more than the maximum of %d. Increase 'Max instances' or limit the resource groups.", state.Zone, state.Location, total, maxInstances), nil)
+	}
+
+	messages := []action_kit_api.Message{{
+		Level: extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Zone %s of %s will be taken down: %d virtual machine(s) and %d instance(s) of %d scale set(s).",
+			state.Zone, state.Location, len(state.VirtualMachines), total-len(state.VirtualMachines), len(state.ScaleSets)),
+	}}
+	if len(skipped) > 0 {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Warn),
+			Message: fmt.Sprintf("Resources in zone %s of %s which are not running are left alone: %s.", state.Zone, state.Location, strings.Join(skipped, ", ")),
+		})
+	}
+	return &action_kit_api.PrepareResult{Messages: &messages}, nil
+}
+
+func (s *ZoneOutageState) instanceCount() int {
+	count := len(s.VirtualMachines)
+	for _, scaleSet := range s.ScaleSets {
+		count += len(scaleSet.InstanceIDs)
+	}
+	return count
+}
+
+// inScope tells whether a discovered resource is in the location of the outage and in one of its resource groups, if
+// any are given.
+func inScope(location string, resourceGroup string, outageLocation string, outageResourceGroups []string) bool {
+	if !strings.EqualFold(location, outageLocation) {
+		return false
+	}
+	return len(outageResourceGroups) == 0 || slices.ContainsFunc(outageResourceGroups, func(outageResourceGroup string) bool {
+		return strings.EqualFold(resourceGroup, outageResourceGroup)
+	})
+}
+
+func attribute(attributes map[string][]string, name string) string {
+	if values := attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (e *zoneOutageAction) virtualMachinePowerState(ctx context.Context, vm ZoneOutageVirtualMachine) (string, error) {
+	client, err := e.virtualMachinesProvider(vm.SubscriptionId)
+	if err != nil {
+		return "", err
+	}
+	view, err := client.InstanceView(ctx, vm.ResourceGroupName, vm.Name, nil)
+	if err != nil {
+		return "", err
+	}
+	return common.PowerStateOf(view.Statuses), nil
+}
+
+// selectInstancesInZone adds the running instances of the scale set in the zone, as discovered by the scale set
+// instance discovery, and returns the ones which are not running. Members of flexible scale sets are not listed
+// here, they are discovered as virtual machines.
+func (e *zoneOutageAction) selectInstancesInZone(ctx context.Context, item extscalesetinstance.ScaleSet, scaleSet *ZoneOutageScaleSet, zone string) ([]string, error) {
+	client, err := e.scaleSetVMsProvider(scaleSet.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := extscalesetinstance.GetAllScaleSetInstances(ctx, client, item)
+	if err != nil {
+		return nil, err
+	}
+	skipped := make([]string, 0)
+	for _, instance := range instances {
+		if !slices.Contains(instance.Attributes["azure.zone"], zone) {
+			continue
+		}
+		instanceId := attribute(instance.Attributes, "azure-scale-set-instance.id")
+		view, err := client.GetInstanceView(ctx, scaleSet.ResourceGroupName, scaleSet.Name, instanceId, nil)
+		if err != nil {
+			return nil, err
+		}
+		if powerState := common.PowerStateOf(view.Statuses); powerState != "running" {
+			skipped = append(skipped, fmt.Sprintf("instance %s of scale set '%s' (%s)", instanceId, scaleSet.Name, powerState))
+			continue
+		}
+		scaleSet.InstanceIDs = append(scaleSet.InstanceIDs, instanceId)
+	}
+	return skipped, nil
+}
+
+func (e *zoneOutageAction) Start(ctx context.Context, state *ZoneOutageState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.VirtualMachines)+len(state.ScaleSets))
+		for _, vm := range state.VirtualMachines {
+			mutations = append(mutations, fmt.Sprintf("%s virtual machine '%s' in resource group '%s'", state.Mode, vm.Name, vm.ResourceGroupName))
+		}
+		for _, scaleSet := range state.ScaleSets {
+			mutations = append(mutations, fmt.Sprintf("%s instances %v of scale set '%s' in resource group '%s'", state.Mode, scaleSet.InstanceIDs, scaleSet.Name, scaleSet.ResourceGroupName))
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	messages := make([]action_kit_api.Message, 0)
+	failed := 0
+	for i := range state.VirtualMachines {
+		vm := &state.VirtualMachines[i]
+		token, err := e.stopVirtualMachine(ctx, state.Mode, vm)
+		vm.ResumeToken = token
+		messages = append(messages, common.Outcome(err, fmt.Sprintf("%s of virtual machine '%s'", state.Mode, vm.Name)))
+		if err != nil {
+			vm.Error = err.Error()
+			failed++
+		}
+	}
+	for i := range state.ScaleSets {
+		scaleSet := &state.ScaleSets[i]
+		token, err := e.stopScaleSetInstances(ctx, state.Mode, scaleSet)
+		scaleSet.ResumeToken = token
+		messages = append(messages, common.Outcome(err, fmt.Sprintf("%s of instances %v of scale set '%s'", state.Mode, scaleSet.InstanceIDs, scaleSet.Name)))
+		if err != nil {
+			scaleSet.Error = err.Error()
+			failed++
+		}
+	}
+
+	if failed == len(state.VirtualMachines)+len(state.ScaleSets) {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to take down any resource in zone %s of %s", state.Zone, state.Location), nil)
+	}
+	return &action_kit_api.StartResult{Messages: &messages}, nil
+}
+
+func (e *zoneOutageAction) Stop(ctx context.Context, state *ZoneOutageState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.VirtualMachines)+len(state.ScaleSets))
+		for _, vm := range state.VirtualMachines {
+			mutations = append(mutations, fmt.Sprintf("start virtual machine '%s' in resource group '%s'", vm.Name, vm.ResourceGroupName))
+		}
+		for _, scaleSet := range state.ScaleSets {
+			mutations = append(mutations, fmt.Sprintf("start instances %v of scale set '%s' in resource group '%s'", scaleSet.InstanceIDs, scaleSet.Name, scaleSet.ResourceGroupName))
+		}
+		return &action_kit_api.StopResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	ctx, cancel := common.WithStopTimeout(ctx)
+	defer cancel()
+
+	restarts := make([]common.Restart, 0, len(state.VirtualMachines)+len(state.ScaleSets))
+	for i := range state.VirtualMachines {
+		vm := &state.VirtualMachines[i]
+		if vm.Error != "" {
+			continue
+		}
+		restarts = append(restarts, common.Restart{
+			Description: fmt.Sprintf("start of virtual machine '%s'", vm.Name),
+			Begin: func(ctx context.Context) (common.Operation, error) {
+				return e.startVirtualMachine(ctx, vm)
+			},
+		})
+	}
+	for i := range state.ScaleSets {
+		scaleSet := &state.ScaleSets[i]
+		if scaleSet.Error != "" {
+			continue
+		}
+		restarts = append(restarts, common.Restart{
+			Description: fmt.Sprintf("start of instances %v of scale set '%s'", scaleSet.InstanceIDs, scaleSet.Name),
+			Begin: func(ctx context.Context) (common.Operation, error) {
+				return e.startScaleSetInstances(ctx, scaleSet)
+			},
+		})
+	}
+	messages, failed := common.BringBack(ctx, fmt.Sprintf("resources of zone %s of %s", state.Zone, state.Location), e.resumeStops(ctx, state), restarts)
+
+	if len(failed) > 0 {
+		return &action_kit_api.StopResult{
+			Messages: &messages,
+			Error: &action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Failed to bring %d resource(s) of zone %s of %s back.", len(failed), state.Zone, state.Location),
+				Detail: new(strings.Join(failed, ", ")),
+				Status: new(action_kit_api.Errored),
+			},
+		}, nil
+	}
+	return &action_kit_api.StopResult{Messages: &messages}, nil
+}
+
+// stopVirtualMachine deallocates or powers off the virtual machine and returns the resume token of that operation.
+func (e *zoneOutageAction) stopVirtualMachine(ctx context.Context, mode string, vm *ZoneOutageVirtualMachine) (string, error) {
+	operation, err := e.beginStopVirtualMachine(ctx, mode, vm, "")
+	if err != nil || operation == nil {
+		return "", err
+	}
+	return resumeToken(operation, vm.Name), nil
+}
+
+func (e *zoneOutageAction) beginStopVirtualMachine(ctx context.Context, mode string, vm *ZoneOutageVirtualMachine, resumeToken string) (common.Operation, error) {
+	client, err := e.virtualMachinesProvider(vm.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	if mode == outageModePowerOff {
+		return common.NewOperation(client.BeginPowerOff(ctx, vm.ResourceGroupName, vm.Name, &armcompute.VirtualMachinesClientBeginPowerOffOptions{ResumeToken: resumeToken}))
+	}
+	return common.NewOperation(client.BeginDeallocate(ctx, vm.ResourceGroupName, vm.Name, &armcompute.VirtualMachinesClientBeginDeallocateOptions{ResumeToken: resumeToken}))
+}
+
+// startVirtualMachine starts the virtual machine again.
+func (e *zoneOutageAction) startVirtualMachine(ctx context.Context, vm *ZoneOutageVirtualMachine) (common.Operation, error) {
+	client, err := e.virtualMachinesProvider(vm.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewOperation(client.BeginStart(ctx, vm.ResourceGroupName, vm.Name, nil))
+}
+
+// stopScaleSetInstances deallocates or powers off the instances of the scale set in the zone and returns the resume
+// token of that operation.
+func (e *zoneOutageAction) stopScaleSetInstances(ctx context.Context, mode string, scaleSet *ZoneOutageScaleSet) (string, error) {
+	operation, err := e.beginStopScaleSetInstances(ctx, mode, scaleSet, "")
+	if err != nil || operation == nil {
+		return "", err
+	}
+	return resumeToken(operation, scaleSet.Name), nil
+}
+
+func (e *zoneOutageAction) beginStopScaleSetInstances(ctx context.Context, mode string, scaleSet *ZoneOutageScaleSet, resumeToken string) (common.Operation, error) {
+	client, err := e.scaleSetsProvider(scaleSet.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	instanceIds := &armcompute.VirtualMachineScaleSetVMInstanceIDs{InstanceIDs: to.SliceOfPtrs(scaleSet.InstanceIDs...)}
+	if mode == outageModePowerOff {
+		return common.NewOperation(client.BeginPowerOff(ctx, scaleSet.ResourceGroupName, scaleSet.Name, &armcompute.VirtualMachineScaleSetsClientBeginPowerOffOptions{ResumeToken: resumeToken, VMInstanceIDs: instanceIds}))
+	}
+	return common.NewOperation(client.BeginDeallocate(ctx, scaleSet.ResourceGroupName, scaleSet.Name, &armcompute.VirtualMachineScaleSetsClientBeginDeallocateOptions{ResumeToken: resumeToken, VMInstanceIDs: instanceIds}))
+}
+
+// startScaleSetInstances starts the instances of the scale set in the zone again.
+func (e *zoneOutageAction) startScaleSetInstances(ctx context.Context, scaleSet *ZoneOutageScaleSet) (common.Operation, error) {
+	client, err := e.scaleSetsProvider(scaleSet.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	instanceIds := &armcompute.VirtualMachineScaleSetVMInstanceIDs{InstanceIDs: to.SliceOfPtrs(scaleSet.InstanceIDs...)}
+	return common.NewOperation(client.BeginStart(ctx, scaleSet.ResourceGroupName, scaleSet.Name, &armcompute.VirtualMachineScaleSetsClientBeginStartOptions{VMInstanceIDs: instanceIds}))
+}
+
+// resumeStops resumes the stops of Start that had not finished yet, so that Stop can wait for them.
+func (e *zoneOutageAction) resumeStops(ctx context.Context, state *ZoneOutageState) []common.Operation {
+	operations := make([]common.Operation, 0)
+	for i := range state.VirtualMachines {
+		vm := &state.VirtualMachines[i]
+		if vm.Error != "" || vm.ResumeToken == "" {
+			continue
+		}
+		operation, err := e.beginStopVirtualMachine(ctx, state.Mode, vm, vm.ResumeToken)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to resume the %s of vm '%s', starting it anyway.", state.Mode, vm.Name)
+		} else if operation != nil {
+			operations = append(operations, operation)
+		}
+	}
+	for i := range state.ScaleSets {
+		scaleSet := &state.ScaleSets[i]
+		if scaleSet.Error != "" || scaleSet.ResumeToken == "" {
+			continue
+		}
+		operation, err := e.beginStopScaleSetInstances(ctx, state.Mode, scaleSet, scaleSet.ResumeToken)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to resume the %s of instances of scale set '%s', starting them anyway.", state.Mode, scaleSet.Name)
+		} else if operation != nil {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+func resumeToken(operation common.Operation, name string) string {
+	token, err := operation.ResumeToken()
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to get the resume token of the outage of '%s'.", name)
+	}
+	return token
+}
+
+func (e *zoneOutageAction) RequiredPermissions(state *ZoneOutageState) []common.PermissionRequirement {
+	vmOperation, scaleSetOperation := "Microsoft.Compute/virtualMachines/deallocate/action", "Microsoft.Compute/virtualMachineScaleSets/deallocate/action"
+	if state.Mode == outageModePowerOff {
+		vmOperation, scaleSetOperation = "Microsoft.Compute/virtualMachines/powerOff/action", "Microsoft.Compute/virtualMachineScaleSets/powerOff/action"
+	}
+	requirements := make([]common.PermissionRequirement, 0, len(state.VirtualMachines)+len(state.ScaleSets))
+	for _, vm := range state.VirtualMachines {
+		requirements = append(requirements, common.PermissionRequirement{
+			Scope:      fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s", vm.SubscriptionId, vm.ResourceGroupName, vm.Name),
+			Operations: []string{"Microsoft.Compute/virtualMachines/read", vmOperation, "Microsoft.Compute/virtualMachines/start/action"},
+		})
+	}
+	for _, scaleSet := range state.ScaleSets {
+		requirements = append(requirements, common.PermissionRequirement{
+			Scope:      fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s", scaleSet.SubscriptionId, scaleSet.ResourceGroupName, scaleSet.Name),
+			Operations: []string{"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/read", scaleSetOperation, "Microsoft.Compute/virtualMachineScaleSets/start/action"},
+		})
+	}
+	return requirements
+}
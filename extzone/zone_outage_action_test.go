@@ -0,0 +1,270 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package extzone
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type resourceGraphMock struct {
+	virtualMachines []any
+	scaleSets       []any
+}
+
+func (m *resourceGraphMock) Resources(_ context.Context, query armresourcegraph.QueryRequest, _ *armresourcegraph.ClientResourcesOptions) (armresourcegraph.ClientResourcesResponse, error) {
+	data := m.virtualMachines
+	if strings.Contains(*query.Query, "virtualmachinescalesets") {
+		data = m.scaleSets
+	}
+	return armresourcegraph.ClientResourcesResponse{QueryResponse: armresourcegraph.QueryResponse{Data: data, TotalRecords: new(int64(len(data)))}}, nil
+}
+
+func virtualMachineRow(name string, resourceGroup string, zone string) map[string]any {
+	return map[string]any{
+		"subscriptionId": "42", "resourceGroup": resourceGroup, "location": "westeurope", "name": name, "zones": []any{zone},
+		"properties": map[string]any{"vmId": name + "-id"},
+	}
+}
+
+func scaleSetRow(name string, resourceGroup string, tags map[string]any) map[string]any {
+	return map[string]any{
+		"id": name + "-id", "subscriptionId": "42", "resourceGroup": resourceGroup, "location": "westeurope", "name": name, "tags": tags,
+	}
+}
+
+func instanceView(powerState string) []*armcompute.InstanceViewStatus {
+	return []*armcompute.InstanceViewStatus{{Code: new("ProvisioningState/succeeded")}, {Code: new("PowerState/" + powerState)}}
+}
+
+type virtualMachinesApiMock struct {
+	mock.Mock
+	stopped []string
+}
+
+func (m *virtualMachinesApiMock) InstanceView(_ context.Context, _ string, vmName string, _ *armcompute.VirtualMachinesClientInstanceViewOptions) (armcompute.VirtualMachinesClientInstanceViewResponse, error) {
+	powerState := "running"
+	if slices.Contains(m.stopped, vmName) {
+		powerState = "deallocated"
+	}
+	return armcompute.VirtualMachinesClientInstanceViewResponse{VirtualMachineInstanceView: armcompute.VirtualMachineInstanceView{Statuses: instanceView(powerState)}}, nil
+}
+
+func (m *virtualMachinesApiMock) BeginDeallocate(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachinesClientDeallocateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *virtualMachinesApiMock) BeginPowerOff(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+func (m *virtualMachinesApiMock) BeginStart(ctx context.Context, resourceGroupName string, vmName string, _ *armcompute.VirtualMachinesClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachinesClientStartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmName)
+	return nil, args.Error(1)
+}
+
+type scaleSetsApiMock struct {
+	mock.Mock
+}
+
+func instanceIdsOf(ids *armcompute.VirtualMachineScaleSetVMInstanceIDs) []string {
+	result := make([]string, 0, len(ids.InstanceIDs))
+	for _, id := range ids.InstanceIDs {
+		result = append(result, *id)
+	}
+	return result
+}
+
+func (m *scaleSetsApiMock) BeginDeallocate(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginDeallocateOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientDeallocateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceIdsOf(options.VMInstanceIDs))
+	return nil, args.Error(1)
+}
+
+func (m *scaleSetsApiMock) BeginPowerOff(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginPowerOffOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientPowerOffResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceIdsOf(options.VMInstanceIDs))
+	return nil, args.Error(1)
+}
+
+func (m *scaleSetsApiMock) BeginStart(ctx context.Context, resourceGroupName string, vmScaleSetName string, options *armcompute.VirtualMachineScaleSetsClientBeginStartOptions) (*runtime.Poller[armcompute.VirtualMachineScaleSetsClientStartResponse], error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceIdsOf(options.VMInstanceIDs))
+	return nil, args.Error(1)
+}
+
+type scaleSetVMsApiMock struct {
+	instances map[string][]*armcompute.VirtualMachineScaleSetVM
+	stopped   []string
+}
+
+func (m *scaleSetVMsApiMock) GetInstanceView(_ context.Context, _ string, scaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewOptions) (armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse, error) {
+	powerState := "running"
+	if slices.Contains(m.stopped, scaleSetName+"/"+instanceID) {
+		powerState = "stopped"
+	}
+	return armcompute.VirtualMachineScaleSetVMsClientGetInstanceViewResponse{VirtualMachineScaleSetVMInstanceView: armcompute.VirtualMachineScaleSetVMInstanceView{Statuses: instanceView(powerState)}}, nil
+}
+
+func (m *scaleSetVMsApiMock) NewListPager(_ string, scaleSetName string, _ *armcompute.VirtualMachineScaleSetVMsClientListOptions) *runtime.Pager[armcompute.VirtualMachineScaleSetVMsClientListResponse] {
+	return runtime.NewPager(runtime.PagingHandler[armcompute.VirtualMachineScaleSetVMsClientListResponse]{
+		More: func(armcompute.VirtualMachineScaleSetVMsClientListResponse) bool { return false },
+		Fetcher: func(context.Context, *armcompute.VirtualMachineScaleSetVMsClientListResponse) (armcompute.VirtualMachineScaleSetVMsClientListResponse, error) {
+			return armcompute.VirtualMachineScaleSetVMsClientListResponse{VirtualMachineScaleSetVMListResult: armcompute.VirtualMachineScaleSetVMListResult{Value: m.instances[scaleSetName]}}, nil
+		},
+	})
+}
+
+func instance(scaleSetName string, id string, zone string) *armcompute.VirtualMachineScaleSetVM {
+	return &armcompute.VirtualMachineScaleSetVM{
+		ID:         new(scaleSetName + "/virtualMachines/" + id),
+		Name:       new(scaleSetName + "_" + id),
+		InstanceID: new(id),
+		Zones:      []*string{new(zone)},
+	}
+}
+
+func newTestAction(vms *virtualMachinesApiMock, scaleSets *scaleSetsApiMock, stoppedInstances ...string) *zoneOutageAction {
+	return &zoneOutageAction{
+		resourceGraphProvider: func() (common.ArmResourceGraphApi, error) {
+			return &resourceGraphMock{
+				virtualMachines: []any{
+					virtualMachineRow("vm-1", "rg-42", "1"),
+					virtualMachineRow("vm-2", "rg-42", "1"),
+					virtualMachineRow("vm-3", "rg-42", "2"),
+					virtualMachineRow("vm-4", "rg-other", "1"),
+				},
+				scaleSets: []any{
+					scaleSetRow("vmss-1", "rg-42", map[string]any{}),
+					scaleSetRow("aks-pool-1", "rg-42", map[string]any{"aks-managed-poolName": "pool"}),
+				},
+			}, nil
+		},
+		virtualMachinesProvider: func(string) (zoneVirtualMachinesApi, error) { return vms, nil },
+		scaleSetsProvider:       func(string) (zoneScaleSetsApi, error) { return scaleSets, nil },
+		scaleSetVMsProvider: func(string) (zoneScaleSetVMsApi, error) {
+			return &scaleSetVMsApiMock{
+				instances: map[string][]*armcompute.VirtualMachineScaleSetVM{
+					"vmss-1":     {instance("vmss-1", "0", "1"), instance("vmss-1", "1", "2"), instance("vmss-1", "2", "1")},
+					"aks-pool-1": {instance("aks-pool-1", "0", "1")},
+				},
+				stopped: stoppedInstances,
+			}, nil
+		},
+	}
+}
+
+func outageRequest(config map[string]any) action_kit_api.PrepareActionRequestBody {
+	request := map[string]any{"location": "westeurope", "zone": "1", "resourceGroups": []any{"rg-42"}, "mode": outageModeDeallocate, "maxInstances": 10}
+	for k, v := range config {
+		request[k] = v
+	}
+	return action_kit_api.PrepareActionRequestBody{Config: request}
+}
+
+func TestZoneOutageAction_Prepare(t *testing.T) {
+	tests := []struct {
+		name                    string
+		config                  map[string]any
+		expectedVirtualMachines []string
+		expectedScaleSets       []string
+		expectedError           string
+	}{
+		{name: "skips aks node pools by default", expectedVirtualMachines: []string{"vm-1", "vm-2"}, expectedScaleSets: []string{"vmss-1"}},
+		{name: "includes aks node pools", config: map[string]any{"includeAksNodePools": true}, expectedVirtualMachines: []string{"vm-1", "vm-2"}, expectedScaleSets: []string{"vmss-1", "aks-pool-1"}},
+		{name: "takes down all resource groups without a filter", config: map[string]any{"resourceGroups": []any{" "}}, expectedVirtualMachines: []string{"vm-1", "vm-2", "vm-4"}, expectedScaleSets: []string{"vmss-1"}},
+		{name: "takes down several resource groups", config: map[string]any{"resourceGroups": []any{"rg-42", "RG-OTHER"}}, expectedVirtualMachines: []string{"vm-1", "vm-2", "vm-4"}, expectedScaleSets: []string{"vmss-1"}},
+		{name: "respects the safety cap", config: map[string]any{"maxInstances": 3}, expectedError: "Zone 1 of westeurope has 4 instances, more than the maximum of 3."},
+		{name: "rejects invalid zones", config: map[string]any{"zone": "1' or 1==1"}, expectedError: "Invalid zone"},
+		{name: "rejects invalid resource groups", config: map[string]any{"resourceGroups": []any{"rg' or 1==1"}}, expectedError: "Invalid resource group"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := newTestAction(new(virtualMachinesApiMock), new(scaleSetsApiMock))
+			state := action.NewEmptyState()
+
+			_, err := action.Prepare(context.Background(), &state, outageRequest(tt.config))
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			virtualMachines := make([]string, 0, len(state.VirtualMachines))
+			for _, vm := range state.VirtualMachines {
+				virtualMachines = append(virtualMachines, vm.Name)
+			}
+			assert.Equal(t, tt.expectedVirtualMachines, virtualMachines)
+			names := make([]string, 0, len(state.ScaleSets))
+			for _, scaleSet := range state.ScaleSets {
+				names = append(names, scaleSet.Name)
+			}
+			assert.Equal(t, tt.expectedScaleSets, names)
+			assert.Equal(t, []string{"0", "2"}, state.ScaleSets[0].InstanceIDs)
+		})
+	}
+}
+
+func TestZoneOutageAction_StartAndStopReportPerResourceOutcomes(t *testing.T) {
+	// Given
+	vms := new(virtualMachinesApiMock)
+	vms.On("BeginDeallocate", mock.Anything, "rg-42", "vm-1").Return(nil, nil)
+	vms.On("BeginDeallocate", mock.Anything, "rg-42", "vm-2").Return(nil, errors.New("conflict"))
+	vms.On("BeginStart", mock.Anything, "rg-42", "vm-1").Return(nil, nil)
+	scaleSets := new(scaleSetsApiMock)
+	scaleSets.On("BeginDeallocate", mock.Anything, "rg-42", "vmss-1", []string{"0", "2"}).Return(nil, nil)
+	scaleSets.On("BeginStart", mock.Anything, "rg-42", "vmss-1", []string{"0", "2"}).Return(nil, nil)
+	action := newTestAction(vms, scaleSets)
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.Background(), &state, outageRequest(map[string]any{}))
+	require.NoError(t, err)
+
+	// When
+	startResult, err := action.Start(context.Background(), &state)
+	require.NoError(t, err)
+	stopResult, err := action.Stop(context.Background(), &state)
+	require.NoError(t, err)
+
+	// Then
+	require.Len(t, *startResult.Messages, 3)
+	assert.Equal(t, "The deallocate of virtual machine 'vm-2' failed: conflict", (*startResult.Messages)[1].Message)
+	assert.Equal(t, "conflict", state.VirtualMachines[1].Error)
+	require.Len(t, *stopResult.Messages, 2)
+	assert.Nil(t, stopResult.Error)
+	vms.AssertExpectations(t)
+	vms.AssertNotCalled(t, "BeginStart", mock.Anything, "rg-42", "vm-2")
+	scaleSets.AssertExpectations(t)
+}
+
+func TestZoneOutageAction_PrepareSkipsResourcesWhichAreNotRunning(t *testing.T) {
+	// Given
+	vms := &virtualMachinesApiMock{stopped: []string{"vm-2"}}
+	action := newTestAction(vms, new(scaleSetsApiMock), "vmss-1/2")
+	state := action.NewEmptyState()
+
+	// When
+	result, err := action.Prepare(context.Background(), &state, outageRequest(map[string]any{}))
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, state.VirtualMachines, 1)
+	assert.Equal(t, "vm-1", state.VirtualMachines[0].Name)
+	require.Len(t, state.ScaleSets, 1)
+	assert.Equal(t, []string{"0"}, state.ScaleSets[0].InstanceIDs)
+	require.Len(t, *result.Messages, 2)
+	assert.Equal(t, action_kit_api.Warn, *(*result.Messages)[1].Level)
+	assert.Equal(t, "Resources in zone 1 of westeurope which are not running are left alone: virtual machine 'vm-2' (deallocated), instance 2 of scale set 'vmss-1' (stopped).", (*result.Messages)[1].Message)
+}
@@ -5,12 +5,18 @@
 package main
 
 import (
+	"context"
+	"os"
+
 	_ "github.com/KimMachineGun/automemlimit" // By default, it sets `GOMEMLIMIT` to 90% of cgroup's memory limit.
+	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-azure/config"
+	"github.com/steadybit/extension-azure/extmetrics"
+	"github.com/steadybit/extension-azure/exttracing"
 	"github.com/steadybit/extension-azure/register"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/exthealth"
@@ -43,6 +49,19 @@ func main() {
 	config.ParseConfiguration()
 	config.ValidateConfiguration()
 
+	// Spans for discoveries, actions and Azure API calls are exported via OTLP when
+	// STEADYBIT_EXTENSION_TRACING_OTLP_ENDPOINT is set. Pending spans are flushed on shutdown.
+	shutdownTracing := exttracing.Init(context.Background())
+	extsignals.AddSignalHandler(extsignals.SignalHandler{
+		Handler: func(signal os.Signal) {
+			if err := shutdownTracing(context.Background()); err != nil {
+				log.Warn().Err(err).Msgf("Failed to flush pending spans.")
+			}
+		},
+		Order: extsignals.OrderStopCustom,
+		Name:  "ShutdownTracing",
+	})
+
 	// This is a section you will most likely want to change: The registration of HTTP handlers
 	// for your extension. You might want to change these because the names do not fit, or because
 	// you do not have a need for all of them.
@@ -52,6 +71,9 @@ func main() {
 	// by the Steadybit agent to obtain the extension's capabilities.
 	exthttp.RegisterRevisionedHandler("/", getExtensionList)
 
+	// This call exposes Prometheus metrics about discoveries, Azure API calls and actions under /metrics.
+	extmetrics.RegisterHandler()
+
 	//This will install a signal handlder, that will stop active actions when receiving a SIGURS1, SIGTERM or SIGINT
 	extsignals.ActivateSignalHandlers()
 
@@ -35,18 +35,26 @@ type blockAction struct {
 
 var _ action_kit_sdk.Action[BlockActionState] = (*blockAction)(nil)
 var _ action_kit_sdk.ActionWithStop[BlockActionState] = (*blockAction)(nil)
+var _ common.ActionWithRequiredPermissions[BlockActionState] = (*blockAction)(nil)
 
 type BlockActionState struct {
+	common.ExecutionContextState
+
 	ResourceId               string            `json:"resourceId"`
 	Config                   *BlockHostsConfig `json:"config"`
 	ResourceGroupName        string            `json:"resourceGroupName"`
 	NetworkSecurityGroupName string            `json:"networkSecurityGroupName"`
 	NetworkSecurityRuleNames []string          `json:"networkSecurityRuleNames"`
+	DryRun                   bool              `json:"dryRun"`
 }
 
 type BlockHostsConfig struct {
 	BlockedIPs     *[]string                        `json:"denylist,omitempty"`
 	BlockDirection armnetwork.SecurityRuleDirection `json:"direction"`
+	// Directions overrides BlockDirection to block several directions at once.
+	Directions []armnetwork.SecurityRuleDirection `json:"directions,omitempty"`
+	// AllowedPrefixes are CIDRs or service tags that stay reachable, allowed with a higher priority than the deny rules.
+	AllowedPrefixes []string `json:"allowlist,omitempty"`
 }
 
 func NewBlockAction() action_kit_sdk.Action[BlockActionState] {
@@ -107,6 +115,7 @@ func getInjectBlockDescription() action_kit_api.ActionDescription {
 					},
 				}),
 			},
+			common.DryRunParameter(),
 		},
 		Stop: new(action_kit_api.MutatingEndpointReference{}),
 	}
@@ -196,10 +205,22 @@ func (b *blockAction) Prepare(ctx context.Context, state *BlockActionState, requ
 	state.ResourceId = resource
 	state.ResourceGroupName = resourceGroup
 	state.NetworkSecurityGroupName = nsgName
+	state.DryRun = common.IsDryRun(request)
 
 	return nil, nil
 }
 
+func (b *blockAction) RequiredPermissions(state *BlockActionState) []common.PermissionRequirement {
+	return []common.PermissionRequirement{{
+		Scope: state.ResourceId,
+		Operations: []string{
+			"Microsoft.Network/networkSecurityGroups/read",
+			"Microsoft.Network/networkSecurityGroups/securityRules/write",
+			"Microsoft.Network/networkSecurityGroups/securityRules/delete",
+		},
+	}}
+}
+
 func (b *blockAction) Start(ctx context.Context, state *BlockActionState) (*action_kit_api.StartResult, error) {
 	cred, err := common.ConnectionAzure()
 
@@ -213,7 +234,7 @@ func (b *blockAction) Start(ctx context.Context, state *BlockActionState) (*acti
 		return nil, fmt.Errorf("'AZURE_SUBSCRIPTION_ID' environment variable is missing")
 	}
 
-	client, err := armnetwork.NewSecurityGroupsClient(subscriptionId, cred, nil)
+	client, err := armnetwork.NewSecurityGroupsClient(subscriptionId, cred, common.ArmClientOptions())
 
 	if err != nil {
 		return nil, fmt.Errorf("unable to create security groups client: %s", err)
@@ -225,37 +246,26 @@ func (b *blockAction) Start(ctx context.Context, state *BlockActionState) (*acti
 		return nil, fmt.Errorf("unable to retrieve security group '%s' in the resource group '%s' with error %s", state.NetworkSecurityGroupName, state.ResourceGroupName, err)
 	}
 
-	securityRulesClient, err := armnetwork.NewSecurityRulesClient(subscriptionId, cred, nil)
+	securityRulesClient, err := armnetwork.NewSecurityRulesClient(subscriptionId, cred, common.ArmClientOptions())
 
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve security rules client: %s", err)
 	}
 
-	existingRules := securityGroup.Properties.SecurityRules
-	usedPriorities := make(map[int32]bool)
-	for _, rule := range existingRules {
-		if rule.Properties != nil && rule.Properties.Priority != nil {
-			usedPriorities[*rule.Properties.Priority] = true
-		}
-	}
-
-	for i, ip := range *state.Config.BlockedIPs {
-		var sourcePrefix, destinationPrefix *string
-		if state.Config.BlockDirection == armnetwork.SecurityRuleDirectionInbound {
-			sourcePrefix = new(ip)
-			destinationPrefix = new("*")
-		} else {
-			sourcePrefix = new("*")
-			destinationPrefix = new(ip)
-		}
+	rules := planBlockRules(securityGroup.Properties.SecurityRules, state.Config)
 
-		priority := 100 + int32(i)
-		for usedPriorities[priority] {
-			priority++
+	if state.DryRun {
+		mutations := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			state.NetworkSecurityRuleNames = append(state.NetworkSecurityRuleNames, rule.Name)
+			mutations = append(mutations, fmt.Sprintf("create security rule '%s' with priority %d %s %s traffic from '%s' to '%s' in network security group '%s'",
+				rule.Name, rule.Priority, accessVerb(rule.Access), strings.ToLower(string(rule.Direction)), rule.SourcePrefix, rule.DestinationPrefix, state.NetworkSecurityGroupName))
 		}
-		usedPriorities[priority] = true
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
 
-		ruleName := fmt.Sprintf("SteadybitBlockRule-%d", i)
+	for _, rule := range rules {
+		ruleName := rule.Name
 		sg, err := securityRulesClient.BeginCreateOrUpdate(ctx,
 			state.ResourceGroupName,
 			*securityGroup.Name,
@@ -265,11 +275,11 @@ func (b *blockAction) Start(ctx context.Context, state *BlockActionState) (*acti
 					Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolAsterisk),
 					SourcePortRange:          new("*"),
 					DestinationPortRange:     new("*"),
-					SourceAddressPrefix:      sourcePrefix,
-					DestinationAddressPrefix: destinationPrefix,
-					Access:                   to.Ptr(armnetwork.SecurityRuleAccessDeny),
-					Direction:                new(state.Config.BlockDirection),
-					Priority:                 new(priority),
+					SourceAddressPrefix:      new(rule.SourcePrefix),
+					DestinationAddressPrefix: new(rule.DestinationPrefix),
+					Access:                   new(rule.Access),
+					Direction:                new(rule.Direction),
+					Priority:                 new(rule.Priority),
 					Description:              new("Blocked by steadybit"),
 				},
 			}, nil)
@@ -309,6 +319,14 @@ func (b *blockAction) Start(ctx context.Context, state *BlockActionState) (*acti
 }
 
 func (b *blockAction) Stop(ctx context.Context, state *BlockActionState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.NetworkSecurityRuleNames))
+		for _, ruleName := range state.NetworkSecurityRuleNames {
+			mutations = append(mutations, fmt.Sprintf("delete security rule '%s' from network security group '%s'", ruleName, state.NetworkSecurityGroupName))
+		}
+		return &action_kit_api.StopResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
 	cred, err := common.ConnectionAzure()
 
 	if err != nil {
@@ -321,7 +339,7 @@ func (b *blockAction) Stop(ctx context.Context, state *BlockActionState) (*actio
 		return nil, fmt.Errorf("'AZURE_SUBSCRIPTION_ID' environment variable is missing")
 	}
 
-	client, err := armnetwork.NewSecurityRulesClient(subscriptionId, cred, nil)
+	client, err := armnetwork.NewSecurityRulesClient(subscriptionId, cred, common.ArmClientOptions())
 
 	if err != nil {
 		return nil, fmt.Errorf("unable to create security groups client: %s", err)
@@ -336,6 +354,77 @@ func (b *blockAction) Stop(ctx context.Context, state *BlockActionState) (*actio
 	return nil, nil
 }
 
+// plannedBlockRule is a rule the block attack creates: a deny rule for one of the blocked IPs or
+// an allow rule for one of the allowed prefixes.
+type plannedBlockRule struct {
+	Name              string
+	Priority          int32
+	SourcePrefix      string
+	DestinationPrefix string
+	Direction         armnetwork.SecurityRuleDirection
+	Access            armnetwork.SecurityRuleAccess
+}
+
+// planBlockRules names one deny rule per blocked IP and direction, preceded by the allow rules of
+// that direction, and assigns each the lowest free priority from 100 upwards, skipping the
+// priorities of the rules that already exist in the group.
+func planBlockRules(existingRules []*armnetwork.SecurityRule, config *BlockHostsConfig) []plannedBlockRule {
+	usedPriorities := make(map[int32]bool)
+	for _, rule := range existingRules {
+		if rule.Properties != nil && rule.Properties.Priority != nil {
+			usedPriorities[*rule.Properties.Priority] = true
+		}
+	}
+
+	directions := config.Directions
+	if len(directions) == 0 {
+		directions = []armnetwork.SecurityRuleDirection{config.BlockDirection}
+	}
+
+	rules := make([]plannedBlockRule, 0)
+	allowed, blocked := 0, 0
+	add := func(name string, direction armnetwork.SecurityRuleDirection, access armnetwork.SecurityRuleAccess, prefix string) {
+		rule := plannedBlockRule{
+			Name:              name,
+			SourcePrefix:      "*",
+			DestinationPrefix: prefix,
+			Direction:         direction,
+			Access:            access,
+		}
+		if direction == armnetwork.SecurityRuleDirectionInbound {
+			rule.SourcePrefix = prefix
+			rule.DestinationPrefix = "*"
+		}
+
+		priority := 100 + int32(len(rules))
+		for usedPriorities[priority] {
+			priority++
+		}
+		usedPriorities[priority] = true
+		rule.Priority = priority
+
+		rules = append(rules, rule)
+	}
+	for _, direction := range directions {
+		for _, prefix := range config.AllowedPrefixes {
+			add(fmt.Sprintf("SteadybitAllowRule-%d", allowed), direction, armnetwork.SecurityRuleAccessAllow, prefix)
+			allowed++
+		}
+		for _, ip := range *config.BlockedIPs {
+			add(fmt.Sprintf("SteadybitBlockRule-%d", blocked), direction, armnetwork.SecurityRuleAccessDeny, ip)
+			blocked++
+		}
+	}
+	return rules
+}
+
+func accessVerb(access armnetwork.SecurityRuleAccess) string {
+	if access == armnetwork.SecurityRuleAccessAllow {
+		return "allowing"
+	}
+	return "denying"
+}
+
 func cleanupRules(ctx context.Context, state *BlockActionState, client *armnetwork.SecurityRulesClient) error {
 	for _, ruleName := range state.NetworkSecurityRuleNames {
 		poller, err := client.BeginDelete(ctx, state.ResourceGroupName, state.NetworkSecurityGroupName, ruleName, nil)
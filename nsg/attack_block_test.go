@@ -192,3 +192,34 @@ func TestBlockAction_IPAddressHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanBlockRules_SkipsUsedPriorities(t *testing.T) {
+	existing := []*armnetwork.SecurityRule{
+		{Properties: &armnetwork.SecurityRulePropertiesFormat{Priority: new(int32(100))}},
+		{Properties: &armnetwork.SecurityRulePropertiesFormat{Priority: new(int32(102))}},
+		{Properties: nil},
+	}
+
+	rules := planBlockRules(existing, &BlockHostsConfig{
+		BlockedIPs:     new([]string{"10.0.0.1", "10.0.0.2"}),
+		BlockDirection: armnetwork.SecurityRuleDirectionInbound,
+	})
+
+	assert.Equal(t, []plannedBlockRule{
+		{Name: "SteadybitBlockRule-0", Priority: 101, SourcePrefix: "10.0.0.1", DestinationPrefix: "*", Direction: armnetwork.SecurityRuleDirectionInbound, Access: armnetwork.SecurityRuleAccessDeny},
+		{Name: "SteadybitBlockRule-1", Priority: 103, SourcePrefix: "10.0.0.2", DestinationPrefix: "*", Direction: armnetwork.SecurityRuleDirectionInbound, Access: armnetwork.SecurityRuleAccessDeny},
+	}, rules)
+}
+
+func TestBlockAction_Stop_DryRun(t *testing.T) {
+	action := NewBlockAction().(*blockAction)
+
+	result, err := action.Stop(context.Background(), &BlockActionState{
+		NetworkSecurityGroupName: "nsg",
+		NetworkSecurityRuleNames: []string{"SteadybitBlockRule-0"},
+		DryRun:                   true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[dry run] Would delete security rule 'SteadybitBlockRule-0' from network security group 'nsg'", (*result.Messages)[0].Message)
+}
@@ -0,0 +1,550 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package nsg
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/steadybit/extension-azure/extscalesetinstance"
+	"github.com/steadybit/extension-azure/extvm"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	// maxNsgNameLength is the maximum length of the name of a network security group.
+	maxNsgNameLength = 80
+
+	IsolateBoth       = "both"
+	denyAllPriority   = int32(4096)
+	allowRulePriority = int32(100)
+)
+
+// isolationTarget is what the isolation needs to know about the attacked machine.
+type isolationTarget struct {
+	// Name identifies the machine in the name of the isolation network security group.
+	Name string
+	// Subject describes the machine in messages, e.g. "virtual machine 'vm-1'".
+	Subject string
+	NicIds  []string
+}
+
+type isolateNicAction struct {
+	description           action_kit_api.ActionDescription
+	targetProvider        func(ctx context.Context, request action_kit_api.PrepareActionRequestBody) (*isolationTarget, error)
+	networkInterfacesApi  func(subscriptionId string) (networkInterfacesApi, error)
+	networkSecurityGroups func(subscriptionId string) (securityGroupsApi, error)
+}
+
+var _ action_kit_sdk.Action[NicIsolationState] = (*isolateNicAction)(nil)
+var _ action_kit_sdk.ActionWithStop[NicIsolationState] = (*isolateNicAction)(nil)
+var _ common.ActionWithRequiredPermissions[NicIsolationState] = (*isolateNicAction)(nil)
+
+type NicIsolationState struct {
+	common.ExecutionContextState
+
+	SubscriptionId    string   `json:"subscriptionId"`
+	ResourceGroupName string   `json:"resourceGroupName"`
+	Subject           string   `json:"subject"`
+	NicIds            []string `json:"nicIds"`
+	Direction         string   `json:"direction"`
+	AllowedCidrs      []string `json:"allowedCidrs"`
+	// IsolationNsgName is the deny-all network security group created in the resource group of the first NIC.
+	IsolationNsgName string `json:"isolationNsgName"`
+	// OriginalNsgs maps each NIC that was switched to the isolation group to its previous group ("" for none).
+	OriginalNsgs map[string]string `json:"originalNsgs"`
+	DryRun       bool              `json:"dryRun"`
+}
+
+type networkInterfacesApi interface {
+	Get(ctx context.Context, resourceGroupName string, networkInterfaceName string, options *armnetwork.InterfacesClientGetOptions) (armnetwork.InterfacesClientGetResponse, error)
+	BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, networkInterfaceName string, parameters armnetwork.Interface, options *armnetwork.InterfacesClientBeginCreateOrUpdateOptions) (*runtime.Poller[armnetwork.InterfacesClientCreateOrUpdateResponse], error)
+}
+
+type securityGroupsApi interface {
+	BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, networkSecurityGroupName string, parameters armnetwork.SecurityGroup, options *armnetwork.SecurityGroupsClientBeginCreateOrUpdateOptions) (*runtime.Poller[armnetwork.SecurityGroupsClientCreateOrUpdateResponse], error)
+	BeginDelete(ctx context.Context, resourceGroupName string, networkSecurityGroupName string, options *armnetwork.SecurityGroupsClientBeginDeleteOptions) (*runtime.Poller[armnetwork.SecurityGroupsClientDeleteResponse], error)
+}
+
+type scaleSetVMsApi interface {
+	Get(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, options *armcompute.VirtualMachineScaleSetVMsClientGetOptions) (armcompute.VirtualMachineScaleSetVMsClientGetResponse, error)
+}
+
+func newIsolateNicAction(description action_kit_api.ActionDescription, targetProvider func(ctx context.Context, request action_kit_api.PrepareActionRequestBody) (*isolationTarget, error)) *isolateNicAction {
+	return &isolateNicAction{
+		description:    description,
+		targetProvider: targetProvider,
+		networkInterfacesApi: func(subscriptionId string) (networkInterfacesApi, error) {
+			cred, err := common.ConnectionAzure()
+			if err != nil {
+				return nil, err
+			}
+			return armnetwork.NewInterfacesClient(subscriptionId, cred, common.ArmClientOptions())
+		},
+		networkSecurityGroups: func(subscriptionId string) (securityGroupsApi, error) {
+			cred, err := common.ConnectionAzure()
+			if err != nil {
+				return nil, err
+			}
+			return armnetwork.NewSecurityGroupsClient(subscriptionId, cred, common.ArmClientOptions())
+		},
+	}
+}
+
+// NewVirtualMachineIsolationAction isolates virtual machines via the NICs from the 'azure-vm.network.id' attribute.
+func NewVirtualMachineIsolationAction() action_kit_sdk.ActionWithStop[NicIsolationState] {
+	return newIsolateNicAction(getIsolationDescription(
+		fmt.Sprintf("%s.isolate-nic", extvm.TargetIDVM),
+		"virtual machines",
+		action_kit_api.TargetSelection{
+			TargetType: extvm.TargetIDVM,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "vm-name",
+					Description: new("Find azure virtual machine by name"),
+					Query:       "azure-vm.vm.name=\"\"",
+				},
+			}),
+		},
+	), virtualMachineIsolationTarget)
+}
+
+// NewScaleSetInstanceIsolationAction isolates instances of scale sets in flexible orchestration mode. The NICs of
+// instances in uniform orchestration mode belong to the scale set model and cannot be changed individually.
+func NewScaleSetInstanceIsolationAction() action_kit_sdk.ActionWithStop[NicIsolationState] {
+	return newIsolateNicAction(getIsolationDescription(
+		fmt.Sprintf("%s.isolate-nic", extscalesetinstance.TargetIDScaleSetInstance),
+		"scale set instances",
+		action_kit_api.TargetSelection{
+			TargetType: extscalesetinstance.TargetIDScaleSetInstance,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "instance name",
+					Description: new("Find azure scale set instance by name"),
+					Query:       "azure-scale-set-instance.name=\"\"",
+				},
+			}),
+		},
+	), scaleSetInstanceIsolationTarget(func(subscriptionId string) (scaleSetVMsApi, error) {
+		return common.GetVirtualMachineScaleSetVMsClient(subscriptionId)
+	}))
+}
+
+func getIsolationDescription(id string, subjects string, targetSelection action_kit_api.TargetSelection) action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:              id,
+		Version:         extbuild.GetSemverVersionStringOrUnknown(),
+		Label:           "Isolate Network Interfaces",
+		Description:     fmt.Sprintf("Attach a temporary deny-all network security group to the network interfaces of %s. The original network security groups are restored on stop.", subjects),
+		Icon:            new(string(targetIcon)),
+		TargetSelection: &targetSelection,
+		Technology:      new("Azure"),
+		Category:        new("Network Security Groups"),
+		Kind:            action_kit_api.Attack,
+		TimeControl:     action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Label:        "Duration",
+				Name:         "duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Description:  new("The duration of the attack."),
+				Required:     new(true),
+				DefaultValue: new("60s"),
+				Order:        new(0),
+			},
+			{
+				Name:         "direction",
+				Label:        "Direction",
+				Description:  new("Direction in which to deny traffic"),
+				Type:         action_kit_api.ActionParameterTypeString,
+				Required:     new(true),
+				Order:        new(1),
+				DefaultValue: new(IsolateBoth),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Deny inbound traffic",
+						Value: string(BlockInbound),
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Deny outbound traffic",
+						Value: string(BlockOutbound),
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Deny inbound and outbound traffic",
+						Value: IsolateBoth,
+					},
+				}),
+			},
+			{
+				Name:        "allowedCidrs",
+				Label:       "Allowed CIDRs",
+				Description: new("Address ranges that stay reachable, e.g. the bastion subnet."),
+				Type:        action_kit_api.ActionParameterTypeStringArray,
+				Required:    new(false),
+				Order:       new(2),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func virtualMachineIsolationTarget(_ context.Context, request action_kit_api.PrepareActionRequestBody) (*isolationTarget, error) {
+	vmName := request.Target.Attributes["azure-vm.vm.name"]
+	if len(vmName) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-vm.vm.name' attribute.", nil)
+	}
+	nicIds := nonEmpty(request.Target.Attributes["azure-vm.network.id"])
+	if len(nicIds) == 0 {
+		return nil, extension_kit.ToError("Target is missing the 'azure-vm.network.id' attribute.", nil)
+	}
+	return &isolationTarget{Name: vmName[0], Subject: fmt.Sprintf("virtual machine '%s'", vmName[0]), NicIds: nicIds}, nil
+}
+
+func scaleSetInstanceIsolationTarget(clientProvider func(subscriptionId string) (scaleSetVMsApi, error)) func(ctx context.Context, request action_kit_api.PrepareActionRequestBody) (*isolationTarget, error) {
+	return func(ctx context.Context, request action_kit_api.PrepareActionRequestBody) (*isolationTarget, error) {
+		attributes := request.Target.Attributes
+		if len(attributes["azure-scale-set.name"]) == 0 || len(attributes["azure-scale-set-instance.id"]) == 0 ||
+			len(attributes["azure.subscription.id"]) == 0 || len(attributes["azure.resource-group.name"]) == 0 {
+			return nil, extension_kit.ToError("Target is missing one of: azure-scale-set.name, azure-scale-set-instance.id, azure.subscription.id, azure.resource-group.name", nil)
+		}
+		scaleSetName := attributes["azure-scale-set.name"][0]
+		instanceId := attributes["azure-scale-set-instance.id"][0]
+		subject := fmt.Sprintf("instance '%s' of scale set '%s'", instanceId, scaleSetName)
+
+		client, err := clientProvider(attributes["azure.subscription.id"][0])
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize azure client for subscription %s", attributes["azure.subscription.id"][0]), err)
+		}
+		instance, err := client.Get(ctx, attributes["azure.resource-group.name"][0], scaleSetName, instanceId, nil)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to get %s", subject), err)
+		}
+		var nicIds []string
+		if instance.Properties != nil && instance.Properties.NetworkProfile != nil {
+			for _, nic := range instance.Properties.NetworkProfile.NetworkInterfaces {
+				if nic != nil && nic.ID != nil {
+					nicIds = append(nicIds, *nic.ID)
+				}
+			}
+		}
+		if len(nicIds) == 0 {
+			return nil, extension_kit.ToError(fmt.Sprintf("The %s has no network interfaces.", subject), nil)
+		}
+		for _, nicId := range nicIds {
+			if strings.Contains(strings.ToLower(nicId), "/virtualmachinescalesets/") {
+				return nil, extension_kit.ToError(fmt.Sprintf("The network interfaces of %s belong to a scale set in uniform orchestration mode and cannot be isolated individually.", subject), nil)
+			}
+		}
+		return &isolationTarget{Name: fmt.Sprintf("%s-%s", scaleSetName, instanceId), Subject: subject, NicIds: nicIds}, nil
+	}
+}
+
+func (a *isolateNicAction) Describe() action_kit_api.ActionDescription {
+	return a.description
+}
+
+func (a *isolateNicAction) NewEmptyState() NicIsolationState {
+	return NicIsolationState{}
+}
+
+func (a *isolateNicAction) Prepare(ctx context.Context, state *NicIsolationState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	target, err := a.targetProvider(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	firstNic, err := arm.ParseResourceID(target.NicIds[0])
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Invalid network interface ID '%s'", target.NicIds[0]), err)
+	}
+
+	direction := extutil.ToString(request.Config["direction"])
+	if direction != string(BlockInbound) && direction != string(BlockOutbound) && direction != IsolateBoth {
+		return nil, extension_kit.ToError(fmt.Sprintf("Invalid direction '%s', please select one of the following: %s, %s, %s", direction, BlockInbound, BlockOutbound, IsolateBoth), nil)
+	}
+
+	allowedCidrs := make([]string, 0)
+	for _, cidr := range nonEmpty(extutil.ToStringArray(request.Config["allowedCidrs"])) {
+		if _, _, err := net.ParseCIDR(cidr); err != nil && net.ParseIP(cidr) == nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("'%s' is neither a CIDR nor an IP address", cidr), nil)
+		}
+		allowedCidrs = append(allowedCidrs, cidr)
+	}
+
+	state.SubscriptionId = firstNic.SubscriptionID
+	state.ResourceGroupName = firstNic.ResourceGroupName
+	state.Subject = target.Subject
+	state.NicIds = target.NicIds
+	state.Direction = direction
+	state.AllowedCidrs = allowedCidrs
+	var executionId *int
+	if request.ExecutionContext != nil {
+		executionId = request.ExecutionContext.ExecutionId
+	}
+	state.IsolationNsgName = isolationNsgName(target.Name, executionId)
+	state.DryRun = common.IsDryRun(request)
+	return nil, nil
+}
+
+// isolationNsgName names the isolation NSG after the VM and the execution. Azure allows at most 80 characters, so a
+// longer VM name is cut and suffixed with a hash of the full name, which keeps the NSGs of similar VMs apart.
+func isolationNsgName(vmName string, executionId *int) string {
+	const prefix = "steadybit-isolation-"
+	suffix := ""
+	if executionId != nil {
+		suffix = fmt.Sprintf("-%d", *executionId)
+	}
+	if len(prefix)+len(vmName)+len(suffix) > maxNsgNameLength {
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(vmName)))[:8]
+		vmName = vmName[:maxNsgNameLength-len(prefix)-len(suffix)-len(hash)-1] + "-" + hash
+	}
+	return prefix + vmName + suffix
+}
+
+func (a *isolateNicAction) RequiredPermissions(state *NicIsolationState) []common.PermissionRequirement {
+	requirements := []common.PermissionRequirement{{
+		Scope: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", state.SubscriptionId, state.ResourceGroupName),
+		Operations: []string{
+			"Microsoft.Network/networkSecurityGroups/write",
+			"Microsoft.Network/networkSecurityGroups/delete",
+			"Microsoft.Network/networkSecurityGroups/join/action",
+		},
+	}}
+	for _, nicId := range state.NicIds {
+		requirements = append(requirements, common.PermissionRequirement{
+			Scope:      nicId,
+			Operations: []string{"Microsoft.Network/networkInterfaces/read", "Microsoft.Network/networkInterfaces/write"},
+		})
+	}
+	return requirements
+}
+
+func (a *isolateNicAction) Start(ctx context.Context, state *NicIsolationState) (*action_kit_api.StartResult, error) {
+	if state.DryRun {
+		mutations := []string{fmt.Sprintf("create network security group '%s' in resource group '%s' denying %s traffic except for %v",
+			state.IsolationNsgName, state.ResourceGroupName, state.Direction, state.AllowedCidrs)}
+		for _, nicId := range state.NicIds {
+			mutations = append(mutations, fmt.Sprintf("attach network security group '%s' to network interface %s", state.IsolationNsgName, nicId))
+		}
+		return &action_kit_api.StartResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	nics, err := a.networkInterfacesApi(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize network interfaces client for subscription %s", state.SubscriptionId), err)
+	}
+	nsgs, err := a.networkSecurityGroups(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize network security groups client for subscription %s", state.SubscriptionId), err)
+	}
+
+	firstNic, err := getNic(ctx, nics, state.NicIds[0])
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to get network interface %s", state.NicIds[0]), err)
+	}
+	operation, err := common.NewOperation(nsgs.BeginCreateOrUpdate(ctx, state.ResourceGroupName, state.IsolationNsgName, armnetwork.SecurityGroup{
+		Location: firstNic.Location,
+		Tags:     map[string]*string{"created-by": new("steadybit")},
+		Properties: &armnetwork.SecurityGroupPropertiesFormat{
+			SecurityRules: isolationRules(state.Direction, state.AllowedCidrs),
+		},
+	}, nil))
+	if err == nil {
+		err = await(ctx, operation)
+	}
+	if err != nil {
+		rollbackErr := a.restore(ctx, state, nics, nsgs)
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to create network security group '%s'", state.IsolationNsgName), errors.Join(err, rollbackErr))
+	}
+
+	isolationNsgId := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/networkSecurityGroups/%s", state.SubscriptionId, state.ResourceGroupName, state.IsolationNsgName)
+	state.OriginalNsgs = make(map[string]string, len(state.NicIds))
+	for _, nicId := range state.NicIds {
+		if err := switchNsg(ctx, nics, nicId, new(isolationNsgId), func(original string) { state.OriginalNsgs[nicId] = original }); err != nil {
+			rollbackErr := a.restore(ctx, state, nics, nsgs)
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to attach network security group '%s' to network interface %s", state.IsolationNsgName, nicId), errors.Join(err, rollbackErr))
+		}
+	}
+
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Isolated %d network interface(s) of %s with network security group '%s'.", len(state.NicIds), state.Subject, state.IsolationNsgName),
+		}}),
+	}, nil
+}
+
+func (a *isolateNicAction) Stop(ctx context.Context, state *NicIsolationState) (*action_kit_api.StopResult, error) {
+	if state.DryRun {
+		mutations := make([]string, 0, len(state.NicIds)+1)
+		for _, nicId := range state.NicIds {
+			mutations = append(mutations, fmt.Sprintf("restore the original network security group of network interface %s", nicId))
+		}
+		mutations = append(mutations, fmt.Sprintf("delete network security group '%s'", state.IsolationNsgName))
+		return &action_kit_api.StopResult{Messages: common.DryRunMessages(mutations...)}, nil
+	}
+
+	nics, err := a.networkInterfacesApi(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize network interfaces client for subscription %s", state.SubscriptionId), err)
+	}
+	nsgs, err := a.networkSecurityGroups(state.SubscriptionId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to initialize network security groups client for subscription %s", state.SubscriptionId), err)
+	}
+	if err := a.restore(ctx, state, nics, nsgs); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to restore the network security groups of %s", state.Subject), err)
+	}
+
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Restored the network security groups of %s and deleted '%s'.", state.Subject, state.IsolationNsgName),
+		}}),
+	}, nil
+}
+
+// restore re-attaches the original network security group to every switched NIC and deletes the isolation group
+// afterwards, as Azure refuses to delete a group that is still attached.
+func (a *isolateNicAction) restore(ctx context.Context, state *NicIsolationState, nics networkInterfacesApi, nsgs securityGroupsApi) error {
+	var errs []error
+	for nicId, original := range state.OriginalNsgs {
+		var originalId *string
+		if original != "" {
+			originalId = new(original)
+		}
+		if err := switchNsg(ctx, nics, nicId, originalId, nil); err != nil {
+			errs = append(errs, fmt.Errorf("network interface %s: %w", nicId, err))
+			continue
+		}
+		delete(state.OriginalNsgs, nicId)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	operation, err := common.NewOperation(nsgs.BeginDelete(ctx, state.ResourceGroupName, state.IsolationNsgName, nil))
+	if err == nil {
+		err = await(ctx, operation)
+	}
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete network security group '%s': %w", state.IsolationNsgName, err)
+	}
+	return nil
+}
+
+// switchNsg attaches the network security group to the NIC, or detaches the current one if nsgId is nil. The
+// group attached before is reported to recordOriginal before the NIC is updated.
+func switchNsg(ctx context.Context, nics networkInterfacesApi, nicId string, nsgId *string, recordOriginal func(original string)) error {
+	nic, err := getNic(ctx, nics, nicId)
+	if err != nil {
+		return err
+	}
+	if nic.Properties == nil {
+		nic.Properties = &armnetwork.InterfacePropertiesFormat{}
+	}
+	if recordOriginal != nil {
+		original := ""
+		if nic.Properties.NetworkSecurityGroup != nil && nic.Properties.NetworkSecurityGroup.ID != nil {
+			original = *nic.Properties.NetworkSecurityGroup.ID
+		}
+		recordOriginal(original)
+	}
+	nic.Properties.NetworkSecurityGroup = nil
+	if nsgId != nil {
+		nic.Properties.NetworkSecurityGroup = &armnetwork.SecurityGroup{ID: nsgId}
+	}
+	id, err := arm.ParseResourceID(nicId)
+	if err != nil {
+		return err
+	}
+	operation, err := common.NewOperation(nics.BeginCreateOrUpdate(ctx, id.ResourceGroupName, id.Name, nic, nil))
+	if err != nil {
+		return err
+	}
+	return await(ctx, operation)
+}
+
+func getNic(ctx context.Context, nics networkInterfacesApi, nicId string) (armnetwork.Interface, error) {
+	id, err := arm.ParseResourceID(nicId)
+	if err != nil {
+		return armnetwork.Interface{}, err
+	}
+	response, err := nics.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	return response.Interface, err
+}
+
+// await waits for a long-running network operation, if Azure started one.
+func await(ctx context.Context, operation common.Operation) error {
+	if operation == nil {
+		return nil
+	}
+	return operation.Wait(ctx)
+}
+
+// isolationRules allows the CIDRs in the isolated directions and denies everything else with the lowest priority
+// a custom rule can have, which still takes precedence over the default rules of Azure.
+func isolationRules(direction string, allowedCidrs []string) []*armnetwork.SecurityRule {
+	var directions []armnetwork.SecurityRuleDirection
+	if direction == string(BlockInbound) || direction == IsolateBoth {
+		directions = append(directions, armnetwork.SecurityRuleDirectionInbound)
+	}
+	if direction == string(BlockOutbound) || direction == IsolateBoth {
+		directions = append(directions, armnetwork.SecurityRuleDirectionOutbound)
+	}
+
+	rules := make([]*armnetwork.SecurityRule, 0)
+	for _, d := range directions {
+		for i, cidr := range allowedCidrs {
+			source, destination := "*", cidr
+			if d == armnetwork.SecurityRuleDirectionInbound {
+				source, destination = cidr, "*"
+			}
+			rules = append(rules, isolationRule(fmt.Sprintf("SteadybitAllow%s-%d", d, i), allowRulePriority+int32(i), d, armnetwork.SecurityRuleAccessAllow, source, destination))
+		}
+		rules = append(rules, isolationRule(fmt.Sprintf("SteadybitDenyAll%s", d), denyAllPriority, d, armnetwork.SecurityRuleAccessDeny, "*", "*"))
+	}
+	return rules
+}
+
+func isolationRule(name string, priority int32, direction armnetwork.SecurityRuleDirection, access armnetwork.SecurityRuleAccess, source string, destination string) *armnetwork.SecurityRule {
+	return &armnetwork.SecurityRule{
+		Name: new(name),
+		Properties: &armnetwork.SecurityRulePropertiesFormat{
+			Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolAsterisk),
+			SourcePortRange:          new("*"),
+			DestinationPortRange:     new("*"),
+			SourceAddressPrefix:      new(source),
+			DestinationAddressPrefix: new(destination),
+			Access:                   new(access),
+			Direction:                new(direction),
+			Priority:                 new(priority),
+			Description:              new("Isolated by steadybit"),
+		},
+	}
+}
+
+func nonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			result = append(result, strings.TrimSpace(value))
+		}
+	}
+	return result
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package nsg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	nicId          = "/subscriptions/42/resourceGroups/rg-42/providers/Microsoft.Network/networkInterfaces/vm-1-nic"
+	originalNsgId  = "/subscriptions/42/resourceGroups/rg-42/providers/Microsoft.Network/networkSecurityGroups/vm-1-nsg"
+	isolationNsgId = "/subscriptions/42/resourceGroups/rg-42/providers/Microsoft.Network/networkSecurityGroups/steadybit-isolation-vm-1"
+)
+
+type networkInterfacesApiMock struct {
+	mock.Mock
+}
+
+func (m *networkInterfacesApiMock) Get(ctx context.Context, resourceGroupName string, networkInterfaceName string, _ *armnetwork.InterfacesClientGetOptions) (armnetwork.InterfacesClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, networkInterfaceName)
+	return args.Get(0).(armnetwork.InterfacesClientGetResponse), args.Error(1)
+}
+
+func (m *networkInterfacesApiMock) BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, networkInterfaceName string, parameters armnetwork.Interface, _ *armnetwork.InterfacesClientBeginCreateOrUpdateOptions) (*runtime.Poller[armnetwork.InterfacesClientCreateOrUpdateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, networkInterfaceName, parameters)
+	return nil, args.Error(1)
+}
+
+type securityGroupsApiMock struct {
+	mock.Mock
+}
+
+func (m *securityGroupsApiMock) BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, networkSecurityGroupName string, parameters armnetwork.SecurityGroup, _ *armnetwork.SecurityGroupsClientBeginCreateOrUpdateOptions) (*runtime.Poller[armnetwork.SecurityGroupsClientCreateOrUpdateResponse], error) {
+	args := m.Called(ctx, resourceGroupName, networkSecurityGroupName, parameters)
+	return nil, args.Error(1)
+}
+
+func (m *securityGroupsApiMock) BeginDelete(ctx context.Context, resourceGroupName string, networkSecurityGroupName string, _ *armnetwork.SecurityGroupsClientBeginDeleteOptions) (*runtime.Poller[armnetwork.SecurityGroupsClientDeleteResponse], error) {
+	args := m.Called(ctx, resourceGroupName, networkSecurityGroupName)
+	return nil, args.Error(1)
+}
+
+type scaleSetVMsApiMock struct {
+	mock.Mock
+}
+
+func (m *scaleSetVMsApiMock) Get(ctx context.Context, resourceGroupName string, vmScaleSetName string, instanceID string, _ *armcompute.VirtualMachineScaleSetVMsClientGetOptions) (armcompute.VirtualMachineScaleSetVMsClientGetResponse, error) {
+	args := m.Called(ctx, resourceGroupName, vmScaleSetName, instanceID)
+	return args.Get(0).(armcompute.VirtualMachineScaleSetVMsClientGetResponse), args.Error(1)
+}
+
+func nicWithNsg(nsgId string) armnetwork.InterfacesClientGetResponse {
+	nic := armnetwork.Interface{ID: new(nicId), Location: new("westeurope"), Properties: &armnetwork.InterfacePropertiesFormat{}}
+	if nsgId != "" {
+		nic.Properties.NetworkSecurityGroup = &armnetwork.SecurityGroup{ID: new(nsgId)}
+	}
+	return armnetwork.InterfacesClientGetResponse{Interface: nic}
+}
+
+func TestIsolationRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		allowed   []string
+		expected  []string
+	}{
+		{
+			name:      "inbound",
+			direction: "inbound",
+			allowed:   []string{"10.0.1.0/24"},
+			expected:  []string{"SteadybitAllowInbound-0 100 10.0.1.0/24 -> *", "SteadybitDenyAllInbound 4096 * -> *"},
+		},
+		{
+			name:      "outbound",
+			direction: "outbound",
+			allowed:   []string{"10.0.1.0/24", "10.0.2.4"},
+			expected: []string{
+				"SteadybitAllowOutbound-0 100 * -> 10.0.1.0/24",
+				"SteadybitAllowOutbound-1 101 * -> 10.0.2.4",
+				"SteadybitDenyAllOutbound 4096 * -> *",
+			},
+		},
+		{
+			name:      "both without allowlist",
+			direction: "both",
+			expected:  []string{"SteadybitDenyAllInbound 4096 * -> *", "SteadybitDenyAllOutbound 4096 * -> *"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := isolationRules(tt.direction, tt.allowed)
+
+			actual := make([]string, 0, len(rules))
+			for _, rule := range rules {
+				p := rule.Properties
+				actual = append(actual, fmt.Sprintf("%s %d %s -> %s", *rule.Name, *p.Priority, *p.SourceAddressPrefix, *p.DestinationAddressPrefix))
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestIsolateNicAction_PrepareVirtualMachine(t *testing.T) {
+	// Given
+	action := NewVirtualMachineIsolationAction()
+	state := action.NewEmptyState()
+	request := action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"direction": "both", "allowedCidrs": []any{"10.0.1.0/24", ""}},
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure-vm.vm.name":    {"vm-1"},
+			"azure-vm.network.id": {nicId},
+		}},
+		ExecutionContext: &action_kit_api.ExecutionContext{ExecutionId: new(7)},
+	}
+
+	// When
+	_, err := action.Prepare(context.Background(), &state, request)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "42", state.SubscriptionId)
+	assert.Equal(t, "rg-42", state.ResourceGroupName)
+	assert.Equal(t, []string{nicId}, state.NicIds)
+	assert.Equal(t, []string{"10.0.1.0/24"}, state.AllowedCidrs)
+	assert.Equal(t, "steadybit-isolation-vm-1-7", state.IsolationNsgName)
+}
+
+func TestIsolationNsgName_FitsTheNameLimitOfAzure(t *testing.T) {
+	long := strings.Repeat("a", 64)
+
+	name := isolationNsgName(long, new(123456))
+
+	assert.Len(t, name, maxNsgNameLength)
+	assert.True(t, strings.HasPrefix(name, "steadybit-isolation-aaaa"))
+	assert.True(t, strings.HasSuffix(name, "-123456"))
+	assert.NotEqual(t, name, isolationNsgName(long[:63]+"b", new(123456)))
+	assert.Equal(t, "steadybit-isolation-vm-1", isolationNsgName("vm-1", nil))
+}
+
+func TestIsolateNicAction_PrepareRejectsInvalidCidr(t *testing.T) {
+	action := NewVirtualMachineIsolationAction()
+	state := action.NewEmptyState()
+	request := action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"direction": "inbound", "allowedCidrs": []any{"10.0.1.0/33"}},
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure-vm.vm.name":    {"vm-1"},
+			"azure-vm.network.id": {nicId},
+		}},
+	}
+
+	_, err := action.Prepare(context.Background(), &state, request)
+
+	assert.ErrorContains(t, err, "'10.0.1.0/33' is neither a CIDR nor an IP address")
+}
+
+func TestScaleSetInstanceIsolationTarget_RejectsUniformScaleSets(t *testing.T) {
+	// Given
+	api := new(scaleSetVMsApiMock)
+	api.On("Get", mock.Anything, "rg-42", "my-scaleSet", "0").Return(armcompute.VirtualMachineScaleSetVMsClientGetResponse{
+		VirtualMachineScaleSetVM: armcompute.VirtualMachineScaleSetVM{Properties: &armcompute.VirtualMachineScaleSetVMProperties{
+			NetworkProfile: &armcompute.NetworkProfile{NetworkInterfaces: []*armcompute.NetworkInterfaceReference{{
+				ID: new("/subscriptions/42/resourceGroups/rg-42/providers/Microsoft.Compute/virtualMachineScaleSets/my-scaleSet/virtualMachines/0/networkInterfaces/nic"),
+			}}},
+		}},
+	}, nil)
+	targetProvider := scaleSetInstanceIsolationTarget(func(string) (scaleSetVMsApi, error) { return api, nil })
+
+	// When
+	_, err := targetProvider(context.Background(), action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"azure-scale-set.name":        {"my-scaleSet"},
+			"azure-scale-set-instance.id": {"0"},
+			"azure.subscription.id":       {"42"},
+			"azure.resource-group.name":   {"rg-42"},
+		}},
+	})
+
+	// Then
+	assert.ErrorContains(t, err, "uniform orchestration mode")
+}
+
+func TestIsolateNicAction_IsolatesAndRestoresNic(t *testing.T) {
+	// Given
+	nics := new(networkInterfacesApiMock)
+	nsgs := new(securityGroupsApiMock)
+	action := &isolateNicAction{
+		networkInterfacesApi:  func(string) (networkInterfacesApi, error) { return nics, nil },
+		networkSecurityGroups: func(string) (securityGroupsApi, error) { return nsgs, nil },
+	}
+	state := &NicIsolationState{
+		SubscriptionId:    "42",
+		ResourceGroupName: "rg-42",
+		Subject:           "virtual machine 'vm-1'",
+		NicIds:            []string{nicId},
+		Direction:         "both",
+		IsolationNsgName:  "steadybit-isolation-vm-1",
+	}
+	nics.On("Get", mock.Anything, "rg-42", "vm-1-nic").Return(nicWithNsg(originalNsgId), nil).Once()
+	nics.On("Get", mock.Anything, "rg-42", "vm-1-nic").Return(nicWithNsg(originalNsgId), nil).Once()
+	nics.On("Get", mock.Anything, "rg-42", "vm-1-nic").Return(nicWithNsg(isolationNsgId), nil).Once()
+	nsgs.On("BeginCreateOrUpdate", mock.Anything, "rg-42", "steadybit-isolation-vm-1", mock.Anything).Return(nil, nil)
+	nics.On("BeginCreateOrUpdate", mock.Anything, "rg-42", "vm-1-nic", mock.Anything).Return(nil, nil)
+	nsgs.On("BeginDelete", mock.Anything, "rg-42", "steadybit-isolation-vm-1").Return(nil, nil)
+
+	// When
+	_, err := action.Start(context.Background(), state)
+	require.NoError(t, err)
+	_, err = action.Stop(context.Background(), state)
+	require.NoError(t, err)
+
+	// Then
+	require.Len(t, nics.Calls, 5)
+	assert.Equal(t, isolationNsgId, *nics.Calls[2].Arguments.Get(3).(armnetwork.Interface).Properties.NetworkSecurityGroup.ID)
+	assert.Equal(t, originalNsgId, *nics.Calls[4].Arguments.Get(3).(armnetwork.Interface).Properties.NetworkSecurityGroup.ID)
+	assert.Empty(t, state.OriginalNsgs)
+	nsgs.AssertExpectations(t)
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package nsg
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-azure/common"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// serviceTagPattern matches Azure service tags like 'AzureCloud' or 'Storage.WestEurope'.
+var serviceTagPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(\.[A-Za-z0-9]+)?$`)
+
+// NewRegionIsolationAction makes a region unreachable by adding deny-all rules to all network security groups
+// selected by their location, keeping management CIDRs and service tags reachable. It shares the rule handling of
+// the block action.
+func NewRegionIsolationAction() action_kit_sdk.Action[BlockActionState] {
+	return &blockAction{
+		description:    getRegionIsolationDescription(),
+		configProvider: injectRegionIsolation,
+	}
+}
+
+func getRegionIsolationDescription() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.isolate-region", TargetIDNetworkSG),
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Label:       "Isolate Region",
+		Description: "Deny all inbound and outbound traffic of the network security groups in a region, except for management CIDRs and service tags.",
+		Icon:        new(string(targetIcon)),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: TargetIDNetworkSG,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "location",
+					Description: new("Find network security groups by location"),
+					Query:       "azure.location=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Azure"),
+		Category:    new("Network Security Groups"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Label:        "Duration",
+				Name:         "duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Description:  new("The duration of the attack."),
+				Required:     new(true),
+				DefaultValue: new("60s"),
+				Order:        new(0),
+			},
+			{
+				Name:         "direction",
+				Label:        "Direction",
+				Description:  new("Direction in which to deny traffic"),
+				Type:         action_kit_api.ActionParameterTypeString,
+				Required:     new(true),
+				Order:        new(1),
+				DefaultValue: new(IsolateBoth),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{Label: "Inbound and outbound", Value: IsolateBoth},
+					action_kit_api.ExplicitParameterOption{Label: "Inbound", Value: string(BlockInbound)},
+					action_kit_api.ExplicitParameterOption{Label: "Outbound", Value: string(BlockOutbound)},
+				}),
+			},
+			{
+				Name:         "allowedPrefixes",
+				Label:        "Allowed CIDRs and service tags",
+				Description:  new("Management CIDRs and service tags, e.g. 'AzureLoadBalancer', that stay reachable."),
+				Type:         action_kit_api.ActionParameterTypeStringArray,
+				DefaultValue: new(""),
+				Required:     new(false),
+				Order:        new(2),
+			},
+			common.DryRunParameter(),
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func injectRegionIsolation(request action_kit_api.PrepareActionRequestBody) (*BlockHostsConfig, error) {
+	var directions []armnetwork.SecurityRuleDirection
+	switch direction := extutil.ToString(request.Config["direction"]); direction {
+	case IsolateBoth:
+		directions = []armnetwork.SecurityRuleDirection{armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleDirectionOutbound}
+	case string(BlockInbound):
+		directions = []armnetwork.SecurityRuleDirection{armnetwork.SecurityRuleDirectionInbound}
+	case string(BlockOutbound):
+		directions = []armnetwork.SecurityRuleDirection{armnetwork.SecurityRuleDirectionOutbound}
+	default:
+		return nil, fmt.Errorf("invalid direction %s is specified, please select one of the following: %s, %s, %s", direction, IsolateBoth, string(BlockInbound), string(BlockOutbound))
+	}
+
+	allowed := nonEmpty(extutil.ToStringArray(request.Config["allowedPrefixes"]))
+	for _, prefix := range allowed {
+		if _, _, err := net.ParseCIDR(prefix); err == nil || net.ParseIP(prefix) != nil || serviceTagPattern.MatchString(prefix) {
+			continue
+		}
+		return nil, fmt.Errorf("the following entry is neither a CIDR, an IP address nor a service tag: %s", prefix)
+	}
+
+	return &BlockHostsConfig{
+		BlockedIPs:      new([]string{"*"}),
+		BlockDirection:  directions[0],
+		Directions:      directions,
+		AllowedPrefixes: allowed,
+	}, nil
+}
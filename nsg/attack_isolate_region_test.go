@@ -0,0 +1,80 @@
+/*
+ * Copyright 2026 steadybit GmbH. All rights reserved.
+ */
+
+package nsg
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectRegionIsolation(t *testing.T) {
+	tests := []struct {
+		name               string
+		config             map[string]any
+		expectedDirections []armnetwork.SecurityRuleDirection
+		expectedAllowed    []string
+		expectedError      string
+	}{
+		{
+			name:               "both directions with management prefixes",
+			config:             map[string]any{"direction": IsolateBoth, "allowedPrefixes": []any{"10.1.0.0/16", " AzureLoadBalancer ", "", "Storage.WestEurope"}},
+			expectedDirections: []armnetwork.SecurityRuleDirection{armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleDirectionOutbound},
+			expectedAllowed:    []string{"10.1.0.0/16", "AzureLoadBalancer", "Storage.WestEurope"},
+		},
+		{
+			name:               "outbound only",
+			config:             map[string]any{"direction": string(BlockOutbound)},
+			expectedDirections: []armnetwork.SecurityRuleDirection{armnetwork.SecurityRuleDirectionOutbound},
+			expectedAllowed:    []string{},
+		},
+		{
+			name:          "rejects invalid prefixes",
+			config:        map[string]any{"direction": IsolateBoth, "allowedPrefixes": []any{"10.1.0.0/16; drop"}},
+			expectedError: "neither a CIDR, an IP address nor a service tag",
+		},
+		{
+			name:          "rejects invalid directions",
+			config:        map[string]any{"direction": "sideways"},
+			expectedError: "invalid direction sideways",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := injectRegionIsolation(action_kit_api.PrepareActionRequestBody{Config: tt.config})
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"*"}, *config.BlockedIPs)
+			assert.Equal(t, tt.expectedDirections, config.Directions)
+			assert.Equal(t, tt.expectedAllowed, config.AllowedPrefixes)
+		})
+	}
+}
+
+func TestPlanBlockRules_AllowsPrefixesBeforeDenyingAll(t *testing.T) {
+	existing := []*armnetwork.SecurityRule{
+		{Properties: &armnetwork.SecurityRulePropertiesFormat{Priority: new(int32(101))}},
+	}
+
+	rules := planBlockRules(existing, &BlockHostsConfig{
+		BlockedIPs:      new([]string{"*"}),
+		Directions:      []armnetwork.SecurityRuleDirection{armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleDirectionOutbound},
+		AllowedPrefixes: []string{"AzureLoadBalancer"},
+	})
+
+	assert.Equal(t, []plannedBlockRule{
+		{Name: "SteadybitAllowRule-0", Priority: 100, SourcePrefix: "AzureLoadBalancer", DestinationPrefix: "*", Direction: armnetwork.SecurityRuleDirectionInbound, Access: armnetwork.SecurityRuleAccessAllow},
+		{Name: "SteadybitBlockRule-0", Priority: 102, SourcePrefix: "*", DestinationPrefix: "*", Direction: armnetwork.SecurityRuleDirectionInbound, Access: armnetwork.SecurityRuleAccessDeny},
+		{Name: "SteadybitAllowRule-1", Priority: 103, SourcePrefix: "*", DestinationPrefix: "AzureLoadBalancer", Direction: armnetwork.SecurityRuleDirectionOutbound, Access: armnetwork.SecurityRuleAccessAllow},
+		{Name: "SteadybitBlockRule-1", Priority: 104, SourcePrefix: "*", DestinationPrefix: "*", Direction: armnetwork.SecurityRuleDirectionOutbound, Access: armnetwork.SecurityRuleAccessDeny},
+	}, rules)
+}
@@ -74,6 +74,10 @@ func (a *nsgDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
 }
 
 func (a *nsgDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return common.ObserveDiscovery(ctx, TargetIDNetworkSG, a.discoverTargets)
+}
+
+func (a *nsgDiscovery) discoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
 	client, err := common.GetClientByCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
@@ -5,10 +5,12 @@ import (
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-azure/appcontainers"
 	"github.com/steadybit/extension-azure/azurefunctions"
+	"github.com/steadybit/extension-azure/common"
 	"github.com/steadybit/extension-azure/config"
 	"github.com/steadybit/extension-azure/extaks"
 	"github.com/steadybit/extension-azure/extapim"
 	"github.com/steadybit/extension-azure/extappgateway"
+	"github.com/steadybit/extension-azure/extavailabilityset"
 	"github.com/steadybit/extension-azure/extcosmosdb"
 	"github.com/steadybit/extension-azure/extdisk"
 	"github.com/steadybit/extension-azure/exteventgrid"
@@ -19,6 +21,7 @@ import (
 	"github.com/steadybit/extension-azure/extstoragequeue"
 	"github.com/steadybit/extension-azure/extvm"
 	"github.com/steadybit/extension-azure/extvmss"
+	"github.com/steadybit/extension-azure/extzone"
 	"github.com/steadybit/extension-azure/nsg"
 )
 
@@ -27,55 +30,83 @@ func RegisterHandlers() error {
 
 	if configSpec.DiscoveryEnableVirtualMachines {
 		discovery_kit_sdk.Register(extvm.NewVirtualMachineDiscovery())
-		action_kit_sdk.RegisterAction(extvm.NewVirtualMachineStateAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineStateAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineStateWaitAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineStopAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineSpotEvictionAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineResizeAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(nsg.NewVirtualMachineIsolationAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineStressCpuAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineStressMemoryAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineKillProcessAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineStopServiceAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineFillDiskAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvm.NewVirtualMachineNetworkDelayAction())))
 	}
 
 	if configSpec.DiscoveryEnableScaleInstances {
 		discovery_kit_sdk.Register(extscalesetinstance.NewScaleSetInstanceDiscovery())
-		action_kit_sdk.RegisterAction(extscalesetinstance.NewScaleSetInstanceStateAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extscalesetinstance.NewScaleSetInstanceStateAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extscalesetinstance.NewScaleSetInstanceStateWaitAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extscalesetinstance.NewScaleSetInstanceStopAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extscalesetinstance.NewScaleSetInstanceSpotEvictionAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(nsg.NewScaleSetInstanceIsolationAction())))
 	}
 
 	if configSpec.DiscoveryEnableNetworkSecurityGroups {
 		discovery_kit_sdk.Register(nsg.NewNsgDiscovery())
-		action_kit_sdk.RegisterAction(nsg.NewBlockAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(nsg.NewBlockAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(nsg.NewRegionIsolationAction())))
+	}
+
+	if configSpec.DiscoveryEnableVirtualMachines || configSpec.DiscoveryEnableScaleInstances {
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extzone.NewZoneOutageAction())))
 	}
 
 	if configSpec.DiscoveryEnableAzureFunctions {
 		discovery_kit_sdk.Register(azurefunctions.NewAzureFunctionDiscovery())
-		action_kit_sdk.RegisterAction(azurefunctions.NewAzureFunctionExceptionAction())
-		action_kit_sdk.RegisterAction(azurefunctions.NewAzureFunctionStatusCodeAction())
-		action_kit_sdk.RegisterAction(azurefunctions.NewAzureFunctionLatencyAction())
-		action_kit_sdk.RegisterAction(azurefunctions.NewAzureFunctionFillDiskAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(azurefunctions.NewAzureFunctionExceptionAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(azurefunctions.NewAzureFunctionStatusCodeAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(azurefunctions.NewAzureFunctionLatencyAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(azurefunctions.NewAzureFunctionFillDiskAction())))
 	}
 
 	if configSpec.DiscoveryEnableContainerApps {
 		discovery_kit_sdk.Register(appcontainers.NewAppContainerDiscovery())
-		action_kit_sdk.RegisterAction(appcontainers.NewAppContainerExceptionAction())
-		action_kit_sdk.RegisterAction(appcontainers.NewAppContainerStatusCodeAction())
-		action_kit_sdk.RegisterAction(appcontainers.NewAppContainerLatencyAction())
-		action_kit_sdk.RegisterAction(appcontainers.NewAppContainerFillDiskAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(appcontainers.NewAppContainerExceptionAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(appcontainers.NewAppContainerStatusCodeAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(appcontainers.NewAppContainerLatencyAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(appcontainers.NewAppContainerFillDiskAction())))
 	}
 
 	if configSpec.DiscoveryEnableAksCluster {
 		discovery_kit_sdk.Register(extaks.NewClusterDiscovery())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extaks.NewClusterStopAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extaks.NewClusterRestrictApiServerAction())))
 	}
 	if configSpec.DiscoveryEnableAksNodePool {
 		discovery_kit_sdk.Register(extaks.NewNodePoolDiscovery())
-		action_kit_sdk.RegisterAction(extaks.NewNodePoolTerminateInstancesAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extaks.NewNodePoolTerminateInstancesAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extaks.NewNodePoolSpotEvictionAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extaks.NewNodePoolScaleAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extaks.NewNodePoolRebootNodesAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extaks.NewNodePoolNodeImageUpgradeAction())))
 	}
 	if configSpec.DiscoveryEnableScaleSet {
 		discovery_kit_sdk.Register(extvmss.NewScaleSetDiscovery())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvmss.NewScaleSetCapacityAction())))
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extvmss.NewScaleSetInstancesAction())))
 	}
 	if configSpec.DiscoveryEnableManagedDisk {
 		discovery_kit_sdk.Register(extdisk.NewDiskDiscovery())
 	}
 	if configSpec.DiscoveryEnableNatGateway {
 		discovery_kit_sdk.Register(extnatgateway.NewNatGatewayDiscovery())
-		action_kit_sdk.RegisterAction(extnatgateway.NewNatGatewayDisassociateAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extnatgateway.NewNatGatewayDisassociateAction())))
 	}
 	if configSpec.DiscoveryEnableCosmosDb {
 		discovery_kit_sdk.Register(extcosmosdb.NewAccountDiscovery())
-		action_kit_sdk.RegisterAction(extcosmosdb.NewCosmosDbFailoverAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extcosmosdb.NewCosmosDbFailoverAction())))
 	}
 	if configSpec.DiscoveryEnableEventGrid {
 		discovery_kit_sdk.Register(exteventgrid.NewTopicDiscovery())
@@ -86,11 +117,11 @@ func RegisterHandlers() error {
 	}
 	if configSpec.DiscoveryEnableServiceBusQueue {
 		discovery_kit_sdk.Register(extservicebus.NewQueueDiscovery())
-		action_kit_sdk.RegisterAction(extservicebus.NewQueueDisableAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extservicebus.NewQueueDisableAction())))
 	}
 	if configSpec.DiscoveryEnableServiceBusTopic {
 		discovery_kit_sdk.Register(extservicebus.NewTopicDiscovery())
-		action_kit_sdk.RegisterAction(extservicebus.NewTopicDisableAction())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extservicebus.NewTopicDisableAction())))
 	}
 	if configSpec.DiscoveryEnableStorageQueue {
 		discovery_kit_sdk.Register(extstoragequeue.NewStorageAccountDiscovery())
@@ -104,6 +135,10 @@ func RegisterHandlers() error {
 	if configSpec.DiscoveryEnableApiManagement {
 		discovery_kit_sdk.Register(extapim.NewApiManagementDiscovery())
 	}
+	if configSpec.DiscoveryEnableAvailabilitySet {
+		discovery_kit_sdk.Register(extavailabilityset.NewAvailabilitySetDiscovery())
+		action_kit_sdk.RegisterAction(common.InstrumentAction(common.WithPreflight(extavailabilityset.NewFaultDomainOutageAction())))
+	}
 
 	return nil
 }
@@ -47,8 +47,8 @@ func TestRegisterHandlers_WithEnvironmentVariables(t *testing.T) {
 				"STEADYBIT_EXTENSION_DISCOVERY_ENABLE_NETWORK_SECURITY_GROUPS": "false",
 			},
 			expectedDiscoveryCount: 1,
-			expectedActionCount:    1,
-			description:            "When only VMs are enabled, should register VM discovery and state action",
+			expectedActionCount:    13,
+			description:            "When only VMs are enabled, should register VM discovery, state, state-and-wait, stop, spot eviction, resize, isolation, run command and zone outage actions",
 		},
 		{
 			name: "only scale instances enabled",
@@ -60,8 +60,8 @@ func TestRegisterHandlers_WithEnvironmentVariables(t *testing.T) {
 				"STEADYBIT_EXTENSION_DISCOVERY_ENABLE_NETWORK_SECURITY_GROUPS": "false",
 			},
 			expectedDiscoveryCount: 1,
-			expectedActionCount:    1,
-			description:            "When only scale instances are enabled, should register scale set discovery and state action",
+			expectedActionCount:    6,
+			description:            "When only scale instances are enabled, should register scale set discovery, state, state-and-wait, stop, spot eviction, isolation and zone outage actions",
 		},
 		{
 			name: "only azure functions enabled",
@@ -86,8 +86,8 @@ func TestRegisterHandlers_WithEnvironmentVariables(t *testing.T) {
 				"STEADYBIT_EXTENSION_DISCOVERY_ENABLE_NETWORK_SECURITY_GROUPS": "true",
 			},
 			expectedDiscoveryCount: 1,
-			expectedActionCount:    1,
-			description:            "When only NSGs are enabled, should register NSG discovery and block action",
+			expectedActionCount:    2,
+			description:            "When only NSGs are enabled, should register NSG discovery, block and region isolation actions",
 		},
 		{
 			name: "all features enabled",
@@ -99,14 +99,14 @@ func TestRegisterHandlers_WithEnvironmentVariables(t *testing.T) {
 				"STEADYBIT_EXTENSION_DISCOVERY_ENABLE_NETWORK_SECURITY_GROUPS": "true",
 			},
 			expectedDiscoveryCount: 4,
-			expectedActionCount:    7,
+			expectedActionCount:    24,
 			description:            "When all features are enabled, should register all discoveries and actions",
 		},
 		{
 			name:                   "default values (VMs and scale instances enabled by default)",
 			envVars:                map[string]string{},
 			expectedDiscoveryCount: 2,
-			expectedActionCount:    2,
+			expectedActionCount:    18,
 			description:            "With default config, VMs and scale instances should be enabled",
 		},
 		{
@@ -119,7 +119,7 @@ func TestRegisterHandlers_WithEnvironmentVariables(t *testing.T) {
 				"STEADYBIT_EXTENSION_DISCOVERY_ENABLE_NETWORK_SECURITY_GROUPS": "false",
 			},
 			expectedDiscoveryCount: 2,
-			expectedActionCount:    5,
+			expectedActionCount:    17,
 			description:            "Mixed configuration should register only enabled features",
 		},
 	}